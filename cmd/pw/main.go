@@ -18,6 +18,11 @@ var (
 )
 
 func main() {
+	// Child processes started by the packwiz runner only run packwiz
+	if os.Getenv(packwiz.ChildEnv) != "" {
+		os.Exit(packwiz.RunChild(os.Args[1:]))
+	}
+
 	// Set build information for version command
 	commands.BuildInfo.Version = version
 	commands.BuildInfo.Commit = commit
@@ -42,6 +47,7 @@ func main() {
 		commands.CmdBuild, // build, export (all formats)
 
 		// Pack management
+		commands.CmdRefresh, // refresh (native, incremental)
 		commands.CmdImport,  // import, load
 		commands.CmdDetect,  // detect, detect-url, url
 		commands.CmdRelease, // release, changelog
@@ -52,8 +58,11 @@ func main() {
 		commands.CmdArbitrary, // arbitrary, exec, run
 
 		// Development
-		commands.CmdServer, // server, test-server, start
-		commands.CmdJava,   // java (Java installation management)
+		commands.CmdServer,       // server, test-server, start
+		commands.CmdAnalyzeCrash, // analyze-crash, crash
+		commands.CmdBisect,       // bisect (find the mods that break the server)
+		commands.CmdJava,         // java (Java installation management)
+		commands.CmdCache,        // cache (shared download cache)
 
 		// Just add more function references here - no () needed!
 	)
@@ -104,21 +113,10 @@ func main() {
 		}
 	}
 
-	// Save original args and replace with the command we want to execute
+	// Save original args and replace with the command we want to execute,
+	// pointing packwiz at the discovered pack unless --pack-file was given
 	originalArgs := os.Args
-	os.Args = append([]string{os.Args[0]}, args...)
-
-	// Only add --pack-file if it's not already present in args
-	hasPackFile := false
-	for _, arg := range os.Args {
-		if arg == "--pack-file" {
-			hasPackFile = true
-			break
-		}
-	}
-	if packLocation != "" && !hasPackFile {
-		os.Args = append(os.Args, "--pack-file", packLocation+"/pack.toml")
-	}
+	os.Args = append([]string{os.Args[0]}, utils.PackFileArgs(args, packLocation)...)
 
 	// Call packwiz directly
 	packwiz.PackwizExecute()
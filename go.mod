@@ -10,6 +10,7 @@ require (
 	github.com/Merith-TK/utils v0.0.0-20250710011649-922eab8d0105
 	github.com/packwiz/packwiz v0.0.0-20250119231123-241f24b550f6
 	github.com/pelletier/go-toml v1.9.5
+	github.com/spf13/cobra v1.6.1
 )
 
 require (
@@ -56,7 +57,6 @@ require (
 	github.com/skratchdot/open-golang v0.0.0-20200116055534-eef842397966 // indirect
 	github.com/spf13/afero v1.9.3 // indirect
 	github.com/spf13/cast v1.5.0 // indirect
-	github.com/spf13/cobra v1.6.1 // indirect
 	github.com/spf13/jwalterweatherman v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/spf13/viper v1.15.0 // indirect
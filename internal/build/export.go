@@ -0,0 +1,247 @@
+package build
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/config"
+	"github.com/Merith-TK/packwiz-wrapper/internal/packwiz"
+	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
+)
+
+// Export formats understood by Export, in the order 'all' builds them
+const (
+	FormatCurseForge = "curseforge"
+	FormatModrinth   = "modrinth"
+	FormatMultiMC    = "multimc"
+	FormatPrism      = "prism"
+	FormatTechnic    = "technic"
+	FormatServer     = "server"
+)
+
+// Formats lists every export format
+var Formats = []string{FormatCurseForge, FormatModrinth, FormatMultiMC, FormatPrism, FormatTechnic, FormatServer}
+
+// formatExtensions are the artifact extensions, zip unless listed
+var formatExtensions = map[string]string{
+	FormatModrinth: ".mrpack",
+}
+
+// DefaultNameTemplate is used when neither the options nor packwrap.toml set one
+const DefaultNameTemplate = "{{.Dir}}-{{.Format}}"
+
+// NameData is the data available to artifact name templates
+type NameData struct {
+	Name             string // pack.toml name, or the pack name option when set
+	Version          string // pack.toml version
+	MinecraftVersion string
+	Format           string // curseforge, modrinth, multimc, prism, technic or server
+	Dir              string // pack directory name, or the pack name option when set
+	Timestamp        string // SourceDateEpoch as 01-02_15-04-05, stable for a given commit
+}
+
+// Options controls how a pack is exported
+type Options struct {
+	// PackName is used in artifact names and as the MultiMC instance name.
+	// Defaults to the name of the pack directory.
+	PackName string
+
+	// Output is the artifact path. Relative paths are resolved against the pack
+	// directory, and the default is <OutputDir>/<rendered NameTemplate>.<ext>.
+	Output string
+
+	// OutputDir receives artifacts that use their default name. Defaults to the
+	// output-dir in packwrap.toml, then .build.
+	OutputDir string
+
+	// NameTemplate overrides the name-template in packwrap.toml
+	NameTemplate string
+
+	// UseLocal makes MultiMC and Prism instances install from the local
+	// pack.toml instead of the pack's remote URL
+	UseLocal bool
+
+	// BundleMods ships the mod jars in Prism instances for offline play.
+	// The bundle-mods setting in packwrap.toml also enables it.
+	BundleMods bool
+}
+
+// Export builds the pack in packDir in the given format and returns the
+// absolute path of the artifact. The artifact is also recorded in the
+// manifest.json and SHA256SUMS of the output directory.
+func Export(packDir, format string, options Options) (string, error) {
+	var export func(string, Options) (string, error)
+	switch format {
+	case FormatCurseForge:
+		export = ExportCurseForge
+	case FormatModrinth:
+		export = ExportModrinth
+	case FormatMultiMC:
+		export = ExportMultiMC
+	case FormatPrism:
+		export = ExportPrism
+	case FormatTechnic:
+		export = ExportTechnic
+	case FormatServer:
+		export = ExportServer
+	default:
+		return "", fmt.Errorf("unknown export format: %s", format)
+	}
+
+	artifact, err := export(packDir, options)
+	if err != nil {
+		return "", err
+	}
+
+	packLocation := utils.FindPackToml(packDir)
+	if err := options.recordArtifact(packDir, packLocation, format, artifact); err != nil {
+		return artifact, fmt.Errorf("failed to record checksums: %w", err)
+	}
+	return artifact, nil
+}
+
+// packName returns the configured pack name or the pack directory's name
+func (o Options) packName(packDir string) string {
+	if o.PackName != "" {
+		return o.PackName
+	}
+	if abs, err := filepath.Abs(packDir); err == nil {
+		packDir = abs
+	}
+	return filepath.Base(packDir)
+}
+
+// artifactPath resolves the absolute artifact path for format, creating its
+// directory. Unless Output is set, the name comes from the name template.
+func (o Options) artifactPath(packDir, packLocation, format string) (string, error) {
+	output := o.Output
+	if output == "" {
+		cfg, err := config.Load(packLocation)
+		if err != nil {
+			return "", err
+		}
+
+		name, err := o.artifactName(packDir, packLocation, format, cfg)
+		if err != nil {
+			return "", err
+		}
+		output = filepath.Join(o.outputDirName(cfg), name)
+	}
+	if !filepath.IsAbs(output) {
+		output = filepath.Join(packDir, output)
+	}
+
+	output, err := filepath.Abs(output)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve output path: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	return output, nil
+}
+
+// outputDir returns the absolute directory that receives default named
+// artifacts and the build manifest
+func (o Options) outputDir(packDir, packLocation string) (string, error) {
+	cfg, err := config.Load(packLocation)
+	if err != nil {
+		return "", err
+	}
+
+	dir := o.outputDirName(cfg)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(packDir, dir)
+	}
+	return filepath.Abs(dir)
+}
+
+// outputDirName returns the configured output directory, which may be relative
+// to the pack directory
+func (o Options) outputDirName(cfg *config.Config) string {
+	if o.OutputDir != "" {
+		return o.OutputDir
+	}
+	if cfg.Build.OutputDir != "" {
+		return cfg.Build.OutputDir
+	}
+	return ".build"
+}
+
+// artifactName renders the name template for format and adds the extension
+func (o Options) artifactName(packDir, packLocation, format string, cfg *config.Config) (string, error) {
+	nameTemplate := o.NameTemplate
+	if nameTemplate == "" {
+		nameTemplate = cfg.Build.NameTemplate
+	}
+	if nameTemplate == "" {
+		nameTemplate = DefaultNameTemplate
+	}
+
+	meta, err := packwiz.ReadPackToml(packLocation)
+	if err != nil {
+		return "", err
+	}
+
+	data := NameData{
+		Name:             o.PackName,
+		Version:          meta.Version,
+		MinecraftVersion: meta.Versions.Minecraft,
+		Format:           format,
+		Dir:              o.packName(packDir),
+		Timestamp:        SourceDateEpoch(packLocation).Format("01-02_15-04-05"),
+	}
+	if data.Name == "" {
+		data.Name = meta.Name
+	}
+	if data.Name == "" {
+		data.Name = data.Dir
+	}
+	if data.MinecraftVersion == "" {
+		data.MinecraftVersion = meta.McVersion
+	}
+
+	name, err := RenderName(nameTemplate, data)
+	if err != nil {
+		return "", err
+	}
+
+	ext := formatExtensions[format]
+	if ext == "" {
+		ext = ".zip"
+	}
+	if !strings.HasSuffix(name, ext) {
+		name += ext
+	}
+	return name, nil
+}
+
+// RenderName renders an artifact name template. Path separators and characters
+// that are invalid in file names are replaced so the result is a single file name.
+func RenderName(nameTemplate string, data NameData) (string, error) {
+	tmpl, err := template.New("name").Option("missingkey=error").Parse(nameTemplate)
+	if err != nil {
+		return "", fmt.Errorf("invalid name template: %w", err)
+	}
+
+	var name bytes.Buffer
+	if err := tmpl.Execute(&name, data); err != nil {
+		return "", fmt.Errorf("failed to render name template: %w", err)
+	}
+
+	result := strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name.String()))
+	if result == "" {
+		return "", fmt.Errorf("name template %q rendered an empty name", nameTemplate)
+	}
+	return result, nil
+}
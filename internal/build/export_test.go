@@ -0,0 +1,148 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestPack creates a pack directory with the given pack.toml and optional packwrap.toml
+func writeTestPack(t *testing.T, packToml, packwrapToml string) string {
+	t.Helper()
+	packDir := filepath.Join(t.TempDir(), "My Pack")
+	if err := os.MkdirAll(packDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(packDir, "pack.toml"), []byte(packToml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if packwrapToml != "" {
+		if err := os.WriteFile(filepath.Join(packDir, "packwrap.toml"), []byte(packwrapToml), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return packDir
+}
+
+func TestOptionsArtifactPath(t *testing.T) {
+	packDir := writeTestPack(t, `name = "Cool Pack"
+version = "1.4.0"
+
+[versions]
+minecraft = "1.20.1"
+`, "")
+	absOutput := filepath.Join(t.TempDir(), "release", "pack.zip")
+
+	tests := []struct {
+		name     string
+		options  Options
+		format   string
+		expected string
+	}{
+		{"default", Options{}, FormatMultiMC, filepath.Join(packDir, ".build", "My Pack-multimc.zip")},
+		{"output dir", Options{OutputDir: "dist"}, FormatServer, filepath.Join(packDir, "dist", "My Pack-server.zip")},
+		{"relative output", Options{Output: "out/custom.zip"}, FormatMultiMC, filepath.Join(packDir, "out", "custom.zip")},
+		{"absolute output", Options{Output: absOutput, OutputDir: "dist"}, FormatMultiMC, absOutput},
+		{"template", Options{NameTemplate: "{{.Name}}-{{.Version}}-{{.Format}}"}, FormatModrinth, filepath.Join(packDir, ".build", "Cool Pack-1.4.0-modrinth.mrpack")},
+		{"pack name", Options{PackName: "cool", NameTemplate: "{{.Name}}-mc{{.MinecraftVersion}}"}, FormatTechnic, filepath.Join(packDir, ".build", "cool-mc1.20.1.zip")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := tt.options.artifactPath(packDir, packDir, tt.format)
+			if err != nil {
+				t.Fatalf("artifactPath failed: %v", err)
+			}
+			if path != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, path)
+			}
+			if _, err := os.Stat(filepath.Dir(path)); err != nil {
+				t.Errorf("Expected output directory to exist: %v", err)
+			}
+		})
+	}
+}
+
+func TestArtifactPathUsesPackConfig(t *testing.T) {
+	packDir := writeTestPack(t, "name = \"Cool Pack\"\nversion = \"2.0\"\n", `[build]
+name-template = "{{.Name}}-{{.Version}}-{{.Format}}"
+output-dir = "artifacts"
+`)
+
+	path, err := Options{}.artifactPath(packDir, packDir, FormatCurseForge)
+	if err != nil {
+		t.Fatalf("artifactPath failed: %v", err)
+	}
+	if expected := filepath.Join(packDir, "artifacts", "Cool Pack-2.0-curseforge.zip"); path != expected {
+		t.Errorf("Expected %s, got %s", expected, path)
+	}
+
+	// Options win over the config
+	path, err = Options{NameTemplate: "{{.Format}}", OutputDir: "dist"}.artifactPath(packDir, packDir, FormatServer)
+	if err != nil {
+		t.Fatalf("artifactPath failed: %v", err)
+	}
+	if expected := filepath.Join(packDir, "dist", "server.zip"); path != expected {
+		t.Errorf("Expected %s, got %s", expected, path)
+	}
+}
+
+func TestDefaultNameIsReproducible(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	packDir := writeTestPack(t, "name = \"Cool Pack\"\n", "")
+
+	path, err := Options{}.artifactPath(packDir, packDir, FormatCurseForge)
+	if err != nil {
+		t.Fatalf("artifactPath failed: %v", err)
+	}
+	if name := filepath.Base(path); name != "My Pack-curseforge.zip" {
+		t.Errorf("Unexpected default CurseForge name: %s", name)
+	}
+
+	path, err = Options{NameTemplate: "{{.Dir}}_{{.Timestamp}}"}.artifactPath(packDir, packDir, FormatModrinth)
+	if err != nil {
+		t.Fatalf("artifactPath failed: %v", err)
+	}
+	if name := filepath.Base(path); name != "My Pack_11-14_22-13-20.mrpack" {
+		t.Errorf("Expected the timestamp to come from SOURCE_DATE_EPOCH, got %s", name)
+	}
+}
+
+func TestRenderName(t *testing.T) {
+	data := NameData{Name: "Pack/With:Slashes", Version: "1.0", Format: "server"}
+
+	name, err := RenderName("{{.Name}}-{{.Version}}-{{.Format}}", data)
+	if err != nil {
+		t.Fatalf("RenderName failed: %v", err)
+	}
+	if name != "Pack_With_Slashes-1.0-server" {
+		t.Errorf("Unexpected name: %s", name)
+	}
+
+	if _, err := RenderName("{{.Nope}}", data); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+	if _, err := RenderName("{{.Missing", data); err == nil {
+		t.Error("Expected an error for an invalid template")
+	}
+	if _, err := RenderName("{{.Version}}", NameData{}); err == nil {
+		t.Error("Expected an error for an empty name")
+	}
+}
+
+func TestOptionsPackName(t *testing.T) {
+	packDir := filepath.Join(t.TempDir(), "My Pack")
+
+	if name := (Options{}).packName(packDir); name != "My Pack" {
+		t.Errorf("Expected directory name, got %q", name)
+	}
+	if name := (Options{PackName: "custom"}).packName(packDir); name != "custom" {
+		t.Errorf("Expected configured name, got %q", name)
+	}
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	if _, err := Export(t.TempDir(), "zip", Options{}); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
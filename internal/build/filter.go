@@ -0,0 +1,160 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/config"
+	"github.com/Merith-TK/packwiz-wrapper/internal/packwiz"
+)
+
+// commonExcludes are never copied by any export: build output, the temporary
+// directories of the exporters, the test server and bisect, and pw's own files
+var commonExcludes = []string{
+	"/.build/",
+	"/.git/",
+	"/.temp/",
+	"/.mmc-temp/",
+	"/.prism-temp/",
+	"/.technic/",
+	"/.server/",
+	"/.run/",
+	"/.bisect/",
+	"/" + config.FileName,
+}
+
+// DefaultExcludes are the patterns each export skips unless packwrap.toml
+// re-includes them
+var DefaultExcludes = map[string][]string{
+	FormatMultiMC: {
+		"/mods/",                           // downloaded by packwiz-installer when the instance launches
+		"/packwiz-installer-bootstrap.jar", // included separately
+	},
+	FormatPrism: {
+		"/mods/",                           // installed on launch, or bundled by the installer
+		"/packwiz-installer-bootstrap.jar", // included separately
+		"*.pw.toml",                        // only read from the pack URL on launch
+	},
+	FormatTechnic: {
+		"/mods/**/*.jar", // downloaded by the installer
+	},
+	FormatServer: {
+		"/resourcepacks/", // client-only
+		"/shaderpacks/",   // client-only
+		"/screenshots/",   // client-only
+		"/saves/",         // client-only
+		"/logs/",          // runtime generated
+		"/crash-reports/", // runtime generated
+		"/options.txt",    // client-only
+		"/optionsof.txt",  // OptiFine client settings
+		"/mods/**/*.jar",  // downloaded by the installer with server filtering
+		"!*.pw.toml",      // kept so the server can be updated with packwiz-installer
+	},
+}
+
+// fileFilter decides which pack files an export copies. It combines the
+// pack's .packwizignore, the format's default excludes and the filter in
+// packwrap.toml, in that order, so later patterns win.
+type fileFilter struct {
+	matcher *packwiz.IgnoreMatcher
+
+	// reincludes is set when a negated pattern can bring back files inside an
+	// excluded directory, so excluded directories must still be walked
+	reincludes bool
+}
+
+// newFileFilter loads the filter for format from the pack in packLocation.
+// outputDir is excluded as well when it lies inside the pack.
+func newFileFilter(packLocation, format, outputDir string) (*fileFilter, error) {
+	matcher, err := packwiz.LoadIgnoreMatcher(packLocation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", packwiz.IgnoreFile, err)
+	}
+	matcher.Add(commonExcludes...)
+	matcher.Add(DefaultExcludes[format]...)
+
+	if rel, err := filepath.Rel(packLocation, outputDir); err == nil && filepath.IsLocal(rel) {
+		matcher.Add("/" + filepath.ToSlash(rel) + "/")
+	}
+
+	cfg, err := config.Load(packLocation)
+	if err != nil {
+		return nil, err
+	}
+	filter := cfg.Build.Filter(format)
+	matcher.Add(filter.Exclude...)
+	for _, pattern := range filter.Include {
+		matcher.Add("!" + strings.TrimPrefix(pattern, "!"))
+	}
+
+	reincludes := len(filter.Include) > 0
+	for _, pattern := range DefaultExcludes[format] {
+		reincludes = reincludes || strings.HasPrefix(pattern, "!")
+	}
+
+	return &fileFilter{matcher: matcher, reincludes: reincludes}, nil
+}
+
+// exportFilter loads the file filter for format, also excluding the output directory
+func (o Options) exportFilter(packDir, packLocation, format string) (*fileFilter, error) {
+	outputDir, err := o.outputDir(packDir, packLocation)
+	if err != nil {
+		return nil, err
+	}
+	return newFileFilter(packLocation, format, outputDir)
+}
+
+// Skip reports whether the slash separated path, relative to the pack, is left out
+func (f *fileFilter) Skip(relPath string, isDir bool) bool {
+	return f.matcher.Match(relPath, isDir)
+}
+
+// copyPackFiles copies every file in packLocation that the filter keeps into destDir
+func copyPackFiles(packLocation, destDir string, filter *fileFilter) error {
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return err
+	}
+
+	absDest, err := filepath.Abs(destDir)
+	if err != nil {
+		return err
+	}
+
+	return filepath.Walk(packLocation, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(packLocation, path)
+		if err != nil {
+			return err
+		}
+		if relPath == "." {
+			return nil
+		}
+
+		// Never copy the destination into itself
+		if abs, err := filepath.Abs(path); err == nil && abs == absDest {
+			return filepath.SkipDir
+		}
+
+		slashPath := filepath.ToSlash(relPath)
+		if info.IsDir() {
+			// Files inside may still be re-included by a negated pattern
+			if !filter.Skip(slashPath, true) {
+				return os.MkdirAll(filepath.Join(destDir, relPath), 0755)
+			}
+			if !filter.reincludes {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filter.Skip(slashPath, false) {
+			return nil
+		}
+
+		return copyFile(path, filepath.Join(destDir, relPath))
+	})
+}
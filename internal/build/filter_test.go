@@ -0,0 +1,125 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultServerFilter(t *testing.T) {
+	packDir := writeTestPack(t, "name = \"Filter Test\"\n", "")
+	filter, err := newFileFilter(packDir, FormatServer, filepath.Join(packDir, ".build"))
+	if err != nil {
+		t.Fatalf("newFileFilter failed: %v", err)
+	}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		skip  bool
+	}{
+		{"config/mod.toml", false, false},
+		{"options.txt", false, true},
+		{"config/options.txt", false, false},
+		{"saves", true, true},
+		{"saves/world/level.dat", false, true},
+		{"mods/mod.jar", false, true},
+		{"mods/mod.pw.toml", false, false},
+		{"modsextra/mod.jar", false, false},
+		{"resourcepacks/pack.pw.toml", false, false},
+		{"resourcepacks/pack.zip", false, true},
+		{".git/config", false, true},
+		{".gitignore", false, true},
+		{"packwrap.toml", false, true},
+		{".build/pack.zip", false, true},
+	}
+
+	for _, tt := range tests {
+		if got := filter.Skip(tt.path, tt.isDir); got != tt.skip {
+			t.Errorf("Skip(%q) = %v, want %v", tt.path, got, tt.skip)
+		}
+	}
+}
+
+func TestFilterUsesPackwizignoreAndConfig(t *testing.T) {
+	packDir := writeTestPack(t, "name = \"Filter Test\"\n", `[build.multimc]
+exclude = ["*.psd"]
+include = ["/mods/README.md"]
+`)
+	if err := os.WriteFile(filepath.Join(packDir, ".packwizignore"), []byte("/notes/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	filter, err := newFileFilter(packDir, FormatMultiMC, filepath.Join(packDir, "dist"))
+	if err != nil {
+		t.Fatalf("newFileFilter failed: %v", err)
+	}
+
+	tests := map[string]bool{
+		"notes/todo.txt":     true,
+		"art/icon.psd":       true,
+		"mods/mod.pw.toml":   true,
+		"mods/README.md":     false,
+		"dist/pack.zip":      true,
+		"config/options.txt": false,
+		"options.txt":        false,
+	}
+	for path, skip := range tests {
+		if got := filter.Skip(path, false); got != skip {
+			t.Errorf("Skip(%q) = %v, want %v", path, got, skip)
+		}
+	}
+}
+
+func TestCopyPackFiles(t *testing.T) {
+	packDir := writeTestPack(t, "name = \"Filter Test\"\n", `[build.server]
+include = ["/saves/template/"]
+`)
+	files := []string{
+		"config/mod.toml",
+		"mods/mod.jar",
+		"mods/mod.pw.toml",
+		"saves/template/level.dat",
+		"saves/world/level.dat",
+		"options.txt",
+	}
+	for _, name := range files {
+		path := filepath.Join(packDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The destination lives inside the pack, as the exporters' temp directories do
+	destDir := filepath.Join(packDir, ".server")
+	filter, err := newFileFilter(packDir, FormatServer, filepath.Join(packDir, ".build"))
+	if err != nil {
+		t.Fatalf("newFileFilter failed: %v", err)
+	}
+	if err := copyPackFiles(packDir, destDir, filter); err != nil {
+		t.Fatalf("copyPackFiles failed: %v", err)
+	}
+
+	expected := map[string]bool{
+		"pack.toml":                true,
+		"config/mod.toml":          true,
+		"mods/mod.pw.toml":         true,
+		"saves/template/level.dat": true,
+		"mods/mod.jar":             false,
+		"saves/world/level.dat":    false,
+		"options.txt":              false,
+		".server":                  false,
+	}
+	for name, exists := range expected {
+		_, err := os.Stat(filepath.Join(destDir, filepath.FromSlash(name)))
+		if exists && err != nil {
+			t.Errorf("Expected %s to be copied: %v", name, err)
+		}
+		if !exists && err == nil {
+			t.Errorf("Expected %s to be skipped", name)
+		}
+	}
+}
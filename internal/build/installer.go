@@ -0,0 +1,475 @@
+package build
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/packwiz"
+	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
+)
+
+// InstallManifestFile lists the files an Installer put into a directory, like
+// packwiz-installer's packwiz.json, so files the pack drops are removed by the
+// next install
+const InstallManifestFile = ".packwrap-install.json"
+
+// Sides a metafile can target
+const (
+	SideClient = "client"
+	SideServer = "server"
+	SideBoth   = "both"
+)
+
+// Installer installs a packwiz pack into a directory without Java or
+// packwiz-installer-bootstrap. Mods are downloaded in parallel and every file
+// is checked against the hash recorded in the pack.
+type Installer struct {
+	// Side selects the metafiles to install: client, server, or empty for all.
+	// Metafiles without a side or with side both are always installed.
+	Side string
+
+	// SkipFiles installs only metafile downloads, for callers that copy the
+	// pack's other files themselves
+	SkipFiles bool
+
+	// SkipManifest leaves out InstallManifestFile, for one-off installs into
+	// fresh directories such as exports
+	SkipManifest bool
+
+	// Jobs is the number of parallel downloads, 8 when unset
+	Jobs int
+
+	// Client is used for downloads, http.DefaultClient when nil
+	Client *http.Client
+
+	// Cache is checked before downloading and receives every download; nil disables it
+	Cache *utils.Cache
+
+	// Progress is called after each file is handled; done counts up to total
+	Progress func(done, total int, file string)
+}
+
+// InstallResult counts what an install did
+type InstallResult struct {
+	Downloaded int // metafiles fetched from their download URL
+	Cached     int // metafiles taken from the download cache
+	Copied     int // pack files copied into the target
+	Skipped    int // files already present with the right hash
+	Removed    int // files of an earlier install that the pack no longer has
+}
+
+// installManifest is the content of InstallManifestFile
+type installManifest struct {
+	Files []string `json:"files"` // slash separated, relative to the target directory
+}
+
+// installTask is a single file to put into the target directory
+type installTask struct {
+	target     string // slash separated, relative to the target directory
+	source     string // local path for pack files
+	url        string // download URL for metafiles
+	hashFormat string
+	hash       string
+}
+
+// Install installs the pack in packLocation into targetDir. Files are placed
+// relative to the pack's index, as packwiz-installer does.
+func (i *Installer) Install(packLocation, targetDir string) (*InstallResult, error) {
+	data, err := packwiz.ReadPack(packLocation)
+	if err != nil {
+		return nil, err
+	}
+	if len(data.Errors) > 0 {
+		return nil, fmt.Errorf("failed to read metafiles: %w", errors.Join(data.Errors...))
+	}
+
+	tasks, err := i.tasks(data)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := i.Jobs
+	if jobs <= 0 {
+		jobs = 8
+	}
+	if jobs > len(tasks) {
+		jobs = len(tasks)
+	}
+
+	result := &InstallResult{}
+	var (
+		mu   sync.Mutex
+		done int
+		errs []error
+		wg   sync.WaitGroup
+	)
+	queue := make(chan installTask)
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range queue {
+				action, err := i.install(task, targetDir)
+
+				mu.Lock()
+				done++
+				switch {
+				case err != nil:
+					errs = append(errs, fmt.Errorf("failed to install %s: %w", task.target, err))
+				case action == installSkipped:
+					result.Skipped++
+				case action == installCopied:
+					result.Copied++
+				case action == installCached:
+					result.Cached++
+				default:
+					result.Downloaded++
+				}
+				if i.Progress != nil {
+					i.Progress(done, len(tasks), task.target)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, task := range tasks {
+		queue <- task
+	}
+	close(queue)
+	wg.Wait()
+
+	if !i.SkipManifest {
+		removed, err := updateInstallManifest(targetDir, tasks, len(errs) == 0)
+		result.Removed = removed
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return result, errors.Join(errs...)
+	}
+	return result, nil
+}
+
+// updateInstallManifest records the installed files in targetDir. After a
+// complete install, files listed by the previous manifest that are no longer
+// part of the pack are removed; after a failed one they stay listed so the
+// next install removes them instead.
+func updateInstallManifest(targetDir string, tasks []installTask, complete bool) (int, error) {
+	manifestPath := filepath.Join(targetDir, InstallManifestFile)
+
+	var previous installManifest
+	if data, err := os.ReadFile(manifestPath); err == nil {
+		// A corrupt manifest only means nothing is removed this time
+		json.Unmarshal(data, &previous)
+	} else if !os.IsNotExist(err) {
+		return 0, fmt.Errorf("failed to read %s: %w", InstallManifestFile, err)
+	}
+
+	installed := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		installed[task.target] = true
+	}
+
+	removed := 0
+	for _, file := range previous.Files {
+		file = path.Clean(file)
+		if installed[file] || !filepath.IsLocal(filepath.FromSlash(file)) {
+			continue
+		}
+		if !complete {
+			installed[file] = true
+			continue
+		}
+		err := os.Remove(filepath.Join(targetDir, filepath.FromSlash(file)))
+		if err == nil {
+			removed++
+		} else if !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove %s: %w", file, err)
+		}
+	}
+
+	manifest := installManifest{Files: make([]string, 0, len(installed))}
+	for file := range installed {
+		manifest.Files = append(manifest.Files, file)
+	}
+	sort.Strings(manifest.Files)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return removed, fmt.Errorf("failed to encode %s: %w", InstallManifestFile, err)
+	}
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return removed, err
+	}
+	if err := os.WriteFile(manifestPath, append(data, '\n'), 0644); err != nil {
+		return removed, fmt.Errorf("failed to write %s: %w", InstallManifestFile, err)
+	}
+	return removed, nil
+}
+
+// tasks lists the files to install, filtered by side
+func (i *Installer) tasks(data *packwiz.PackData) ([]installTask, error) {
+	indexDir := path.Dir(indexFile(data))
+
+	var tasks []installTask
+	if !i.SkipFiles {
+		for _, file := range data.Index.Files {
+			if file.Metafile {
+				continue
+			}
+			hashFormat := file.HashFormat
+			if hashFormat == "" {
+				hashFormat = data.Index.HashFormat
+			}
+			tasks = append(tasks, installTask{
+				target:     path.Clean(file.File),
+				source:     filepath.Join(data.Location, filepath.FromSlash(path.Join(indexDir, file.File))),
+				hashFormat: hashFormat,
+				hash:       file.Hash,
+			})
+		}
+	}
+
+	for _, mod := range data.Mods {
+		if !i.wantsMod(mod) {
+			continue
+		}
+
+		url, err := modDownloadURL(mod)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, installTask{
+			target:     modTarget(indexDir, mod),
+			url:        url,
+			hashFormat: mod.Download.HashFormat,
+			hash:       mod.Download.Hash,
+		})
+	}
+
+	for _, task := range tasks {
+		if !filepath.IsLocal(filepath.FromSlash(task.target)) {
+			return nil, fmt.Errorf("refusing to install %s outside the target directory", task.target)
+		}
+	}
+	return tasks, nil
+}
+
+// ModTarget is a metafile the installer installs and where its file goes
+type ModTarget struct {
+	Mod    packwiz.ModToml
+	Target string // slash separated, relative to the target directory
+}
+
+// ModTargets lists the metafiles of data that the installer installs
+func (i *Installer) ModTargets(data *packwiz.PackData) []ModTarget {
+	indexDir := path.Dir(indexFile(data))
+
+	var targets []ModTarget
+	for _, mod := range data.Mods {
+		if i.wantsMod(mod) {
+			targets = append(targets, ModTarget{Mod: mod, Target: modTarget(indexDir, mod)})
+		}
+	}
+	return targets
+}
+
+// indexFile returns the path of the pack's index, relative to the pack
+func indexFile(data *packwiz.PackData) string {
+	if data.Meta.Index.File == "" {
+		return "index.toml"
+	}
+	return data.Meta.Index.File
+}
+
+// modTarget returns where a metafile's file is installed. Metafile folders
+// are relative to the pack, targets to the index.
+func modTarget(indexDir string, mod packwiz.ModToml) string {
+	folder := mod.Parse.Path
+	if indexDir != "." {
+		folder = strings.TrimPrefix(strings.TrimPrefix(folder, indexDir), "/")
+	}
+	return path.Join(folder, mod.Filename)
+}
+
+// wantsMod reports whether a metafile belongs on the installer's side. Optional
+// mods are installed when they default to enabled, like a headless packwiz-installer.
+func (i *Installer) wantsMod(mod packwiz.ModToml) bool {
+	if mod.Option != nil && mod.Option.Optional && !mod.Option.Default {
+		return false
+	}
+	if i.Side == "" || mod.Side == "" || mod.Side == SideBoth {
+		return true
+	}
+	return mod.Side == i.Side
+}
+
+// modDownloadURL returns where a metafile's file is downloaded from. CurseForge
+// metafiles often have no URL, their files are served from the CurseForge CDN.
+func modDownloadURL(mod packwiz.ModToml) (string, error) {
+	if mod.Download.URL != "" {
+		return mod.Download.URL, nil
+	}
+
+	fileID := mod.Update.Curseforge.FileID
+	if fileID != 0 && mod.Filename != "" {
+		return fmt.Sprintf("https://mediafilez.forgecdn.net/files/%d/%d/%s",
+			fileID/1000, fileID%1000, strings.ReplaceAll(mod.Filename, " ", "%20")), nil
+	}
+
+	return "", fmt.Errorf("%s has no download URL", mod.Parse.ModID)
+}
+
+const (
+	installDownloaded = iota
+	installCopied
+	installCached
+	installSkipped
+)
+
+// install puts a single file into targetDir, skipping it when an identical copy exists
+func (i *Installer) install(task installTask, targetDir string) (int, error) {
+	dest := filepath.Join(targetDir, filepath.FromSlash(task.target))
+	if existing, err := os.Open(dest); err == nil {
+		matches, hashErr := hashMatches(existing, task.hashFormat, task.hash)
+		existing.Close()
+		if hashErr != nil {
+			return 0, hashErr
+		}
+		if matches {
+			return installSkipped, nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return 0, err
+	}
+
+	if task.source != "" {
+		file, err := os.Open(task.source)
+		if err != nil {
+			return 0, err
+		}
+		defer file.Close()
+		return installCopied, writeVerified(dest, file, task.hashFormat, task.hash)
+	}
+
+	if i.fromCache(task, dest) {
+		return installCached, nil
+	}
+
+	body, err := i.download(task.url)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	if err := writeVerified(dest, body, task.hashFormat, task.hash); err != nil {
+		return 0, err
+	}
+	if i.Cache != nil {
+		if err := i.Cache.Store(task.hashFormat, task.hash, dest); err != nil {
+			fmt.Printf("Warning: Failed to cache %s: %v\n", task.target, err)
+		}
+	}
+	return installDownloaded, nil
+}
+
+// fromCache installs a download from the cache, reporting whether it was there.
+// Entries that fail verification are removed so the file is downloaded again.
+func (i *Installer) fromCache(task installTask, dest string) bool {
+	if i.Cache == nil {
+		return false
+	}
+
+	cached, err := i.Cache.Open(task.hashFormat, task.hash)
+	if err != nil {
+		return false
+	}
+	err = writeVerified(dest, cached, task.hashFormat, task.hash)
+	cached.Close()
+	if err != nil {
+		os.Remove(cached.Name())
+		return false
+	}
+	return true
+}
+
+// download opens the response body of a GET request
+func (i *Installer) download(url string) (io.ReadCloser, error) {
+	client := i.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "packwiz-wrapper")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download %s: HTTP %d", url, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// writeVerified writes content to dest through a temporary file, keeping it
+// only when its hash matches
+func writeVerified(dest string, content io.Reader, hashFormat, expected string) error {
+	hasher, err := utils.NewFileHasher(hashFormat)
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(dest), ".install-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := io.Copy(io.MultiWriter(temp, hasher), content); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	if actual := hasher.String(); !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("%s hash mismatch: expected %s, got %s", hashFormat, expected, actual)
+	}
+	if err := os.Chmod(temp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), dest)
+}
+
+// hashMatches reports whether content has the expected hash
+func hashMatches(content io.Reader, hashFormat, expected string) (bool, error) {
+	hasher, err := utils.NewFileHasher(hashFormat)
+	if err != nil {
+		return false, err
+	}
+	if _, err := io.Copy(hasher, content); err != nil {
+		return false, err
+	}
+	return strings.EqualFold(hasher.String(), expected), nil
+}
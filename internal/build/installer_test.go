@@ -0,0 +1,320 @@
+package build
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/packwiz"
+	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
+)
+
+func sha256Hex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func sha1Hex(data string) string {
+	sum := sha1.Sum([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// writeInstallerPack writes a pack with a config file and three mods served by server
+func writeInstallerPack(t *testing.T, serverURL, serverModHash string) string {
+	t.Helper()
+	packDir := t.TempDir()
+	configContent := "setting=true\n"
+
+	files := map[string]string{
+		"pack.toml": `name = "Installer Test"
+[index]
+file = "index.toml"
+hash-format = "sha256"
+hash = ""
+[versions]
+minecraft = "1.20.1"
+`,
+		"config/test.cfg": configContent,
+		"mods/server-mod.pw.toml": fmt.Sprintf(`name = "Server Mod"
+filename = "server-mod.jar"
+side = "server"
+[download]
+url = "%s/server-mod.jar"
+hash-format = "sha1"
+hash = "%s"
+`, serverURL, serverModHash),
+		"mods/client-mod.pw.toml": fmt.Sprintf(`name = "Client Mod"
+filename = "client-mod.jar"
+side = "client"
+[download]
+url = "%s/client-mod.jar"
+hash-format = "sha256"
+hash = "%s"
+`, serverURL, sha256Hex("client")),
+		"mods/optional-mod.pw.toml": fmt.Sprintf(`name = "Optional Mod"
+filename = "optional-mod.jar"
+side = "both"
+[download]
+url = "%s/optional-mod.jar"
+hash-format = "sha256"
+hash = "%s"
+[option]
+optional = true
+default = false
+`, serverURL, sha256Hex("optional")),
+	}
+	files["index.toml"] = fmt.Sprintf(`hash-format = "sha256"
+
+[[files]]
+file = "config/test.cfg"
+hash = "%s"
+
+[[files]]
+file = "mods/client-mod.pw.toml"
+hash = ""
+metafile = true
+
+[[files]]
+file = "mods/optional-mod.pw.toml"
+hash = ""
+metafile = true
+
+[[files]]
+file = "mods/server-mod.pw.toml"
+hash = ""
+metafile = true
+`, sha256Hex(configContent))
+
+	for name, content := range files {
+		path := filepath.Join(packDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return packDir
+}
+
+func newModServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	requests := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/server-mod.jar":
+			w.Write([]byte("server"))
+		case "/client-mod.jar":
+			w.Write([]byte("client"))
+		case "/optional-mod.jar":
+			w.Write([]byte("optional"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func TestInstallerInstallsServerSide(t *testing.T) {
+	server, requests := newModServer(t)
+	packDir := writeInstallerPack(t, server.URL, sha1Hex("server"))
+	targetDir := t.TempDir()
+
+	installer := &Installer{Side: SideServer}
+	result, err := installer.Install(packDir, targetDir)
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	if result.Downloaded != 1 || result.Copied != 1 || result.Skipped != 0 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(targetDir, "mods", "server-mod.jar")); err != nil || string(data) != "server" {
+		t.Errorf("Expected the server mod to be installed, got %q (%v)", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "config", "test.cfg")); err != nil {
+		t.Errorf("Expected the config file to be copied: %v", err)
+	}
+	for _, name := range []string{"client-mod.jar", "optional-mod.jar"} {
+		if _, err := os.Stat(filepath.Join(targetDir, "mods", name)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be left out", name)
+		}
+	}
+
+	// A second install finds every file up to date
+	result, err = installer.Install(packDir, targetDir)
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	if result.Skipped != 2 || requests.Load() != 1 {
+		t.Errorf("Expected everything to be skipped, got %+v after %d requests", result, requests.Load())
+	}
+}
+
+func TestInstallerRemovesOutdatedFiles(t *testing.T) {
+	server, _ := newModServer(t)
+	packDir := writeInstallerPack(t, server.URL, sha1Hex("server"))
+	targetDir := t.TempDir()
+
+	installer := &Installer{Side: SideServer}
+	if _, err := installer.Install(packDir, targetDir); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	// Files the installer did not put there are never touched
+	if err := os.WriteFile(filepath.Join(targetDir, "mods", "manual.jar"), []byte("mine"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Updating the mod changes its filename, as 'pw mod update' does
+	metafile := fmt.Sprintf(`name = "Server Mod"
+filename = "server-mod-2.jar"
+side = "server"
+[download]
+url = "%s/server-mod.jar"
+hash-format = "sha1"
+hash = "%s"
+`, server.URL, sha1Hex("server"))
+	if err := os.WriteFile(filepath.Join(packDir, "mods", "server-mod.pw.toml"), []byte(metafile), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := packwiz.Refresh(packDir, packwiz.RefreshOptions{CacheDir: t.TempDir()}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := installer.Install(packDir, targetDir)
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	if result.Removed != 1 {
+		t.Errorf("Expected the old jar to be removed, got %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "mods", "server-mod.jar")); !os.IsNotExist(err) {
+		t.Error("Expected the old jar to be gone")
+	}
+	for _, name := range []string{"server-mod-2.jar", "manual.jar"} {
+		if _, err := os.Stat(filepath.Join(targetDir, "mods", name)); err != nil {
+			t.Errorf("Expected %s to be installed: %v", name, err)
+		}
+	}
+
+	data, _ := os.ReadFile(filepath.Join(targetDir, InstallManifestFile))
+	if !strings.Contains(string(data), "mods/server-mod-2.jar") || strings.Contains(string(data), "mods/server-mod.jar") {
+		t.Errorf("Unexpected %s:\n%s", InstallManifestFile, data)
+	}
+}
+
+func TestInstallerUsesCache(t *testing.T) {
+	server, requests := newModServer(t)
+	packDir := writeInstallerPack(t, server.URL, sha1Hex("server"))
+	cache := &utils.Cache{Dir: t.TempDir()}
+
+	installer := &Installer{Side: SideServer, SkipFiles: true, Cache: cache}
+	if _, err := installer.Install(packDir, t.TempDir()); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	// A fresh target is filled from the cache without downloading again
+	targetDir := t.TempDir()
+	result, err := installer.Install(packDir, targetDir)
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	if result.Cached != 1 || result.Downloaded != 0 || requests.Load() != 1 {
+		t.Errorf("Expected a cache hit, got %+v after %d requests", result, requests.Load())
+	}
+	if data, err := os.ReadFile(filepath.Join(targetDir, "mods", "server-mod.jar")); err != nil || string(data) != "server" {
+		t.Errorf("Expected the cached mod to be installed, got %q (%v)", data, err)
+	}
+
+	// Corrupt entries are discarded and downloaded again
+	if err := os.WriteFile(cache.Path("sha1", sha1Hex("server")), []byte("corrupt"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err = installer.Install(packDir, t.TempDir())
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	if result.Downloaded != 1 || requests.Load() != 2 {
+		t.Errorf("Expected a fresh download, got %+v after %d requests", result, requests.Load())
+	}
+}
+
+func TestInstallerSkipFiles(t *testing.T) {
+	server, _ := newModServer(t)
+	packDir := writeInstallerPack(t, server.URL, sha1Hex("server"))
+	targetDir := t.TempDir()
+
+	installer := &Installer{Side: SideClient, SkipFiles: true}
+	result, err := installer.Install(packDir, targetDir)
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	if result.Downloaded != 1 || result.Copied != 0 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "config")); !os.IsNotExist(err) {
+		t.Error("Expected pack files to be skipped")
+	}
+}
+
+func TestInstallerUsesPerFileHashFormat(t *testing.T) {
+	server, _ := newModServer(t)
+	packDir := writeInstallerPack(t, server.URL, sha1Hex("server"))
+	targetDir := t.TempDir()
+
+	// A refresh of an index in another format records sha256 on each file
+	index := fmt.Sprintf("hash-format = \"sha1\"\n\n[[files]]\nfile = \"config/test.cfg\"\nhash = \"%s\"\nhash-format = \"sha256\"\n", sha256Hex("setting=true\n"))
+	if err := os.WriteFile(filepath.Join(packDir, "index.toml"), []byte(index), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := (&Installer{Side: SideServer}).Install(packDir, targetDir)
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	if result.Copied != 1 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+func TestInstallerRejectsHashMismatch(t *testing.T) {
+	server, _ := newModServer(t)
+	packDir := writeInstallerPack(t, server.URL, sha1Hex("tampered"))
+	targetDir := t.TempDir()
+
+	_, err := (&Installer{Side: SideServer}).Install(packDir, targetDir)
+	if err == nil || !strings.Contains(err.Error(), "hash mismatch") {
+		t.Fatalf("Expected a hash mismatch error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "mods", "server-mod.jar")); !os.IsNotExist(err) {
+		t.Error("Expected the mismatched download to be discarded")
+	}
+}
+
+func TestModDownloadURLFallsBackToCurseForge(t *testing.T) {
+	var mod packwiz.ModToml
+	mod.Filename = "cf mod.jar"
+	mod.Update.Curseforge.FileID = 4567890
+
+	url, err := modDownloadURL(mod)
+	if err != nil {
+		t.Fatalf("modDownloadURL failed: %v", err)
+	}
+	if url != "https://mediafilez.forgecdn.net/files/4567/890/cf%20mod.jar" {
+		t.Errorf("Unexpected URL: %s", url)
+	}
+
+	mod.Update.Curseforge.FileID = 0
+	if _, err := modDownloadURL(mod); err == nil {
+		t.Error("Expected an error without a URL or CurseForge file")
+	}
+}
@@ -0,0 +1,138 @@
+package build
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
+)
+
+// Files written next to the build artifacts
+const (
+	ChecksumsFile = "SHA256SUMS"
+	ManifestFile  = "manifest.json"
+)
+
+// Manifest describes the artifacts of a pack build
+type Manifest struct {
+	Pack             string     `json:"pack"`
+	Version          string     `json:"version,omitempty"`
+	MinecraftVersion string     `json:"minecraft,omitempty"`
+	BuildTime        time.Time  `json:"buildTime"`
+	Artifacts        []Artifact `json:"artifacts"`
+}
+
+// Artifact is a single build output listed in the manifest
+type Artifact struct {
+	Format string `json:"format"`
+	Path   string `json:"path"` // slash separated, relative to the manifest
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// ReadManifest reads the manifest in dir. A missing manifest is returned empty.
+func ReadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return &Manifest{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ManifestFile, err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", ManifestFile, err)
+	}
+	return &manifest, nil
+}
+
+// recordArtifact hashes an artifact and adds it to the manifest and SHA256SUMS
+// in the output directory. Entries from earlier builds are kept while their
+// files still exist, so single format builds do not drop the other formats.
+func (o Options) recordArtifact(packDir, packLocation, format, artifactPath string) error {
+	dir, err := o.outputDir(packDir, packLocation)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	meta, err := readPackToml(packLocation)
+	if err != nil {
+		return fmt.Errorf("failed to read pack.toml: %w", err)
+	}
+
+	relPath, err := filepath.Rel(dir, artifactPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve artifact path: %w", err)
+	}
+	artifact := Artifact{Format: format, Path: filepath.ToSlash(relPath)}
+	if artifact.SHA256, err = utils.HashFile(artifactPath, "sha256"); err != nil {
+		return fmt.Errorf("failed to hash %s: %w", filepath.Base(artifactPath), err)
+	}
+	info, err := os.Stat(artifactPath)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", filepath.Base(artifactPath), err)
+	}
+	artifact.Size = info.Size()
+
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		return err
+	}
+	manifest.Pack = meta.Name
+	if manifest.Pack == "" {
+		manifest.Pack = o.packName(packDir)
+	}
+	manifest.Version = meta.Version
+	manifest.MinecraftVersion = meta.Versions.Minecraft
+	if manifest.MinecraftVersion == "" {
+		manifest.MinecraftVersion = meta.McVersion
+	}
+	manifest.BuildTime = SourceDateEpoch(packLocation)
+
+	artifacts := []Artifact{artifact}
+	for _, existing := range manifest.Artifacts {
+		if existing.Path == artifact.Path {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(existing.Path))); err != nil {
+			continue
+		}
+		artifacts = append(artifacts, existing)
+	}
+	sort.Slice(artifacts, func(i, j int) bool {
+		return artifacts[i].Path < artifacts[j].Path
+	})
+	manifest.Artifacts = artifacts
+
+	return manifest.write(dir)
+}
+
+// write saves the manifest and the matching SHA256SUMS file to dir
+func (m *Manifest) write(dir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", ManifestFile, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", ManifestFile, err)
+	}
+
+	// Same layout as sha256sum, so `sha256sum -c SHA256SUMS` verifies the build
+	var sums strings.Builder
+	for _, artifact := range m.Artifacts {
+		fmt.Fprintf(&sums, "%s  %s\n", artifact.SHA256, artifact.Path)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ChecksumsFile), []byte(sums.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", ChecksumsFile, err)
+	}
+	return nil
+}
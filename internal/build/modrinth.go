@@ -1,52 +1,38 @@
 package build
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"time"
 
+	"github.com/Merith-TK/packwiz-wrapper/internal/packwiz"
 	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
 )
 
-// ExportModrinth exports the pack as a Modrinth mrpack file
-func ExportModrinth(packDir, packName string) error {
-	fmt.Println("💚 Exporting Modrinth pack...")
-
+// ExportModrinth exports the pack as a Modrinth mrpack file and returns its path
+func ExportModrinth(packDir string, options Options) (string, error) {
 	// Find pack.toml location
 	packLocation := utils.FindPackToml(packDir)
 	if packLocation == "" {
-		return fmt.Errorf("pack.toml not found")
-	}
-
-	// Create .build directory
-	buildDir := filepath.Join(packDir, ".build")
-	if err := os.MkdirAll(buildDir, 0755); err != nil {
-		return fmt.Errorf("failed to create .build directory: %w", err)
+		return "", fmt.Errorf("pack.toml not found")
 	}
 
-	// Create output filename with timestamp
-	timestamp := time.Now().Format("_01-02_15-04-05")
-	outputFilename := fmt.Sprintf("%s-modrinth%s.mrpack", packName, timestamp)
-	outputPath := filepath.Join(buildDir, outputFilename)
-
-	// Run packwiz modrinth export using self-execution with output specified
-	executable, err := os.Executable()
+	outputPath, err := options.artifactPath(packDir, packLocation, FormatModrinth)
 	if err != nil {
-		return fmt.Errorf("failed to get executable path: %w", err)
+		return "", err
 	}
 
 	packTomlPath := filepath.Join(packLocation, "pack.toml")
-	cmd := exec.Command(executable, "modrinth", "export", "--pack-file", packTomlPath, "-o", outputPath)
-	cmd.Dir = packDir
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	runner := &packwiz.Runner{Dir: packLocation, Stdout: os.Stdout, Stderr: os.Stderr}
+	if _, err := runner.Run(context.Background(), "modrinth", "export", "--pack-file", packTomlPath, "-o", outputPath); err != nil {
+		return "", fmt.Errorf("packwiz modrinth export failed: %w", err)
+	}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("packwiz modrinth export failed: %w", err)
+	// packwiz stamps entries with the current time
+	if err := NormalizeZip(outputPath, SourceDateEpoch(packLocation)); err != nil {
+		return "", err
 	}
 
-	fmt.Printf("✅ Exported Modrinth pack to .build/%s\n", outputFilename)
-	return nil
+	return outputPath, nil
 }
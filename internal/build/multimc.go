@@ -16,6 +16,11 @@ import (
 type MultiMCComponent struct {
 	UID     string `json:"uid"`
 	Version string `json:"version"`
+
+	// Important components cannot be removed in the launcher, dependency-only
+	// components are hidden and follow the component requiring them
+	Important      bool `json:"important,omitempty"`
+	DependencyOnly bool `json:"dependencyOnly,omitempty"`
 }
 
 // MultiMCPack represents the mmc-pack.json structure
@@ -24,20 +29,19 @@ type MultiMCPack struct {
 	FormatVersion int                `json:"formatVersion"`
 }
 
-// ExportMultiMC exports the pack as a MultiMC instance
-func ExportMultiMC(packDir, packName string, useLocal bool) error {
-	fmt.Println("🎮 Exporting MultiMC pack...")
-
+// ExportMultiMC exports the pack as a MultiMC instance and returns the zip path
+func ExportMultiMC(packDir string, options Options) (string, error) {
 	// Find pack.toml location
 	packLocation := utils.FindPackToml(packDir)
 	if packLocation == "" {
-		return fmt.Errorf("pack.toml not found")
+		return "", fmt.Errorf("pack.toml not found")
 	}
+	packName := options.packName(packDir)
 
 	// Read pack.toml to get version information
 	packToml, err := readPackToml(packLocation)
 	if err != nil {
-		return fmt.Errorf("failed to read pack.toml: %w", err)
+		return "", fmt.Errorf("failed to read pack.toml: %w", err)
 	}
 
 	// Create temporary build directory
@@ -45,17 +49,17 @@ func ExportMultiMC(packDir, packName string, useLocal bool) error {
 	defer os.RemoveAll(tempDir)
 
 	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return fmt.Errorf("failed to create temporary directory: %w", err)
+		return "", fmt.Errorf("failed to create temporary directory: %w", err)
 	}
 
 	// Create instance.cfg
-	if err := createInstanceCfg(tempDir, packName, packLocation, useLocal); err != nil {
-		return fmt.Errorf("failed to create instance.cfg: %w", err)
+	if err := createInstanceCfg(tempDir, packName, packLocation, options.UseLocal); err != nil {
+		return "", fmt.Errorf("failed to create instance.cfg: %w", err)
 	}
 
 	// Create mmc-pack.json
 	if err := createMMCPack(tempDir, packToml); err != nil {
-		return fmt.Errorf("failed to create mmc-pack.json: %w", err)
+		return "", fmt.Errorf("failed to create mmc-pack.json: %w", err)
 	}
 
 	// Copy icon if it exists
@@ -70,28 +74,29 @@ func ExportMultiMC(packDir, packName string, useLocal bool) error {
 
 	// Ensure packwiz-installer-bootstrap.jar is included
 	if err := ensurePackwizInstaller(tempDir, packLocation); err != nil {
-		return fmt.Errorf("failed to ensure packwiz installer: %w", err)
+		return "", fmt.Errorf("failed to ensure packwiz installer: %w", err)
 	}
 
 	// Copy .minecraft directory (excluding unnecessary files)
 	minecraftDir := filepath.Join(tempDir, ".minecraft")
-	if err := copyMinecraftDir(packLocation, minecraftDir); err != nil {
-		return fmt.Errorf("failed to copy .minecraft directory: %w", err)
+	filter, err := options.exportFilter(packDir, packLocation, FormatMultiMC)
+	if err != nil {
+		return "", err
+	}
+	if err := copyPackFiles(packLocation, minecraftDir, filter); err != nil {
+		return "", fmt.Errorf("failed to copy .minecraft directory: %w", err)
 	}
 
 	// Create the zip file
-	buildDir := filepath.Join(packDir, ".build")
-	if err := os.MkdirAll(buildDir, 0755); err != nil {
-		return fmt.Errorf("failed to create .build directory: %w", err)
+	zipPath, err := options.artifactPath(packDir, packLocation, FormatMultiMC)
+	if err != nil {
+		return "", err
 	}
-
-	zipPath := filepath.Join(buildDir, packName+"-multimc.zip")
-	if err := CreateZipFromDir(tempDir, zipPath); err != nil {
-		return fmt.Errorf("failed to create zip file: %w", err)
+	if err := CreateZipFromDirAt(tempDir, zipPath, SourceDateEpoch(packLocation)); err != nil {
+		return "", fmt.Errorf("failed to create zip file: %w", err)
 	}
 
-	fmt.Printf("✅ Created MultiMC pack: .build/%s-multimc.zip\n", packName)
-	return nil
+	return zipPath, nil
 }
 
 // readPackToml reads and parses the pack.toml file
@@ -144,11 +149,10 @@ func createMMCPack(tempDir string, packToml *packwiz.PackToml) error {
 		})
 	}
 
-	// Add LWJGL component (usually 3.3.3 for modern versions)
-	components = append(components, MultiMCComponent{
-		UID:     "org.lwjgl3",
-		Version: "3.3.3",
-	})
+	// Add the LWJGL component matching the Minecraft version
+	if mcVersion != "" {
+		components = append(components, lwjglComponent(mcVersion))
+	}
 
 	// Add mod loader components
 	if packToml.Versions.Fabric != "" {
@@ -165,6 +169,13 @@ func createMMCPack(tempDir string, packToml *packwiz.PackToml) error {
 		})
 	}
 
+	if packToml.Versions.NeoForge != "" {
+		components = append(components, MultiMCComponent{
+			UID:     "net.neoforged",
+			Version: packToml.Versions.NeoForge,
+		})
+	}
+
 	if packToml.Versions.Quilt != "" {
 		components = append(components, MultiMCComponent{
 			UID:     "org.quiltmc.quilt-loader",
@@ -215,63 +226,6 @@ func getPackURL(packLocation string, useLocal bool) string {
 	return remoteURL
 }
 
-// copyMinecraftDir copies the .minecraft directory excluding unnecessary files
-func copyMinecraftDir(srcDir, destDir string) error {
-	if err := os.MkdirAll(destDir, 0755); err != nil {
-		return err
-	}
-
-	return filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Get relative path
-		relPath, err := filepath.Rel(srcDir, path)
-		if err != nil {
-			return err
-		}
-
-		// Skip certain files/directories
-		if shouldSkipFile(relPath) {
-			if info.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		destPath := filepath.Join(destDir, relPath)
-
-		if info.IsDir() {
-			return os.MkdirAll(destPath, info.Mode())
-		} else {
-			return copyFile(path, destPath)
-		}
-	})
-}
-
-// shouldSkipFile determines if a file should be skipped during MultiMC export
-func shouldSkipFile(relPath string) bool {
-	skipPatterns := []string{
-		"mods", // Skip mods directory - will be downloaded by packwiz
-		".build",
-		".git",
-		".temp",
-		".mmc-temp", // Skip our own temp directory
-		".technic",  // Skip other temp directories
-		".server",
-		"packwiz-installer-bootstrap.jar", // Will be included separately
-	}
-
-	for _, pattern := range skipPatterns {
-		if strings.HasPrefix(relPath, pattern) || relPath == pattern {
-			return true
-		}
-	}
-
-	return false
-}
-
 // copyFile copies a file from src to dest
 func copyFile(src, dest string) error {
 	// Create destination directory if it doesn't exist
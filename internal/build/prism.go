@@ -0,0 +1,223 @@
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/config"
+	"github.com/Merith-TK/packwiz-wrapper/internal/packwiz"
+	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
+)
+
+// Default Prism instance heap limits in MiB
+const (
+	DefaultPrismMinMemory = 1024
+	DefaultPrismMaxMemory = 4096
+)
+
+// lwjglVersions maps the first Minecraft version of each range to the LWJGL
+// component Mojang ships with it, newest first
+var lwjglVersions = []struct {
+	minecraft string
+	uid       string
+	version   string
+}{
+	{"1.20.5", "org.lwjgl3", "3.3.3"},
+	{"1.20.2", "org.lwjgl3", "3.3.2"},
+	{"1.19", "org.lwjgl3", "3.3.1"},
+	{"1.17", "org.lwjgl3", "3.2.2"},
+	{"1.14", "org.lwjgl3", "3.2.1"},
+	{"1.13", "org.lwjgl3", "3.1.6"},
+	{"1.8", "org.lwjgl", "2.9.4-nightly-20150209"},
+	{"1.7", "org.lwjgl", "2.9.1"},
+	{"0", "org.lwjgl", "2.9.0"},
+}
+
+// lwjglComponent returns the LWJGL component for a Minecraft version
+func lwjglComponent(mcVersion string) MultiMCComponent {
+	for _, lwjgl := range lwjglVersions {
+		if utils.CompareMinecraftVersions(mcVersion, lwjgl.minecraft) >= 0 {
+			return MultiMCComponent{UID: lwjgl.uid, Version: lwjgl.version, DependencyOnly: true}
+		}
+	}
+	last := lwjglVersions[len(lwjglVersions)-1]
+	return MultiMCComponent{UID: last.uid, Version: last.version, DependencyOnly: true}
+}
+
+// ExportPrism exports the pack as a Prism Launcher instance and returns the zip path
+func ExportPrism(packDir string, options Options) (string, error) {
+	packLocation := utils.FindPackToml(packDir)
+	if packLocation == "" {
+		return "", fmt.Errorf("pack.toml not found")
+	}
+	packName := options.packName(packDir)
+
+	packToml, err := readPackToml(packLocation)
+	if err != nil {
+		return "", fmt.Errorf("failed to read pack.toml: %w", err)
+	}
+	mcVersion := packwiz.MinecraftVersion(packToml)
+	if mcVersion == "" {
+		return "", fmt.Errorf("could not determine Minecraft version from pack.toml")
+	}
+
+	cfg, err := config.Load(packLocation)
+	if err != nil {
+		return "", err
+	}
+	prism := cfg.Build.Prism
+	bundleMods := options.BundleMods || prism.BundleMods
+
+	tempDir := filepath.Join(packDir, ".prism-temp")
+	defer os.RemoveAll(tempDir)
+	if err := os.MkdirAll(tempDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	minecraftDir := filepath.Join(tempDir, ".minecraft")
+
+	// Copy the pack's files
+	filter, err := options.exportFilter(packDir, packLocation, FormatPrism)
+	if err != nil {
+		return "", err
+	}
+	if err := copyPackFiles(packLocation, minecraftDir, filter); err != nil {
+		return "", fmt.Errorf("failed to copy .minecraft directory: %w", err)
+	}
+
+	// Either bundle the client mods or install them with the bootstrap on launch
+	preLaunch := ""
+	if bundleMods {
+		fmt.Println("📦 Bundling mods for offline play...")
+		installer := &Installer{Side: SideClient, SkipFiles: true, SkipManifest: true, Cache: utils.DefaultCache()}
+		if _, err := installer.Install(packLocation, minecraftDir); err != nil {
+			return "", fmt.Errorf("failed to bundle mods: %w", err)
+		}
+	} else {
+		if err := ensurePackwizInstaller(minecraftDir, packLocation); err != nil {
+			return "", fmt.Errorf("failed to ensure packwiz installer: %w", err)
+		}
+		preLaunch = fmt.Sprintf(`"$INST_JAVA" -jar packwiz-installer-bootstrap.jar %s`, getPackURL(packLocation, options.UseLocal))
+	}
+
+	instance := prismInstance{
+		Name:      packName,
+		IconKey:   sanitizeIconName(packName) + "_icon",
+		MinMemory: prism.MinMemory,
+		MaxMemory: prism.MaxMemory,
+		Java:      utils.GetRequiredJavaVersion(mcVersion),
+		PreLaunch: preLaunch,
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "instance.cfg"), []byte(instance.String()), 0644); err != nil {
+		return "", fmt.Errorf("failed to create instance.cfg: %w", err)
+	}
+
+	if err := createPrismPack(tempDir, packToml); err != nil {
+		return "", fmt.Errorf("failed to create mmc-pack.json: %w", err)
+	}
+
+	iconPath := filepath.Join(packLocation, "icon.png")
+	if _, err := os.Stat(iconPath); err == nil {
+		if err := copyFile(iconPath, filepath.Join(tempDir, instance.IconKey+".png")); err != nil {
+			fmt.Printf("Warning: Failed to copy icon: %v\n", err)
+		}
+	}
+
+	zipPath, err := options.artifactPath(packDir, packLocation, FormatPrism)
+	if err != nil {
+		return "", err
+	}
+	if err := CreateZipFromDirAt(tempDir, zipPath, SourceDateEpoch(packLocation)); err != nil {
+		return "", fmt.Errorf("failed to create zip file: %w", err)
+	}
+
+	return zipPath, nil
+}
+
+// prismInstance holds the instance.cfg settings of a Prism instance
+type prismInstance struct {
+	Name      string
+	IconKey   string
+	MinMemory int // MiB, DefaultPrismMinMemory when zero
+	MaxMemory int // MiB, DefaultPrismMaxMemory when zero
+	Java      int // required Java major version
+	PreLaunch string
+}
+
+// String renders instance.cfg
+func (p prismInstance) String() string {
+	minMemory, maxMemory := p.MinMemory, p.MaxMemory
+	if minMemory <= 0 {
+		minMemory = DefaultPrismMinMemory
+	}
+	if maxMemory <= 0 {
+		maxMemory = DefaultPrismMaxMemory
+	}
+	if minMemory > maxMemory {
+		minMemory = maxMemory
+	}
+
+	var cfg strings.Builder
+	cfg.WriteString("[General]\n")
+	cfg.WriteString("ConfigVersion=1.2\n")
+	cfg.WriteString("InstanceType=OneSix\n")
+	fmt.Fprintf(&cfg, "name=%s\n", p.Name)
+	fmt.Fprintf(&cfg, "iconKey=%s\n", p.IconKey)
+	cfg.WriteString("OverrideMemory=true\n")
+	fmt.Fprintf(&cfg, "MinMemAlloc=%d\n", minMemory)
+	fmt.Fprintf(&cfg, "MaxMemAlloc=%d\n", maxMemory)
+
+	// Let Prism pick, or download, a Java matching the Minecraft version
+	fmt.Fprintf(&cfg, "# Requires Java %d\n", p.Java)
+	cfg.WriteString("AutomaticJava=true\n")
+	cfg.WriteString("IgnoreJavaCompatibility=false\n")
+
+	if p.PreLaunch != "" {
+		cfg.WriteString("OverrideCommands=true\n")
+		fmt.Fprintf(&cfg, "PreLaunchCommand=%s\n", p.PreLaunch)
+	}
+	return cfg.String()
+}
+
+// prismComponents returns the mmc-pack.json components for a pack: LWJGL,
+// Minecraft, then the loader with the intermediary mappings it needs
+func prismComponents(packToml *packwiz.PackToml) []MultiMCComponent {
+	mcVersion := packwiz.MinecraftVersion(packToml)
+	components := []MultiMCComponent{
+		lwjglComponent(mcVersion),
+		{UID: "net.minecraft", Version: mcVersion, Important: true},
+	}
+
+	versions := packToml.Versions
+	switch {
+	case versions.NeoForge != "":
+		components = append(components, MultiMCComponent{UID: "net.neoforged", Version: versions.NeoForge})
+	case versions.Forge != "":
+		components = append(components, MultiMCComponent{UID: "net.minecraftforge", Version: versions.Forge})
+	case versions.Fabric != "":
+		components = append(components,
+			MultiMCComponent{UID: "net.fabricmc.intermediary", Version: mcVersion, DependencyOnly: true},
+			MultiMCComponent{UID: "net.fabricmc.fabric-loader", Version: versions.Fabric})
+	case versions.Quilt != "":
+		components = append(components,
+			MultiMCComponent{UID: "net.fabricmc.intermediary", Version: mcVersion, DependencyOnly: true},
+			MultiMCComponent{UID: "org.quiltmc.quilt-loader", Version: versions.Quilt})
+	}
+	return components
+}
+
+// createPrismPack writes mmc-pack.json for a Prism instance
+func createPrismPack(tempDir string, packToml *packwiz.PackToml) error {
+	pack := MultiMCPack{
+		Components:    prismComponents(packToml),
+		FormatVersion: 1,
+	}
+
+	data, err := json.MarshalIndent(pack, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(tempDir, "mmc-pack.json"), data, 0644)
+}
@@ -0,0 +1,161 @@
+package build
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/packwiz"
+	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
+)
+
+func TestLWJGLComponent(t *testing.T) {
+	tests := []struct {
+		mcVersion string
+		uid       string
+		version   string
+	}{
+		{"1.21.1", "org.lwjgl3", "3.3.3"},
+		{"1.20.5", "org.lwjgl3", "3.3.3"},
+		{"1.20.4", "org.lwjgl3", "3.3.2"},
+		{"1.20.1", "org.lwjgl3", "3.3.1"},
+		{"1.18.2", "org.lwjgl3", "3.2.2"},
+		{"1.17.1", "org.lwjgl3", "3.2.2"},
+		{"1.16.5", "org.lwjgl3", "3.2.1"},
+		{"1.14.4", "org.lwjgl3", "3.2.1"},
+		{"1.13.2", "org.lwjgl3", "3.1.6"},
+		{"1.12.2", "org.lwjgl", "2.9.4-nightly-20150209"},
+		{"1.7.10", "org.lwjgl", "2.9.1"},
+		{"1.6.4", "org.lwjgl", "2.9.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mcVersion, func(t *testing.T) {
+			component := lwjglComponent(tt.mcVersion)
+			if component.UID != tt.uid || component.Version != tt.version {
+				t.Errorf("Expected %s %s, got %s %s", tt.uid, tt.version, component.UID, component.Version)
+			}
+			if !component.DependencyOnly {
+				t.Error("Expected LWJGL to be dependency-only")
+			}
+		})
+	}
+}
+
+// TestLWJGLVersionsNewestFirst checks that every row of the table is older than the one above it
+func TestLWJGLVersionsNewestFirst(t *testing.T) {
+	for i := 1; i < len(lwjglVersions); i++ {
+		newer, older := lwjglVersions[i-1], lwjglVersions[i]
+		if utils.CompareMinecraftVersions(newer.minecraft, older.minecraft) <= 0 {
+			t.Errorf("Minecraft %s should come after %s", older.minecraft, newer.minecraft)
+		}
+
+		// org.lwjgl3 is LWJGL 3, newer than every org.lwjgl release
+		if newer.uid != older.uid {
+			if newer.uid != "org.lwjgl3" {
+				t.Errorf("%s should come after %s", older.uid, newer.uid)
+			}
+			continue
+		}
+		newerVersion, _, _ := strings.Cut(newer.version, "-")
+		olderVersion, _, _ := strings.Cut(older.version, "-")
+		if utils.CompareMinecraftVersions(newerVersion, olderVersion) <= 0 {
+			t.Errorf("LWJGL %s for Minecraft %s should be older than %s for %s",
+				older.version, older.minecraft, newer.version, newer.minecraft)
+		}
+	}
+}
+
+func TestPrismComponents(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions func(*packwiz.PackToml)
+		expected string
+	}{
+		{"neoforge", func(p *packwiz.PackToml) { p.Versions.NeoForge = "21.1.77" }, "org.lwjgl3,net.minecraft,net.neoforged"},
+		{"forge", func(p *packwiz.PackToml) { p.Versions.Forge = "52.0.1" }, "org.lwjgl3,net.minecraft,net.minecraftforge"},
+		{"fabric", func(p *packwiz.PackToml) { p.Versions.Fabric = "0.16.5" }, "org.lwjgl3,net.minecraft,net.fabricmc.intermediary,net.fabricmc.fabric-loader"},
+		{"quilt", func(p *packwiz.PackToml) { p.Versions.Quilt = "0.26.4" }, "org.lwjgl3,net.minecraft,net.fabricmc.intermediary,org.quiltmc.quilt-loader"},
+		{"vanilla", func(p *packwiz.PackToml) {}, "org.lwjgl3,net.minecraft"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packToml := &packwiz.PackToml{}
+			packToml.Versions.Minecraft = "1.21.1"
+			tt.versions(packToml)
+
+			var uids []string
+			for _, component := range prismComponents(packToml) {
+				uids = append(uids, component.UID)
+				if component.UID == "net.fabricmc.intermediary" && component.Version != "1.21.1" {
+					t.Errorf("Expected intermediary for 1.21.1, got %s", component.Version)
+				}
+			}
+			if got := strings.Join(uids, ","); got != tt.expected {
+				t.Errorf("Expected components %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPrismInstanceConfig(t *testing.T) {
+	cfg := prismInstance{
+		Name:      "Cool Pack",
+		IconKey:   "Cool_Pack_icon",
+		MaxMemory: 6144,
+		Java:      21,
+		PreLaunch: `"$INST_JAVA" -jar packwiz-installer-bootstrap.jar https://example.com/pack.toml`,
+	}.String()
+
+	for _, line := range []string{
+		"name=Cool Pack",
+		"MinMemAlloc=1024",
+		"MaxMemAlloc=6144",
+		"# Requires Java 21",
+		"OverrideCommands=true",
+		`PreLaunchCommand="$INST_JAVA" -jar packwiz-installer-bootstrap.jar https://example.com/pack.toml`,
+	} {
+		if !strings.Contains(cfg, line+"\n") {
+			t.Errorf("Expected instance.cfg to contain %q:\n%s", line, cfg)
+		}
+	}
+
+	// Offline instances have no pre-launch command
+	offline := prismInstance{Name: "Cool Pack", MinMemory: 8192, MaxMemory: 4096}.String()
+	if strings.Contains(offline, "PreLaunchCommand") {
+		t.Error("Expected no pre-launch command without a bootstrap")
+	}
+	if !strings.Contains(offline, "MinMemAlloc=4096\n") {
+		t.Errorf("Expected the minimum memory to be capped at the maximum:\n%s", offline)
+	}
+}
+
+func TestCreateMMCPackNeoForge(t *testing.T) {
+	packToml := &packwiz.PackToml{}
+	packToml.Versions.Minecraft = "1.21.1"
+	packToml.Versions.NeoForge = "21.1.77"
+
+	dir := t.TempDir()
+	if err := createMMCPack(dir, packToml); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "mmc-pack.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var pack MultiMCPack
+	if err := json.Unmarshal(data, &pack); err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, component := range pack.Components {
+		found = found || (component.UID == "net.neoforged" && component.Version == "21.1.77")
+	}
+	if !found {
+		t.Errorf("Expected a net.neoforged component, got %+v", pack.Components)
+	}
+}
@@ -0,0 +1,190 @@
+package build
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
+)
+
+// zipEpoch is the earliest time a zip entry can record, used when no build time is known
+var zipEpoch = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// SourceDateEpoch returns the timestamp written into build artifacts: the
+// SOURCE_DATE_EPOCH environment variable, then the time of the last git commit
+// in packLocation, then 1980-01-01. The same commit therefore always produces
+// byte-identical archives.
+func SourceDateEpoch(packLocation string) time.Time {
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		if seconds, err := strconv.ParseInt(strings.TrimSpace(epoch), 10, 64); err == nil {
+			return clampZipTime(time.Unix(seconds, 0))
+		}
+		fmt.Printf("Warning: Ignoring invalid SOURCE_DATE_EPOCH %q\n", epoch)
+	}
+
+	if packLocation != "" {
+		if commitTime, err := utils.GitCommitTime(packLocation); err == nil {
+			return clampZipTime(commitTime)
+		}
+	}
+
+	return zipEpoch
+}
+
+// clampZipTime converts t to UTC, raising it to the earliest time zip supports
+func clampZipTime(t time.Time) time.Time {
+	if t.Before(zipEpoch) {
+		return zipEpoch
+	}
+	return t.UTC()
+}
+
+// zipEntry is a file or directory waiting to be written to an archive
+type zipEntry struct {
+	source string // path on disk
+	name   string // slash separated path inside the archive
+	info   os.FileInfo
+}
+
+// writeZip writes entries sorted by name, with fixed timestamps and permissions
+func writeZip(zipPath string, entries []zipEntry, modTime time.Time) error {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+
+	zipFile, err := os.Create(zipPath)
+	if err != nil {
+		return fmt.Errorf("failed to create zip file: %w", err)
+	}
+	defer zipFile.Close()
+
+	zipWriter := zip.NewWriter(zipFile)
+	for _, entry := range entries {
+		if err := addZipEntry(zipWriter, entry, modTime); err != nil {
+			zipWriter.Close()
+			return fmt.Errorf("failed to add %s: %w", entry.name, err)
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to finish zip file: %w", err)
+	}
+	return zipFile.Close()
+}
+
+// addZipEntry adds a single entry to the archive
+func addZipEntry(zipWriter *zip.Writer, entry zipEntry, modTime time.Time) error {
+	if entry.info.IsDir() {
+		_, err := zipWriter.CreateHeader(zipHeader(entry.name+"/", true, false, modTime))
+		return err
+	}
+
+	file, err := os.Open(entry.source)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	executable := entry.info.Mode()&0111 != 0 || strings.HasSuffix(entry.name, ".sh")
+	writer, err := zipWriter.CreateHeader(zipHeader(entry.name, false, executable, modTime))
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(writer, file)
+	return err
+}
+
+// zipHeader returns a header with normalized permissions: 0755 for directories
+// and executables, 0644 for everything else
+func zipHeader(name string, isDir, executable bool, modTime time.Time) *zip.FileHeader {
+	header := &zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modTime,
+	}
+
+	switch {
+	case isDir:
+		header.Method = zip.Store
+		header.SetMode(os.ModeDir | 0755)
+	case executable:
+		header.SetMode(0755)
+	default:
+		header.SetMode(0644)
+	}
+	return header
+}
+
+// NormalizeZip rewrites an archive produced by another tool, such as packwiz's
+// CurseForge and Modrinth exports, with sorted entries, modTime timestamps and
+// normalized permissions
+func NormalizeZip(zipPath string, modTime time.Time) error {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", filepath.Base(zipPath), err)
+	}
+	defer reader.Close()
+
+	files := make([]*zip.File, len(reader.File))
+	copy(files, reader.File)
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+
+	tempFile, err := os.CreateTemp(filepath.Dir(zipPath), ".normalize-*.zip")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tempPath := tempFile.Name()
+	defer os.Remove(tempPath)
+
+	zipWriter := zip.NewWriter(tempFile)
+	for _, file := range files {
+		if err := copyZipEntry(zipWriter, file, modTime); err != nil {
+			zipWriter.Close()
+			tempFile.Close()
+			return fmt.Errorf("failed to copy %s: %w", file.Name, err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("failed to finish zip file: %w", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+
+	// Windows cannot replace a file that is still open
+	reader.Close()
+	if err := os.Rename(tempPath, zipPath); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", filepath.Base(zipPath), err)
+	}
+	return nil
+}
+
+// copyZipEntry recompresses an entry from another archive with a normalized header
+func copyZipEntry(zipWriter *zip.Writer, file *zip.File, modTime time.Time) error {
+	isDir := strings.HasSuffix(file.Name, "/")
+	executable := file.Mode()&0111 != 0 || strings.HasSuffix(file.Name, ".sh")
+	writer, err := zipWriter.CreateHeader(zipHeader(file.Name, isDir, executable, modTime))
+	if err != nil || isDir {
+		return err
+	}
+
+	content, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	_, err = io.Copy(writer, content)
+	return err
+}
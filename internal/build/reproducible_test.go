@@ -0,0 +1,180 @@
+package build
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateZipFromDirIsReproducible(t *testing.T) {
+	srcDir := t.TempDir()
+	files := map[string]string{
+		"b.txt":        "second",
+		"a/nested.txt": "nested",
+		"start.sh":     "#!/bin/sh\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(srcDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	modTime := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	first := filepath.Join(t.TempDir(), "first.zip")
+	if err := CreateZipFromDirAt(srcDir, first, modTime); err != nil {
+		t.Fatalf("CreateZipFromDirAt failed: %v", err)
+	}
+
+	// Touch a file so the mtimes on disk differ between the builds
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(srcDir, "b.txt"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	second := filepath.Join(t.TempDir(), "second.zip")
+	if err := CreateZipFromDirAt(srcDir, second, modTime); err != nil {
+		t.Fatalf("CreateZipFromDirAt failed: %v", err)
+	}
+
+	firstData, _ := os.ReadFile(first)
+	secondData, _ := os.ReadFile(second)
+	if !bytes.Equal(firstData, secondData) {
+		t.Fatal("Expected identical archives for identical content")
+	}
+
+	reader, err := zip.OpenReader(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	var names []string
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+		if !file.Modified.Equal(modTime) {
+			t.Errorf("%s: expected modified %v, got %v", file.Name, modTime, file.Modified)
+		}
+
+		expected := os.FileMode(0644)
+		switch {
+		case strings.HasSuffix(file.Name, "/"):
+			expected = os.ModeDir | 0755
+		case file.Name == "start.sh":
+			expected = 0755
+		}
+		if file.Mode() != expected {
+			t.Errorf("%s: expected mode %v, got %v", file.Name, expected, file.Mode())
+		}
+	}
+	if got := strings.Join(names, ","); got != "a/,a/nested.txt,b.txt,start.sh" {
+		t.Errorf("Unexpected entry order: %s", got)
+	}
+}
+
+func TestNormalizeZip(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "pack.zip")
+	file, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer := zip.NewWriter(file)
+	for _, name := range []string{"z.txt", "a.txt"} {
+		entry, err := writer.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry.Write([]byte(name))
+	}
+	writer.Close()
+	file.Close()
+
+	modTime := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
+	if err := NormalizeZip(zipPath, modTime); err != nil {
+		t.Fatalf("NormalizeZip failed: %v", err)
+	}
+
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	if len(reader.File) != 2 || reader.File[0].Name != "a.txt" || reader.File[1].Name != "z.txt" {
+		t.Fatalf("Expected sorted entries, got %d entries", len(reader.File))
+	}
+	for _, file := range reader.File {
+		if !file.Modified.Equal(modTime) {
+			t.Errorf("%s: expected modified %v, got %v", file.Name, modTime, file.Modified)
+		}
+	}
+}
+
+func TestSourceDateEpoch(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	if got := SourceDateEpoch(""); !got.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Expected SOURCE_DATE_EPOCH, got %v", got)
+	}
+
+	t.Setenv("SOURCE_DATE_EPOCH", "0")
+	if got := SourceDateEpoch(""); !got.Equal(zipEpoch) {
+		t.Errorf("Expected times before 1980 to be clamped, got %v", got)
+	}
+
+	t.Setenv("SOURCE_DATE_EPOCH", "")
+	if got := SourceDateEpoch(t.TempDir()); !got.Equal(zipEpoch) {
+		t.Errorf("Expected the zip epoch outside a git repository, got %v", got)
+	}
+}
+
+func TestRecordArtifact(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	packDir := writeTestPack(t, "name = \"Cool Pack\"\nversion = \"1.0\"\n", "")
+	buildDir := filepath.Join(packDir, ".build")
+	if err := os.MkdirAll(buildDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"pack-server.zip", "pack-multimc.zip"} {
+		if err := os.WriteFile(filepath.Join(buildDir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	options := Options{}
+	if err := options.recordArtifact(packDir, packDir, FormatServer, filepath.Join(buildDir, "pack-server.zip")); err != nil {
+		t.Fatalf("recordArtifact failed: %v", err)
+	}
+	if err := options.recordArtifact(packDir, packDir, FormatMultiMC, filepath.Join(buildDir, "pack-multimc.zip")); err != nil {
+		t.Fatalf("recordArtifact failed: %v", err)
+	}
+
+	manifest, err := ReadManifest(buildDir)
+	if err != nil {
+		t.Fatalf("ReadManifest failed: %v", err)
+	}
+	if manifest.Pack != "Cool Pack" || manifest.Version != "1.0" || manifest.BuildTime.Unix() != 1700000000 {
+		t.Errorf("Unexpected manifest metadata: %+v", manifest)
+	}
+	if len(manifest.Artifacts) != 2 {
+		t.Fatalf("Expected both artifacts in the manifest, got %+v", manifest.Artifacts)
+	}
+	multimc := manifest.Artifacts[0]
+	if multimc.Format != FormatMultiMC || multimc.Path != "pack-multimc.zip" || multimc.Size != int64(len("pack-multimc.zip")) {
+		t.Errorf("Unexpected artifact: %+v", multimc)
+	}
+
+	sums, err := os.ReadFile(filepath.Join(buildDir, ChecksumsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := multimc.SHA256 + "  pack-multimc.zip\n" + manifest.Artifacts[1].SHA256 + "  pack-server.zip\n"
+	if string(sums) != expected {
+		t.Errorf("Unexpected %s:\n%s", ChecksumsFile, sums)
+	}
+}
@@ -3,22 +3,20 @@ package build
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
+	"github.com/Merith-TK/packwiz-wrapper/internal/config"
 	"github.com/Merith-TK/packwiz-wrapper/internal/packwiz"
 	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
 )
 
 // ExportServer exports the pack as a server pack
-func ExportServer(packDir, packName string) error {
-	fmt.Println("🖥️  Exporting Server pack...")
-
+func ExportServer(packDir string, options Options) (string, error) {
 	// Find pack.toml location
 	packLocation := utils.FindPackToml(packDir)
 	if packLocation == "" {
-		return fmt.Errorf("pack.toml not found")
+		return "", fmt.Errorf("pack.toml not found")
 	}
 
 	// Create temporary server directory
@@ -26,12 +24,16 @@ func ExportServer(packDir, packName string) error {
 	defer os.RemoveAll(serverDir)
 
 	if err := os.MkdirAll(serverDir, 0755); err != nil {
-		return fmt.Errorf("failed to create .server directory: %w", err)
+		return "", fmt.Errorf("failed to create .server directory: %w", err)
 	}
 
 	// Copy server-relevant files
-	if err := copyServerFiles(packLocation, serverDir); err != nil {
-		return fmt.Errorf("failed to copy server files: %w", err)
+	filter, err := options.exportFilter(packDir, packLocation, FormatServer)
+	if err != nil {
+		return "", err
+	}
+	if err := copyPackFiles(packLocation, serverDir, filter); err != nil {
+		return "", fmt.Errorf("failed to copy server files: %w", err)
 	}
 
 	// Create server icon
@@ -39,96 +41,34 @@ func ExportServer(packDir, packName string) error {
 		fmt.Printf("Warning: Failed to create server icon: %v\n", err)
 	}
 
-	// Install server-side mods using packwiz installer
+	// Install server-side mods
 	if err := installServerMods(serverDir, packLocation); err != nil {
-		return fmt.Errorf("failed to install server mods: %w", err)
+		return "", fmt.Errorf("failed to install server mods: %w", err)
 	}
 
 	// Create server startup scripts
-	if err := createServerScripts(serverDir); err != nil {
-		return fmt.Errorf("failed to create server scripts: %w", err)
-	}
-
-	// Create the zip file
-	buildDir := filepath.Join(packDir, ".build")
-	if err := os.MkdirAll(buildDir, 0755); err != nil {
-		return fmt.Errorf("failed to create .build directory: %w", err)
-	}
-
-	zipPath := filepath.Join(buildDir, packName+"-server.zip")
-	if err := CreateZipFromDir(serverDir, zipPath); err != nil {
-		return fmt.Errorf("failed to create zip file: %w", err)
+	packToml, err := readPackToml(packLocation)
+	if err != nil {
+		return "", fmt.Errorf("failed to read pack.toml: %w", err)
 	}
-
-	fmt.Printf("✅ Created Server pack: .build/%s-server.zip\n", packName)
-	return nil
-}
-
-// copyServerFiles copies server-relevant files from pack location
-func copyServerFiles(packLocation, serverDir string) error {
-	return filepath.Walk(packLocation, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Get relative path
-		relPath, err := filepath.Rel(packLocation, path)
-		if err != nil {
-			return err
-		}
-
-		// Skip client-only files and directories
-		if shouldSkipServerFile(relPath) {
-			if info.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		destPath := filepath.Join(serverDir, relPath)
-
-		if info.IsDir() {
-			return os.MkdirAll(destPath, info.Mode())
-		} else {
-			return copyFile(path, destPath)
-		}
-	})
-}
-
-// shouldSkipServerFile determines if a file should be skipped for server export
-func shouldSkipServerFile(relPath string) bool {
-	skipPatterns := []string{
-		".build",
-		".git",
-		".temp",
-		"resourcepacks", // Client-only
-		"shaderpacks",   // Client-only
-		"screenshots",   // Client-only
-		"logs",          // Runtime generated
-		"crash-reports", // Runtime generated
-		"saves",         // Client-only
-		"options.txt",   // Client-only
-		"optionsof.txt", // OptiFine client settings
-		// Note: mods directory and .pw.toml files are needed by packwiz installer
+	cfg, err := config.Load(packLocation)
+	if err != nil {
+		return "", err
 	}
-
-	// Allow mod metadata files (needed by packwiz installer)
-	if strings.HasSuffix(relPath, ".pw.toml") {
-		return false
+	if err := createServerScripts(serverDir, packToml, cfg.Server); err != nil {
+		return "", fmt.Errorf("failed to create server scripts: %w", err)
 	}
 
-	// Skip actual mod jar files (will be downloaded by installer with server filtering)
-	if strings.HasPrefix(relPath, "mods"+string(filepath.Separator)) && strings.HasSuffix(relPath, ".jar") {
-		return true
+	// Create the zip file
+	zipPath, err := options.artifactPath(packDir, packLocation, FormatServer)
+	if err != nil {
+		return "", err
 	}
-
-	for _, pattern := range skipPatterns {
-		if strings.HasPrefix(relPath, pattern) {
-			return true
-		}
+	if err := CreateZipFromDirAt(serverDir, zipPath, SourceDateEpoch(packLocation)); err != nil {
+		return "", fmt.Errorf("failed to create zip file: %w", err)
 	}
 
-	return false
+	return zipPath, nil
 }
 
 // createServerIcon creates a server-icon.png from the pack icon
@@ -142,87 +82,137 @@ func createServerIcon(packLocation, serverDir string) error {
 	return copyFile(iconPath, destPath)
 }
 
-// installServerMods uses packwiz installer to download server-side mods
+// installServerMods downloads the server-side mods with the native installer
 func installServerMods(serverDir, packLocation string) error {
 	fmt.Println("📦 Installing server-side mods...")
 
-	// Find packwiz installer
-	installerPath := filepath.Join(packLocation, "packwiz-installer-bootstrap.jar")
-	if _, err := os.Stat(installerPath); os.IsNotExist(err) {
-		// Try to download it
-		fmt.Println("⬇️  Downloading packwiz installer...")
-		if err := DownloadPackwizInstaller(installerPath); err != nil {
-			return fmt.Errorf("failed to download packwiz installer: %w", err)
-		}
+	installer := &Installer{Side: SideServer, SkipFiles: true, SkipManifest: true, Cache: utils.DefaultCache()}
+	result, err := installer.Install(packLocation, serverDir)
+	if err != nil {
+		return err
 	}
 
-	// Copy pack.toml to server directory
-	packTomlSrc := filepath.Join(packLocation, "pack.toml")
-	packTomlDest := filepath.Join(serverDir, "pack.toml")
-	if err := copyFile(packTomlSrc, packTomlDest); err != nil {
-		return fmt.Errorf("failed to copy pack.toml: %w", err)
-	}
+	fmt.Printf("✅ Server mods installed successfully (%d downloaded, %d cached, %d up to date)\n", result.Downloaded, result.Cached, result.Skipped)
+	return nil
+}
 
-	// Copy index.toml to server directory
-	indexTomlSrc := filepath.Join(packLocation, "index.toml")
-	indexTomlDest := filepath.Join(serverDir, "index.toml")
-	if err := copyFile(indexTomlSrc, indexTomlDest); err != nil {
-		return fmt.Errorf("failed to copy index.toml: %w", err)
-	}
+// serverLaunch is how an exported server starts. The export only holds the pack,
+// so the scripts check for the file the loader installer creates first.
+type serverLaunch struct {
+	unixArgs []string // java arguments after the JVM flags on Linux and macOS
+	winArgs  []string // java arguments after the JVM flags on Windows
+	required string   // slash separated file the server cannot start without
+	missing  []string // instructions printed when it is missing
+}
 
-	// Copy installer to server directory
-	serverInstallerPath := filepath.Join(serverDir, "packwiz-installer-bootstrap.jar")
-	if err := copyFile(installerPath, serverInstallerPath); err != nil {
-		return fmt.Errorf("failed to copy installer: %w", err)
+// exportServerLaunch returns the launch of the pack's loader, matching the
+// layouts core.ServerLaunchArgs detects in a deployed server
+func exportServerLaunch(packToml *packwiz.PackToml) serverLaunch {
+	mcVersion := packwiz.MinecraftVersion(packToml)
+	loader, version := packwiz.ModLoader(packToml)
+
+	switch loader {
+	case packwiz.LoaderNeoForge:
+		// The 1.20.1 releases were published as a Forge fork
+		libraries := "libraries/net/neoforged/neoforge/" + version
+		if mcVersion == "1.20.1" {
+			libraries = "libraries/net/neoforged/forge/1.20.1-" + strings.TrimPrefix(version, "1.20.1-")
+		}
+		return argsFileLaunch(libraries, installerMissing("NeoForge", version, "https://neoforged.net"))
+
+	case packwiz.LoaderForge:
+		version = mcVersion + "-" + strings.TrimPrefix(version, mcVersion+"-")
+		missing := installerMissing("Forge", version, "https://files.minecraftforge.net")
+		// Forge only switched to argument files in 1.17
+		if utils.CompareMinecraftVersions(mcVersion, "1.17") < 0 {
+			return jarLaunch("forge-"+version+".jar", missing...)
+		}
+		return argsFileLaunch("libraries/net/minecraftforge/forge/"+version, missing)
+
+	case packwiz.LoaderQuilt:
+		return jarLaunch("quilt-server-launch.jar",
+			fmt.Sprintf("Quilt %s is not installed yet. Download the installer from https://quiltmc.org/install/server", version),
+			fmt.Sprintf("and run it in this directory with: java -jar <installer>.jar install server %s %s --download-server --install-dir=.", mcVersion, version))
+
+	case packwiz.LoaderFabric:
+		return jarLaunch("server.jar",
+			"server.jar is missing. Download the Fabric server launcher and save it as server.jar:",
+			fmt.Sprintf("https://meta.fabricmc.net/v2/versions/loader/%s/%s/1.1.0/server/jar", mcVersion, version))
+
+	default:
+		return jarLaunch("server.jar",
+			fmt.Sprintf("server.jar is missing. Download the Minecraft %s server and save it as server.jar:", mcVersion),
+			"https://www.minecraft.net/download/server")
 	}
+}
 
-	// Find compatible Java for the pack
-	packToml, _, err := utils.LoadPackConfig(packLocation)
-	if err != nil {
-		return fmt.Errorf("failed to load pack config: %w", err)
-	}
+// jarLaunch starts the server from an executable jar
+func jarLaunch(jar string, missing ...string) serverLaunch {
+	args := []string{"-jar", jar}
+	return serverLaunch{unixArgs: args, winArgs: args, required: jar, missing: missing}
+}
 
-	mcVersion := getMinecraftVersionServer(packToml)
-	java, err := utils.FindCompatibleJava(mcVersion)
-	if err != nil {
-		return fmt.Errorf("no compatible Java found for Minecraft %s: %w", mcVersion, err)
+// argsFileLaunch starts the server from the argument files the Forge and
+// NeoForge installers write to a libraries directory
+func argsFileLaunch(libraries string, missing []string) serverLaunch {
+	return serverLaunch{
+		unixArgs: []string{"@user_jvm_args.txt", "@" + libraries + "/unix_args.txt"},
+		winArgs:  []string{"@user_jvm_args.txt", "@" + libraries + "/win_args.txt"},
+		required: libraries + "/unix_args.txt",
+		missing:  missing,
 	}
+}
 
-	fmt.Printf("Using Java %s for mod installation\n", java.Version)
-
-	// Run packwiz installer with server flag and no-gui mode
-	cmd := exec.Command(java.Path, "-jar", "packwiz-installer-bootstrap.jar", "pack.toml", "-s", "server", "-g")
-	cmd.Dir = serverDir
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	// Don't attach stdin to prevent hanging on input prompts
-	cmd.Stdin = nil
-
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("packwiz installer failed: %w", err)
+// installerMissing tells how to install a loader whose installer sets up the server
+func installerMissing(loaderName, version, site string) []string {
+	return []string{
+		fmt.Sprintf("%s %s is not installed yet. Download its installer from %s", loaderName, version, site),
+		"and run it in this directory with: java -jar <installer>.jar --installServer",
 	}
-
-	fmt.Println("✅ Server mods installed successfully")
-	return nil
 }
 
-// createServerScripts creates startup scripts for the server
-func createServerScripts(serverDir string) error {
+// createServerScripts creates the startup scripts, eula.txt and server.properties
+// from the pack's loader and the [server] section of packwrap.toml
+func createServerScripts(serverDir string, packToml *packwiz.PackToml, serverCfg config.Server) error {
+	launch := exportServerLaunch(packToml)
+	javaArgs := serverCfg.JavaArgs()
+
 	// Create start.bat for Windows
-	batScript := `@echo off
-title Minecraft Server
-echo Starting Minecraft Server...
-java -Xmx4G -Xms1G -jar server.jar nogui
-pause`
+	var batArgs []string
+	for _, arg := range append(append(append([]string{}, javaArgs...), launch.winArgs...), "nogui") {
+		batArgs = append(batArgs, batQuote(arg))
+	}
+	batScript := "@echo off\r\n" +
+		"title Minecraft Server\r\n" +
+		"if exist " + batQuote(strings.ReplaceAll(launch.required, "/", `\`)) + " goto start\r\n"
+	for _, line := range launch.missing {
+		batScript += "echo " + batEcho(line) + "\r\n"
+	}
+	batScript += "pause\r\n" +
+		"exit /b 1\r\n" +
+		":start\r\n" +
+		"echo Starting Minecraft Server...\r\n" +
+		"java " + strings.Join(batArgs, " ") + "\r\n" +
+		"pause\r\n"
 
 	if err := os.WriteFile(filepath.Join(serverDir, "start.bat"), []byte(batScript), 0644); err != nil {
 		return fmt.Errorf("failed to create start.bat: %w", err)
 	}
 
 	// Create start.sh for Unix systems
-	shScript := `#!/bin/bash
-echo "Starting Minecraft Server..."
-java -Xmx4G -Xms1G -jar server.jar nogui`
+	var shArgs []string
+	for _, arg := range append(append(append([]string{}, javaArgs...), launch.unixArgs...), "nogui") {
+		shArgs = append(shArgs, shellQuote(arg))
+	}
+	shScript := "#!/bin/bash\n" +
+		"if [ ! -f " + shellQuote(launch.required) + " ]; then\n"
+	for _, line := range launch.missing {
+		shScript += "\techo " + shellQuote(line) + "\n"
+	}
+	shScript += "\texit 1\n" +
+		"fi\n" +
+		"echo \"Starting Minecraft Server...\"\n" +
+		"java " + strings.Join(shArgs, " ") + "\n"
 
 	if err := os.WriteFile(filepath.Join(serverDir, "start.sh"), []byte(shScript), 0755); err != nil {
 		return fmt.Errorf("failed to create start.sh: %w", err)
@@ -237,7 +227,7 @@ eula=false`
 		return fmt.Errorf("failed to create eula.txt: %w", err)
 	}
 
-	// Create server.properties with basic settings
+	// Create server.properties with basic settings, then apply the config
 	serverProps := `# Minecraft server properties
 server-port=25565
 gamemode=survival
@@ -247,20 +237,38 @@ motd=A Minecraft Server
 online-mode=true
 spawn-protection=16
 level-name=world
-level-type=minecraft\:normal`
+level-type=minecraft\:normal
+`
 
-	if err := os.WriteFile(filepath.Join(serverDir, "server.properties"), []byte(serverProps), 0644); err != nil {
+	propsPath := filepath.Join(serverDir, "server.properties")
+	if err := os.WriteFile(propsPath, []byte(serverProps), 0644); err != nil {
+		return fmt.Errorf("failed to create server.properties: %w", err)
+	}
+	if err := utils.UpdateProperties(propsPath, serverCfg.ServerProperties()); err != nil {
 		return fmt.Errorf("failed to create server.properties: %w", err)
 	}
 
 	return nil
 }
 
-// getMinecraftVersionServer gets the Minecraft version from pack.toml
-func getMinecraftVersionServer(packToml *packwiz.PackToml) string {
-	// Prefer versions.minecraft, fallback to mc-version
-	if packToml.Versions.Minecraft != "" {
-		return packToml.Versions.Minecraft
+// shellQuote quotes an argument for a POSIX shell when it needs it
+func shellQuote(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\n'\"\\$`&|;<>()*?[]{}~#!") {
+		return arg
 	}
-	return packToml.McVersion
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// batQuote quotes an argument for cmd.exe when it needs it
+func batQuote(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\"&|<>^%") {
+		return arg
+	}
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(arg, `"`, `""`), "%", "%%") + `"`
+}
+
+// batEcho escapes a line for cmd.exe's echo
+func batEcho(line string) string {
+	replacer := strings.NewReplacer("^", "^^", "&", "^&", "|", "^|", "<", "^<", ">", "^>", "%", "%%")
+	return replacer.Replace(line)
 }
@@ -0,0 +1,175 @@
+package build
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/config"
+	"github.com/Merith-TK/packwiz-wrapper/internal/packwiz"
+)
+
+func TestCreateServerScripts(t *testing.T) {
+	serverDir := t.TempDir()
+	serverCfg := config.Server{
+		MaxMemory: "6G",
+		JVMArgs:   []string{"-Dmotd=Hello World"},
+		Port:      25570,
+	}
+	packToml := &packwiz.PackToml{}
+	packToml.Versions.Minecraft = "1.21.1"
+	if err := createServerScripts(serverDir, packToml, serverCfg); err != nil {
+		t.Fatal(err)
+	}
+
+	sh, err := os.ReadFile(filepath.Join(serverDir, "start.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(sh), "java -Xms1G -Xmx6G '-Dmotd=Hello World' -jar server.jar nogui\n") {
+		t.Errorf("Unexpected start.sh:\n%s", sh)
+	}
+
+	bat, err := os.ReadFile(filepath.Join(serverDir, "start.bat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(bat), "java -Xms1G -Xmx6G \"-Dmotd=Hello World\" -jar server.jar nogui\r\n") {
+		t.Errorf("Unexpected start.bat:\n%s", bat)
+	}
+
+	properties, err := os.ReadFile(filepath.Join(serverDir, "server.properties"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(properties), "server-port=25570\n") || strings.Contains(string(properties), "server-port=25565") {
+		t.Errorf("Expected the configured port, got:\n%s", properties)
+	}
+}
+
+func TestExportServerLaunch(t *testing.T) {
+	tests := []struct {
+		name      string
+		minecraft string
+		versions  func(*packwiz.PackToml)
+		unixArgs  string
+		winArgs   string
+		required  string
+	}{
+		{"neoforge", "1.21.1", func(p *packwiz.PackToml) { p.Versions.NeoForge = "21.1.77" },
+			"@user_jvm_args.txt @libraries/net/neoforged/neoforge/21.1.77/unix_args.txt",
+			"@user_jvm_args.txt @libraries/net/neoforged/neoforge/21.1.77/win_args.txt",
+			"libraries/net/neoforged/neoforge/21.1.77/unix_args.txt"},
+		{"neoforge 1.20.1", "1.20.1", func(p *packwiz.PackToml) { p.Versions.NeoForge = "47.1.106" },
+			"@user_jvm_args.txt @libraries/net/neoforged/forge/1.20.1-47.1.106/unix_args.txt",
+			"@user_jvm_args.txt @libraries/net/neoforged/forge/1.20.1-47.1.106/win_args.txt",
+			"libraries/net/neoforged/forge/1.20.1-47.1.106/unix_args.txt"},
+		{"forge", "1.20.1", func(p *packwiz.PackToml) { p.Versions.Forge = "47.2.0" },
+			"@user_jvm_args.txt @libraries/net/minecraftforge/forge/1.20.1-47.2.0/unix_args.txt",
+			"@user_jvm_args.txt @libraries/net/minecraftforge/forge/1.20.1-47.2.0/win_args.txt",
+			"libraries/net/minecraftforge/forge/1.20.1-47.2.0/unix_args.txt"},
+		{"forge 1.12.2", "1.12.2", func(p *packwiz.PackToml) { p.Versions.Forge = "14.23.5.2860" },
+			"-jar forge-1.12.2-14.23.5.2860.jar", "-jar forge-1.12.2-14.23.5.2860.jar", "forge-1.12.2-14.23.5.2860.jar"},
+		{"quilt", "1.21.1", func(p *packwiz.PackToml) { p.Versions.Quilt = "0.26.4" },
+			"-jar quilt-server-launch.jar", "-jar quilt-server-launch.jar", "quilt-server-launch.jar"},
+		{"fabric", "1.21.1", func(p *packwiz.PackToml) { p.Versions.Fabric = "0.16.5" },
+			"-jar server.jar", "-jar server.jar", "server.jar"},
+		{"vanilla", "1.21.1", func(p *packwiz.PackToml) {},
+			"-jar server.jar", "-jar server.jar", "server.jar"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packToml := &packwiz.PackToml{}
+			packToml.Versions.Minecraft = tt.minecraft
+			tt.versions(packToml)
+
+			launch := exportServerLaunch(packToml)
+			if got := strings.Join(launch.unixArgs, " "); got != tt.unixArgs {
+				t.Errorf("Expected unix arguments %s, got %s", tt.unixArgs, got)
+			}
+			if got := strings.Join(launch.winArgs, " "); got != tt.winArgs {
+				t.Errorf("Expected Windows arguments %s, got %s", tt.winArgs, got)
+			}
+			if launch.required != tt.required || len(launch.missing) == 0 {
+				t.Errorf("Expected %s to be required with instructions, got %+v", tt.required, launch)
+			}
+		})
+	}
+}
+
+// TestServerScriptsCheckLoader runs start.sh of a NeoForge export before and
+// after the loader is installed
+func TestServerScriptsCheckLoader(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("start.sh needs a POSIX shell")
+	}
+
+	serverDir := t.TempDir()
+	packToml := &packwiz.PackToml{}
+	packToml.Versions.Minecraft = "1.21.1"
+	packToml.Versions.NeoForge = "21.1.77"
+	if err := createServerScripts(serverDir, packToml, config.Server{}); err != nil {
+		t.Fatal(err)
+	}
+
+	bat, err := os.ReadFile(filepath.Join(serverDir, "start.bat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(bat), "if exist libraries\\net\\neoforged\\neoforge\\21.1.77\\unix_args.txt goto start\r\n") {
+		t.Errorf("Expected start.bat to check for the loader:\n%s", bat)
+	}
+
+	// A stand-in java prints its arguments
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "java"), []byte("#!/bin/sh\necho \"java $*\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	run := func() (string, error) {
+		cmd := exec.Command("bash", "start.sh")
+		cmd.Dir = serverDir
+		cmd.Env = append(os.Environ(), "PATH="+binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+		output, err := cmd.CombinedOutput()
+		return string(output), err
+	}
+
+	output, err := run()
+	if err == nil || !strings.Contains(output, "NeoForge 21.1.77 is not installed yet") {
+		t.Errorf("Expected start.sh to refuse without NeoForge, got %v:\n%s", err, output)
+	}
+
+	argsFile := filepath.Join(serverDir, "libraries", "net", "neoforged", "neoforge", "21.1.77", "unix_args.txt")
+	if err := os.MkdirAll(filepath.Dir(argsFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(argsFile, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	output, err = run()
+	if err != nil || !strings.Contains(output, "java -Xms1G -Xmx4G @user_jvm_args.txt @libraries/net/neoforged/neoforge/21.1.77/unix_args.txt nogui") {
+		t.Errorf("Expected start.sh to launch NeoForge, got %v:\n%s", err, output)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]string{
+		"-Xmx4G":    "-Xmx4G",
+		"a b":       "'a b'",
+		"it's":      `'it'\''s'`,
+		"":          "''",
+		"-Dx=$HOME": "'-Dx=$HOME'",
+		"100%":      "100%",
+	}
+	for arg, expected := range tests {
+		if quoted := shellQuote(arg); quoted != expected {
+			t.Errorf("shellQuote(%q) = %s, expected %s", arg, quoted, expected)
+		}
+	}
+	if quoted := batQuote("100%"); quoted != `"100%%"` {
+		t.Errorf("batQuote(100%%) = %s", quoted)
+	}
+}
@@ -1,7 +1,6 @@
 package build
 
 import (
-	"archive/zip"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,23 +8,20 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
-// CreateZipFromDir creates a zip file from a directory
+// CreateZipFromDir creates a zip file from a directory, timestamped with
+// SourceDateEpoch("")
 func CreateZipFromDir(srcDir, zipPath string) error {
-	// Create the zip file
-	zipFile, err := os.Create(zipPath)
-	if err != nil {
-		return fmt.Errorf("failed to create zip file: %w", err)
-	}
-	defer zipFile.Close()
-
-	// Create a new zip writer
-	zipWriter := zip.NewWriter(zipFile)
-	defer zipWriter.Close()
+	return CreateZipFromDirAt(srcDir, zipPath, SourceDateEpoch(""))
+}
 
-	// Walk through the source directory
-	return filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+// CreateZipFromDirAt creates a reproducible zip file from a directory. Entries
+// are sorted and every entry is stamped with modTime.
+func CreateZipFromDirAt(srcDir, zipPath string, modTime time.Time) error {
+	var entries []zipEntry
+	err := filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -41,52 +37,21 @@ func CreateZipFromDir(srcDir, zipPath string) error {
 			return nil
 		}
 
-		// Convert Windows paths to Unix paths for zip compatibility
-		relPath = filepath.ToSlash(relPath)
-
-		if info.IsDir() {
-			// Create directory entry (with trailing slash)
-			_, err := zipWriter.Create(relPath + "/")
-			return err
-		} else {
-			// Create file entry
-			return addFileToZip(zipWriter, path, relPath)
-		}
+		entries = append(entries, zipEntry{source: path, name: filepath.ToSlash(relPath), info: info})
+		return nil
 	})
-}
-
-// addFileToZip adds a file to the zip archive
-func addFileToZip(zipWriter *zip.Writer, filePath, zipPath string) error {
-	// Open the file to be added
-	file, err := os.Open(filePath)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
-	// Create entry in zip
-	writer, err := zipWriter.Create(zipPath)
-	if err != nil {
-		return err
-	}
-
-	// Copy file contents to zip
-	_, err = io.Copy(writer, file)
-	return err
+	return writeZip(zipPath, entries, modTime)
 }
 
-// CreateZipFromFiles creates a zip file from a list of files with custom paths
+// CreateZipFromFiles creates a zip file from a list of files with custom paths,
+// timestamped with SourceDateEpoch("")
 func CreateZipFromFiles(zipPath string, files map[string]string) error {
 	// files map: local path -> zip path
-	zipFile, err := os.Create(zipPath)
-	if err != nil {
-		return fmt.Errorf("failed to create zip file: %w", err)
-	}
-	defer zipFile.Close()
-
-	zipWriter := zip.NewWriter(zipFile)
-	defer zipWriter.Close()
-
+	var entries []zipEntry
 	for localPath, zipEntryPath := range files {
 		info, err := os.Stat(localPath)
 		if err != nil {
@@ -96,45 +61,35 @@ func CreateZipFromFiles(zipPath string, files map[string]string) error {
 		// Convert to Unix path for zip compatibility
 		zipEntryPath = filepath.ToSlash(zipEntryPath)
 
-		if info.IsDir() {
-			// Add directory recursively
-			err = filepath.Walk(localPath, func(path string, info os.FileInfo, err error) error {
-				if err != nil {
-					return err
-				}
-
-				relPath, err := filepath.Rel(localPath, path)
-				if err != nil {
-					return err
-				}
-
-				fullZipPath := zipEntryPath
-				if relPath != "." {
-					fullZipPath = filepath.ToSlash(filepath.Join(zipEntryPath, relPath))
-				}
-
-				if info.IsDir() {
-					if fullZipPath != zipEntryPath {
-						_, err := zipWriter.Create(fullZipPath + "/")
-						return err
-					}
-					return nil
-				} else {
-					return addFileToZip(zipWriter, path, fullZipPath)
-				}
-			})
+		if !info.IsDir() {
+			entries = append(entries, zipEntry{source: localPath, name: zipEntryPath, info: info})
+			continue
+		}
+
+		// Add directory recursively
+		err = filepath.Walk(localPath, func(path string, info os.FileInfo, err error) error {
 			if err != nil {
 				return err
 			}
-		} else {
-			// Add single file
-			if err := addFileToZip(zipWriter, localPath, zipEntryPath); err != nil {
+
+			relPath, err := filepath.Rel(localPath, path)
+			if err != nil {
 				return err
 			}
+			if relPath == "." {
+				return nil
+			}
+
+			fullZipPath := filepath.ToSlash(filepath.Join(zipEntryPath, relPath))
+			entries = append(entries, zipEntry{source: path, name: fullZipPath, info: info})
+			return nil
+		})
+		if err != nil {
+			return err
 		}
 	}
 
-	return nil
+	return writeZip(zipPath, entries, SourceDateEpoch(""))
 }
 
 // GetPackNameFromDir extracts a reasonable pack name from the directory path
@@ -1,14 +1,16 @@
 package commands
 
 import (
+	"context"
 	"fmt"
-	"io/ioutil"
 	"os"
-	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
 )
 
 // CmdBatch provides batch operations across multiple pack directories
@@ -19,13 +21,18 @@ func CmdBatch() (names []string, shortHelp, longHelp string, execute func([]stri
   pw batch <command>           - Run command in all subdirectories with pack.toml
   pw batch --all <command>     - Run command in ALL subdirectories
   pw batch -r <command>        - Run command and refresh packs after
+  pw batch -j <n> <command>    - Process up to n packs in parallel (default: CPU count)
   pw batch --all -r <command>  - Run in all dirs and refresh packs
 
+Each pack's output is buffered and printed as a block once it finishes.
+Commands run non-interactively, so pass -y where a command would prompt.
+Press Ctrl+C to cancel: running commands are stopped and remaining packs skipped.
+
 Examples:
   pw batch modlist             - Generate modlists for all packs
   pw batch --all arb ls        - Run 'pw arb ls' in ALL subdirectories
-  pw batch build cf            - Build CurseForge exports for all packs
-  pw batch -r import -i mods.txt - Import mods and refresh all packs`,
+  pw batch -j 4 build cf       - Build CurseForge exports, 4 packs at a time
+  pw batch -r import -y -i mods.txt - Import mods and refresh all packs`,
 		func(args []string) error {
 			if len(args) == 0 {
 				return fmt.Errorf("no command specified for batch operation")
@@ -33,6 +40,7 @@ Examples:
 
 			refresh := false
 			skipPackCheck := false
+			jobs := 0
 			commandArgs := args
 
 			// Parse flags
@@ -40,11 +48,27 @@ Examples:
 				flag := commandArgs[0]
 				commandArgs = commandArgs[1:]
 
-				switch flag {
-				case "-r":
+				switch {
+				case flag == "-r":
 					refresh = true
-				case "--all":
+				case flag == "--all":
 					skipPackCheck = true
+				case flag == "-j" || flag == "--jobs":
+					if len(commandArgs) == 0 {
+						return fmt.Errorf("%s requires a number", flag)
+					}
+					n, err := strconv.Atoi(commandArgs[0])
+					if err != nil || n < 1 {
+						return fmt.Errorf("invalid job count: %s", commandArgs[0])
+					}
+					jobs = n
+					commandArgs = commandArgs[1:]
+				case strings.HasPrefix(flag, "-j"):
+					n, err := strconv.Atoi(flag[2:])
+					if err != nil || n < 1 {
+						return fmt.Errorf("invalid job count: %s", flag[2:])
+					}
+					jobs = n
 				default:
 					return fmt.Errorf("unknown flag: %s", flag)
 				}
@@ -54,15 +78,15 @@ Examples:
 				return fmt.Errorf("no command specified after flags")
 			}
 
-			return runBatchMode(refresh, skipPackCheck, commandArgs)
+			return runBatchMode(refresh, skipPackCheck, jobs, commandArgs)
 		}
 }
 
-func runBatchMode(refresh bool, skipPackCheck bool, args []string) error {
+func runBatchMode(refresh bool, skipPackCheck bool, jobs int, args []string) error {
 	packDir, _ := os.Getwd()
 
 	// Get all subdirectories
-	files, err := ioutil.ReadDir(packDir)
+	files, err := os.ReadDir(packDir)
 	if err != nil {
 		return fmt.Errorf("failed to read directory: %w", err)
 	}
@@ -102,61 +126,41 @@ func runBatchMode(refresh bool, skipPackCheck bool, args []string) error {
 	}
 	fmt.Println()
 
-	// Get current executable path
-	selfExec, err := os.Executable()
-	if err != nil {
-		return fmt.Errorf("failed to get executable path: %w", err)
-	}
-	// Use full path to ensure executable can be found from any directory
-
-	var errors []string
-	successCount := 0
-
-	for i, dir := range targetDirs {
-		dirName := filepath.Base(dir)
-		fmt.Printf("[%d/%d] Processing: %s\n", i+1, len(targetDirs), dirName)
-
-		var err error
-
-		// Check if this is an arbitrary command that should be handled directly
-		if len(args) > 0 && (args[0] == "arb" || args[0] == "arbitrary" || args[0] == "exec" || args[0] == "run") {
-			// Handle arbitrary commands directly in the target directory
-			if len(args) < 2 {
-				fmt.Printf("  ERROR: No command specified for arbitrary execution\n")
-				errorMsg := fmt.Sprintf("Failed in %s: no command specified for arbitrary execution", dirName)
-				errors = append(errors, errorMsg)
-				continue
+	// Cancel running packs on Ctrl+C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	manager := newManager()
+	results, batchErr := manager.BatchOperation(ctx, targetDirs, packwrap.BatchOp{
+		Name:    args[0],
+		Args:    args[1:],
+		Refresh: refresh,
+		Jobs:    jobs,
+		OnComplete: func(result packwrap.BatchResult) {
+			// The SUCCESS or ERROR line is printed from the event that follows
+			fmt.Printf("Output of %s (%s):\n", filepath.Base(result.Dir), result.Duration.Round(1e6))
+			for _, line := range strings.Split(strings.TrimRight(result.Output, "\n"), "\n") {
+				if line != "" {
+					fmt.Printf("  %s\n", line)
+				}
 			}
+		},
+	})
 
-			// Execute the arbitrary command directly in the target directory
-			err = executeArbitraryInDirectory(dir, args[1:])
-		} else {
-			// Build command arguments - prepend executable name for regular commands
-			newArgs := append([]string{selfExec}, args...)
-
-			// Execute command in the pack directory
-			err = executeInDirectory(dir, newArgs)
-		}
-
-		if err != nil {
-			errorMsg := fmt.Sprintf("Failed in %s: %v", dirName, err)
-			errors = append(errors, errorMsg)
-			fmt.Printf("  ERROR: %s\n", errorMsg)
-		} else {
+	// Summary
+	successCount := 0
+	var errors []string
+	for _, result := range results {
+		switch {
+		case result.Skipped:
+			errors = append(errors, fmt.Sprintf("Skipped %s: %s", filepath.Base(result.Dir), result.Error))
+		case result.Error != "":
+			errors = append(errors, fmt.Sprintf("Failed in %s: %s", filepath.Base(result.Dir), result.Error))
+		default:
 			successCount++
-			fmt.Printf("  SUCCESS: Completed in %s\n", dirName)
-		}
-
-		// Refresh if requested
-		if refresh {
-			fmt.Printf("  Refreshing %s...\n", dirName)
-			if err := executePackwizRefresh(dir); err != nil {
-				fmt.Printf("  WARNING: Failed to refresh %s: %v\n", dirName, err)
-			}
 		}
+	}
 
-		fmt.Println()
-	} // Summary
 	fmt.Printf("Batch operation completed:\n")
 	fmt.Printf("  Successful: %d/%d\n", successCount, len(targetDirs))
 	if len(errors) > 0 {
@@ -164,85 +168,11 @@ func runBatchMode(refresh bool, skipPackCheck bool, args []string) error {
 		for _, err := range errors {
 			fmt.Printf("    - %s\n", err)
 		}
-		return fmt.Errorf("batch operation completed with %d error(s)", len(errors))
 	}
 
-	return nil
+	return batchErr
 }
 
 func hasPackToml(dir string) bool {
 	return utils.FindPackToml(dir) != ""
 }
-
-func executeInDirectory(dir string, args []string) error {
-	// Convert directory path for cross-platform compatibility
-	dir = filepath.ToSlash(dir)
-	if !strings.HasSuffix(dir, "/") {
-		dir = dir + "/"
-	}
-
-	fmt.Printf("  [PackWrap] Arbitrary: [%s] %s\n", dir, strings.Join(args, " "))
-
-	// Create command
-	cmd := exec.Command(args[0], args[1:]...)
-	cmd.Dir = filepath.Dir(dir)
-
-	// Check if directory exists, create if needed
-	if _, err := os.Stat(cmd.Dir); err != nil {
-		fmt.Printf("  [PackWrap] [ERROR] arbitrary directory not found, creating...\n")
-		if err := os.Mkdir(cmd.Dir, 0755); err != nil {
-			return fmt.Errorf("failed to create directory: %w", err)
-		}
-	}
-
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-
-	return cmd.Run()
-}
-
-func executePackwizRefresh(dir string) error {
-	// Convert directory path for cross-platform compatibility
-	dir = filepath.ToSlash(dir)
-	if !strings.HasSuffix(dir, "/") {
-		dir = dir + "/"
-	}
-
-	// The dir path includes trailing slash, so we need the directory part
-	workingDir := filepath.Dir(dir)
-
-	// Use centralized pack.toml detection
-	packLocation := utils.FindPackToml(workingDir)
-	if packLocation == "" {
-		return fmt.Errorf("pack.toml not found in %s or %s/.minecraft", workingDir, workingDir)
-	}
-
-	// Create packwiz refresh command
-	cmd := exec.Command("packwiz", "refresh")
-	cmd.Dir = packLocation
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	return cmd.Run()
-}
-
-func executeArbitraryInDirectory(dir string, args []string) error {
-	// Use centralized pack.toml detection
-	packLocation := utils.FindPackToml(dir)
-	if packLocation == "" {
-		// If no pack.toml found, just use the directory as-is (like original arbitrary command)
-		packLocation = dir
-	}
-
-	fmt.Printf("  [PackWrap] Executing arbitrary command in %s: %s\n", packLocation, strings.Join(args, " "))
-
-	// Create and execute command
-	cmd := exec.Command(args[0], args[1:]...)
-	cmd.Dir = packLocation
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-
-	return cmd.Run()
-}
@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/core"
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
+)
+
+// CmdBisect boots the test server with subsets of the pack's mods to find the ones that break it
+func CmdBisect() (names []string, shortHelp, longHelp string, execute func([]string) error) {
+	return []string{"bisect"},
+		"Find the mods that stop the server from starting",
+		`Bisect:
+  pw bisect [options]     - Boot the test server with fewer and fewer mods until
+                            the smallest set that still fails is found
+
+Options:
+  --timeout <duration>    - How long each boot may take (default 5m)
+  --until <regexp>        - Console line that counts as a successful boot
+                            (default: the "Done (...)! For help" line)
+  --fail-on <regexp>      - Console line that counts as a failed boot, for
+                            problems that do not crash the server
+  --keep                  - Keep .bisect, the scratch server of the last boot
+
+The test server is set up or updated first, as with 'pw server setup'. Each boot
+copies it into .bisect with only some of the server mods installed, plus the mods
+they require according to their jars, and a fresh world. A boot fails when the
+server crashes, exits, logs a --fail-on line or does not reach the --until line
+in time. Delta debugging then narrows the mods down until removing any one of
+them makes the server start, so mods that only fail together are found as well.
+
+Examples:
+  pw bisect
+  pw bisect --timeout 10m
+  pw bisect --fail-on "Mixin apply failed"
+  pw bisect --until "Preparing spawn area: 100%"`,
+		func(args []string) error {
+			options, err := parseBisectArgs(args)
+			if err != nil {
+				return err
+			}
+
+			packDir, _ := os.Getwd()
+			manager := newManager()
+			if err := manager.SetupServer(packDir, nil); err != nil {
+				return err
+			}
+
+			result, err := manager.Bisect(packDir, options)
+			if err != nil {
+				return err
+			}
+			printBisectResult(result)
+			return nil
+		}
+}
+
+// parseBisectArgs parses the options of pw bisect
+func parseBisectArgs(args []string) (packwrap.BisectOptions, error) {
+	options := packwrap.BisectOptions{Timeout: core.DefaultServerTestTimeout}
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		if name == "--keep" {
+			options.Keep = true
+			continue
+		}
+		if name != "--timeout" && name != "--until" && name != "--fail-on" {
+			return options, fmt.Errorf("unknown bisect option: %s", args[i])
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return options, fmt.Errorf("%s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+
+		switch name {
+		case "--timeout":
+			timeout, err := time.ParseDuration(value)
+			if err != nil || timeout <= 0 {
+				return options, fmt.Errorf("invalid timeout: %s", value)
+			}
+			options.Timeout = timeout
+		case "--until":
+			options.Success = value
+		case "--fail-on":
+			options.Failure = value
+		}
+	}
+	return options, nil
+}
+
+// printBisectResult prints the metafiles a bisection blames
+func printBisectResult(result *packwrap.BisectResult) {
+	fmt.Println()
+	if len(result.Culprits) == 1 {
+		fmt.Println("❌ The server does not start with this mod:")
+	} else {
+		fmt.Printf("❌ The server does not start with these %d mods together:\n", len(result.Culprits))
+	}
+	printBisectMods(result.Culprits)
+
+	if len(result.Dependencies) > 0 {
+		fmt.Println("\nInstalled with them because they require it:")
+		printBisectMods(result.Dependencies)
+	}
+
+	fmt.Printf("\nReason: %s\n", result.Reason)
+	fmt.Printf("Found in %d boots of %d server mods (%s)\n",
+		result.Boots, result.Mods, result.Duration.Round(time.Second))
+	fmt.Println("Update or remove these mods, then check the pack with 'pw server test'")
+}
+
+// printBisectMods lists metafiles with where their mods come from
+func printBisectMods(mods []packwrap.CrashSuspect) {
+	for _, mod := range mods {
+		fmt.Printf("  %s - %s (%s)\n", mod.Metafile, mod.Name, mod.Filename)
+		fmt.Printf("     Source: %s\n", crashSuspectSource(mod))
+		if len(mod.Evidence) > 0 {
+			fmt.Printf("     Mod IDs: %s\n", strings.Join(mod.Evidence, ", "))
+		}
+	}
+}
@@ -3,10 +3,10 @@ package commands
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/Merith-TK/packwiz-wrapper/internal/build"
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
 )
 
 // CmdBuild provides enhanced build/export operations
@@ -17,16 +17,59 @@ func CmdBuild() (names []string, shortHelp, longHelp string, execute func([]stri
   pw build curseforge|cf  - Export CurseForge pack
   pw build modrinth|mr    - Export Modrinth pack
   pw build multimc|mmc    - Export MultiMC pack
+  pw build prism          - Export Prism Launcher instance
   pw build technic        - Export Technic pack
   pw build server         - Export server pack
   pw build all            - Export all supported formats
 
-MultiMC Options:
+MultiMC/Prism Options:
   pw build mmc --local    - Use local pack.toml path (default: remote URL)
   pw build mmc -l         - Short form for --local
+  pw build prism --offline
+                          - Bundle the mod jars instead of installing them on launch
+
+Prism instances get the LWJGL version matching the pack's Minecraft version
+and JVM memory settings, configurable in packwrap.toml:
+
+    [build.prism]
+    min-memory = 2048
+    max-memory = 6144
+    bundle-mods = true
 
 Output Options:
-  pw build <format> -o <file>  - Specify output filename
+  pw build <format> -o <file>  - Specify output filename (a directory for 'all')
+  pw build <format> --name <template>
+                               - Name artifacts from a template, e.g. {{.Name}}-{{.Version}}-{{.Format}}
+
+Naming:
+  Artifacts are named from the name-template in packwrap.toml next to pack.toml,
+  so CI builds get stable, versioned names:
+
+    [build]
+    name-template = "{{.Name}}-{{.Version}}-{{.Format}}"
+    output-dir = ".build"
+
+  Template fields: .Name .Version .MinecraftVersion .Format .Dir .Timestamp
+
+File Filters:
+  MultiMC, Technic and server exports copy the pack's files, skipping anything
+  in .packwizignore plus per-format defaults (e.g. saves/ and options.txt for
+  servers). Adjust them with gitignore style patterns in packwrap.toml:
+
+    [build.server]
+    exclude = ["/config/client-only/"]
+    include = ["/saves/template/"]
+
+Server Packs:
+  start.sh, start.bat and server.properties are generated from the [server]
+  section of packwrap.toml, see 'pw help server'. The scripts launch the pack's
+  loader and, until its server is installed, print how to install it.
+
+Reproducible Builds:
+  Archives have sorted entries, normalized permissions and a fixed timestamp
+  taken from SOURCE_DATE_EPOCH, or the last git commit, so the same commit
+  always builds byte-identical artifacts. Every build updates SHA256SUMS and
+  manifest.json in the output directory.
 
 Examples:
   pw build cf             - Quick CurseForge export
@@ -36,24 +79,43 @@ Examples:
   pw build all            - Export everything`,
 		func(args []string) error {
 			if len(args) == 0 {
-				fmt.Println("Please specify a build target: cf, mr, mmc, technic, server, all")
+				fmt.Println("Please specify a build target: cf, mr, mmc, prism, technic, server, all")
 				return nil
 			}
 
 			packDir, _ := os.Getwd()
 
 			// Parse flags
-			var useLocal bool
+			var options packwrap.ExportOptions
 			var buildTarget string
 
-			for _, arg := range args {
-				switch arg {
-				case "-l", "--local":
-					useLocal = true
-				default:
-					if !strings.HasPrefix(arg, "-") && buildTarget == "" {
-						buildTarget = arg
+			for i := 0; i < len(args); i++ {
+				arg := args[i]
+				switch {
+				case arg == "-l" || arg == "--local":
+					options.MultiMCLocal = true
+				case arg == "--offline":
+					options.BundleMods = true
+				case arg == "-o" || arg == "--output" || arg == "--name":
+					if i+1 >= len(args) {
+						return fmt.Errorf("%s requires a value", arg)
+					}
+					i++
+					if arg == "--name" {
+						options.NameTemplate = args[i]
+					} else {
+						options.Output = args[i]
 					}
+				case strings.HasPrefix(arg, "--output="):
+					options.Output = strings.TrimPrefix(arg, "--output=")
+				case strings.HasPrefix(arg, "--name="):
+					options.NameTemplate = strings.TrimPrefix(arg, "--name=")
+				case strings.HasPrefix(arg, "-"):
+					return fmt.Errorf("unknown flag: %s", arg)
+				case buildTarget == "":
+					buildTarget = arg
+				default:
+					return fmt.Errorf("unexpected argument: %s", arg)
 				}
 			}
 
@@ -61,55 +123,30 @@ Examples:
 				return fmt.Errorf("no build target specified")
 			}
 
-			// Ensure .build directory exists
-			buildDir := filepath.Join(packDir, ".build")
-			if err := os.MkdirAll(buildDir, 0755); err != nil {
-				return fmt.Errorf("failed to create .build directory: %w", err)
-			}
-
-			// Get pack name from directory
-			packName := filepath.Base(packDir)
-
-			switch buildTarget {
-			case "curseforge", "cf":
-				return build.ExportCurseForge(packDir, packName)
-			case "modrinth", "mr":
-				return build.ExportModrinth(packDir, packName)
-			case "multimc", "mmc":
-				return build.ExportMultiMC(packDir, packName, useLocal)
-			case "technic":
-				return build.ExportTechnic(packDir, packName)
-			case "server":
-				return build.ExportServer(packDir, packName)
-			case "all":
-				fmt.Println("Exporting all formats...")
-				formats := []string{"curseforge", "modrinth", "multimc", "technic", "server"}
-				for _, format := range formats {
-					fmt.Printf("\n=== Exporting %s ===\n", format)
-					if err := executeBuildFormat(format, packDir, packName, useLocal); err != nil {
-						fmt.Printf("Warning: Failed to export %s: %v\n", format, err)
-					}
+			format := packwrap.ExportAll
+			if buildTarget != "all" {
+				name, ok := buildFormats[buildTarget]
+				if !ok {
+					return fmt.Errorf("unknown build target: %s", buildTarget)
 				}
-				return nil
-			default:
-				return fmt.Errorf("unknown build target: %s", buildTarget)
+				format = packwrap.ExportFormat(name)
 			}
+
+			_, err := newManager().ExportPack(packDir, format, options)
+			return err
 		}
 }
 
-func executeBuildFormat(format, packDir, packName string, useLocal bool) error {
-	switch format {
-	case "curseforge":
-		return build.ExportCurseForge(packDir, packName)
-	case "modrinth":
-		return build.ExportModrinth(packDir, packName)
-	case "multimc":
-		return build.ExportMultiMC(packDir, packName, useLocal)
-	case "technic":
-		return build.ExportTechnic(packDir, packName)
-	case "server":
-		return build.ExportServer(packDir, packName)
-	default:
-		return fmt.Errorf("unknown format: %s", format)
-	}
+// buildFormats maps build targets and their aliases to export formats
+var buildFormats = map[string]string{
+	"curseforge":    build.FormatCurseForge,
+	"cf":            build.FormatCurseForge,
+	"modrinth":      build.FormatModrinth,
+	"mr":            build.FormatModrinth,
+	"multimc":       build.FormatMultiMC,
+	"mmc":           build.FormatMultiMC,
+	"prism":         build.FormatPrism,
+	"prismlauncher": build.FormatPrism,
+	"technic":       build.FormatTechnic,
+	"server":        build.FormatServer,
 }
@@ -0,0 +1,201 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
+)
+
+// CmdCache manages the shared download cache
+func CmdCache() (names []string, shortHelp, longHelp string, execute func([]string) error) {
+	return []string{"cache"},
+		"Manage the shared mod download cache",
+		`Cache Commands:
+  pw cache size           - Show the number of cached files and their total size
+  pw cache path           - Show the cache directory
+  pw cache prune [options]
+                          - Remove old entries, or the least recently used ones
+  pw cache verify [--fix] - Re-hash every entry and report corrupt files
+
+Prune Options:
+  --older-than <age>      - Remove entries unused for longer than age (e.g. 30d, 12h)
+  --max-size <size>       - Remove least recently used entries until the cache
+                            fits in size (e.g. 500MB, 2GB)
+
+Examples:
+  pw cache size
+  pw cache prune --older-than 30d
+  pw cache prune --max-size 2GB
+  pw cache verify --fix   - Delete corrupt entries so they are downloaded again
+
+Server setup and server/Technic exports store every mod they download in the
+cache, keyed by hash, and reuse it for every pack.`,
+		func(args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("cache command requires a subcommand. Use 'pw help cache' for available commands")
+			}
+
+			cache := utils.DefaultCache()
+			subcommand := args[0]
+			subArgs := args[1:]
+
+			switch subcommand {
+			case "size", "info":
+				return cacheSize(cache)
+			case "path":
+				fmt.Println(cache.Dir)
+				return nil
+			case "prune":
+				return cachePrune(cache, subArgs)
+			case "verify":
+				return cacheVerify(cache, subArgs)
+			default:
+				return fmt.Errorf("unknown cache subcommand: %s\nUse 'pw help cache' for available commands", subcommand)
+			}
+		}
+}
+
+// cacheSize prints the size of the cache
+func cacheSize(cache *utils.Cache) error {
+	count, size, err := cache.Size()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("📦 Cache: %s\n", cache.Dir)
+	fmt.Printf("   Files: %d\n", count)
+	fmt.Printf("   Size:  %s\n", formatSize(size))
+	return nil
+}
+
+// cachePrune removes entries by age and size
+func cachePrune(cache *utils.Cache, args []string) error {
+	var maxAge time.Duration
+	var maxSize int64
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(arg, "=")
+		if name != "--older-than" && name != "--max-size" {
+			return fmt.Errorf("unknown prune option: %s", arg)
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+
+		var err error
+		if name == "--older-than" {
+			maxAge, err = parseAge(value)
+		} else {
+			maxSize, err = parseSize(value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if maxAge == 0 && maxSize == 0 {
+		return fmt.Errorf("prune requires --older-than or --max-size")
+	}
+
+	removed, err := cache.Prune(maxAge, maxSize)
+	var freed int64
+	for _, entry := range removed {
+		freed += entry.Size
+	}
+	fmt.Printf("🧹 Removed %d files (%s)\n", len(removed), formatSize(freed))
+	return err
+}
+
+// cacheVerify re-hashes every entry
+func cacheVerify(cache *utils.Cache, args []string) error {
+	fix := false
+	for _, arg := range args {
+		if arg != "--fix" {
+			return fmt.Errorf("unknown verify option: %s", arg)
+		}
+		fix = true
+	}
+
+	corrupt, err := cache.Verify(fix)
+	if err != nil {
+		return err
+	}
+
+	if len(corrupt) == 0 {
+		fmt.Println("✅ All cached files match their hashes")
+		return nil
+	}
+
+	for _, entry := range corrupt {
+		fmt.Printf("❌ %s:%s (%s)\n", entry.HashFormat, entry.Hash, entry.Path)
+	}
+	if fix {
+		fmt.Printf("Removed %d corrupt files\n", len(corrupt))
+		return nil
+	}
+	return fmt.Errorf("%d cached files are corrupt, run 'pw cache verify --fix' to remove them", len(corrupt))
+}
+
+// parseAge parses a Go duration, also accepting whole days such as 30d
+func parseAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age: %s", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid age: %s", value)
+	}
+	return age, nil
+}
+
+// parseSize parses a byte count with an optional K, M or G suffix (B and iB optional)
+func parseSize(value string) (int64, error) {
+	number := strings.ToUpper(strings.TrimSpace(value))
+	number = strings.TrimSuffix(strings.TrimSuffix(number, "B"), "I")
+
+	multiplier := int64(1)
+	switch {
+	case strings.HasSuffix(number, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(number, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(number, "G"):
+		multiplier = 1 << 30
+	}
+	if multiplier != 1 {
+		number = number[:len(number)-1]
+	}
+
+	n, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size: %s", value)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// formatSize formats a byte count for display
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(size)/(1<<30))
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
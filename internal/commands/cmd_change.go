@@ -3,36 +3,58 @@ package commands
 import (
 	"fmt"
 	"os"
-	"path/filepath"
-	"regexp"
+	"strings"
 
+	"github.com/Merith-TK/packwiz-wrapper/internal/packwiz"
 	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
 )
 
+// changeKeys maps the keys accepted by 'pw change' to their pack.toml table and key
+var changeKeys = map[string][2]string{
+	"name":                     {"", "name"},
+	"author":                   {"", "author"},
+	"version":                  {"", "version"},
+	"description":              {"", "description"},
+	"minecraft":                {"versions", "minecraft"},
+	"fabric":                   {"versions", "fabric"},
+	"forge":                    {"versions", "forge"},
+	"neoforge":                 {"versions", "neoforge"},
+	"quilt":                    {"versions", "quilt"},
+	"acceptable-game-versions": {"options", "acceptable-game-versions"},
+}
+
 func CmdChange() (names []string, shortHelp, longHelp string, execute func([]string) error) {
 	return []string{"change", "modify", "adjust"},
-		"Modify pack.toml fields (name, author, version)",
+		"Modify pack.toml fields (name, author, version, versions, options)",
 		`Change Commands:
   pw change name "New Name"                  - Changes the pack name
   pw change author "Someone"                 - Changes the pack author
   pw change version "1.0.0"                  - Changes the pack version
-  pw change author "Someone" version "1.0.0" - Changes both the pack author and version`,
+  pw change description "Text"               - Changes the pack description
+  pw change minecraft 1.20.1                 - Changes [versions] minecraft
+  pw change fabric 0.15.11                   - Changes a loader version (fabric, forge, neoforge, quilt)
+  pw change acceptable-game-versions 1.20,1.20.1 - Changes [options] acceptable-game-versions
+  pw change versions.<key> <value>           - Changes any key in [versions]
+  pw change options.<key> <value>            - Changes any key in [options]
+  pw change author "Someone" version "1.0.0" - Changes both the pack author and version
+
+Missing keys are added. Comments, key order and other settings in pack.toml are kept.`,
 		func(args []string) error {
 			if len(args) == 0 || (len(args) == 1 && args[0] == "help") {
 				fmt.Println(longHelp)
 				return nil
 			}
 
-			newValues := map[string]string{}
-			validKeys := map[string]bool{"name": true, "author": true, "version": true}
+			var changes []packChange
 
 			// Parse arguments
 			for i := 0; i < len(args); i++ {
 				key := args[i]
 
-				if !validKeys[key] {
+				table, field, ok := resolveChangeKey(key)
+				if !ok {
 					fmt.Printf("Unknown key: %s\n", key)
-					fmt.Println("Valid keys are: name, author, version")
+					fmt.Println("Valid keys are: name, author, version, description, minecraft, fabric, forge, neoforge, quilt, acceptable-game-versions, versions.<key>, options.<key>")
 					return nil
 				}
 
@@ -41,16 +63,55 @@ func CmdChange() (names []string, shortHelp, longHelp string, execute func([]str
 					return nil
 				}
 
-				value := args[i+1]
-				newValues[key] = value
+				changes = append(changes, packChange{table: table, key: field, value: args[i+1]})
 				i++
 			}
 
-			return modify(newValues)
+			return modify(changes)
+		}
+}
+
+// packChange is a single pack.toml edit requested on the command line
+type packChange struct {
+	table string
+	key   string
+	value string
+}
+
+// resolveChangeKey maps a command line key to its pack.toml table and key
+func resolveChangeKey(key string) (table, field string, ok bool) {
+	if location, found := changeKeys[key]; found {
+		return location[0], location[1], true
+	}
+
+	if table, field, found := strings.Cut(key, "."); found && field != "" {
+		if table == "versions" || table == "options" {
+			return table, field, true
 		}
+	}
+	return "", "", false
 }
 
-func modify(vals map[string]string) error {
+// changeValue converts a command line value to the TOML type pack.toml uses for the key
+func changeValue(change packChange) interface{} {
+	if change.table == "options" {
+		if change.key == "acceptable-game-versions" {
+			var versions []string
+			for _, version := range strings.Split(change.value, ",") {
+				if version = strings.TrimSpace(version); version != "" {
+					versions = append(versions, version)
+				}
+			}
+			return versions
+		}
+		if change.value == "true" || change.value == "false" {
+			return change.value == "true"
+		}
+	}
+	return change.value
+}
+
+func modify(changes []packChange) error {
 	packDir, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("failed to get current directory: %w", err)
@@ -61,35 +122,38 @@ func modify(vals map[string]string) error {
 		return fmt.Errorf("pack.toml not found")
 	}
 
-	packFile := filepath.Join(packLocation, "pack.toml")
-	data, err := os.ReadFile(packFile)
+	// Only pack.toml is edited, so a stale index or broken metafile must not get in the way
+	pack, err := packwiz.LoadPackMeta(packLocation)
 	if err != nil {
-		return fmt.Errorf("failed to read pack.toml: %w", err)
+		return fmt.Errorf("failed to load pack: %w", err)
 	}
 
-	original := string(data)
-	modified := original
+	changed := false
+	for _, change := range changes {
+		name := change.key
+		if change.table != "" {
+			name = change.table + "." + change.key
+		}
 
-	for key, value := range vals {
-		// Match: key = "something"
-		pattern := fmt.Sprintf(`(?m)^%s\s*=\s*".*?"`, regexp.QuoteMeta(key))
-		re := regexp.MustCompile(pattern)
-		replacement := fmt.Sprintf(`%s = "%s"`, key, value)
+		value := changeValue(change)
+		if current, ok := pack.Document().Get(change.table, change.key); ok && fmt.Sprint(current) == fmt.Sprint(value) {
+			fmt.Printf("%s is already \"%s\"\n", name, change.value)
+			continue
+		}
 
-		if re.MatchString(modified) {
-			modified = re.ReplaceAllString(modified, replacement)
-			fmt.Printf("Updated %s to \"%s\"\n", key, value)
-		} else {
-			fmt.Printf("Key '%s' not found — skipping\n", key)
+		if err := pack.Set(change.table, change.key, value); err != nil {
+			return fmt.Errorf("failed to set %s: %w", name, err)
 		}
+		fmt.Printf("Updated %s to \"%s\"\n", name, change.value)
+		changed = true
 	}
 
-	if original == modified {
+	if !changed {
 		fmt.Println("No changes made.")
 		return nil
 	}
 
-	if err := os.WriteFile(packFile, []byte(modified), 0644); err != nil {
+	if err := pack.Save(); err != nil {
 		return fmt.Errorf("failed to write pack.toml: %w", err)
 	}
 
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
+)
+
+// CmdAnalyzeCrash maps a crash report or server log back to the pack's metafiles
+func CmdAnalyzeCrash() (names []string, shortHelp, longHelp string, execute func([]string) error) {
+	return []string{"analyze-crash", "crash"},
+		"Find the metafiles of the mods a crash report blames",
+		`Analyze Crash:
+  pw analyze-crash          - Analyze the newest crash report of the test server,
+                              or .run/logs/latest.log when there is none
+  pw analyze-crash <file>   - Analyze a crash report or log file
+
+Fabric, Quilt, Forge and NeoForge crash reports and logs are supported. The
+mod IDs and jar names they blame are matched against the pack's metafiles by
+file name, metafile name and mod name, and every match is listed with its
+Modrinth or CurseForge source and version.
+
+Examples:
+  pw server test && pw analyze-crash
+  pw analyze-crash ~/.local/share/PrismLauncher/instances/Pack/.minecraft/crash-reports/crash-2024-01-01_12.00.00-client.txt`,
+		func(args []string) error {
+			if len(args) > 1 {
+				return fmt.Errorf("analyze-crash takes at most one file")
+			}
+
+			packDir, _ := os.Getwd()
+			file := ""
+			if len(args) == 1 {
+				file = args[0]
+			}
+
+			analysis, err := newManager().AnalyzeCrash(packDir, file)
+			if err != nil {
+				return err
+			}
+			printCrashAnalysis(analysis)
+			return nil
+		}
+}
+
+// printCrashAnalysis prints the suspects of a crash
+func printCrashAnalysis(analysis *packwrap.CrashAnalysis) {
+	kind := "Log"
+	if analysis.CrashReport {
+		kind = "Crash report"
+	}
+	fmt.Printf("🔍 %s: %s\n", kind, analysis.File)
+	if analysis.Description != "" {
+		fmt.Printf("   Description: %s\n", analysis.Description)
+	}
+	fmt.Println()
+
+	if len(analysis.Suspects) == 0 {
+		fmt.Println("No metafiles matched the mods this file blames")
+	} else {
+		fmt.Println("Responsible metafiles:")
+		for _, suspect := range analysis.Suspects {
+			fmt.Printf("  %s - %s (%s)\n", suspect.Metafile, suspect.Name, suspect.Filename)
+			fmt.Printf("     Source: %s\n", crashSuspectSource(suspect))
+			fmt.Printf("     Matched: %s\n", strings.Join(suspect.Evidence, ", "))
+		}
+	}
+
+	if len(analysis.Unmatched) > 0 {
+		fmt.Printf("\nAlso blamed, but not in the pack: %s\n", strings.Join(analysis.Unmatched, ", "))
+	}
+}
+
+// crashSuspectSource describes where a suspect's mod is downloaded from
+func crashSuspectSource(suspect packwrap.CrashSuspect) string {
+	switch suspect.Source {
+	case "modrinth":
+		return fmt.Sprintf("Modrinth project %s, version %s", suspect.Project, suspect.Version)
+	case "curseforge":
+		return fmt.Sprintf("CurseForge project %s, file %s", suspect.Project, suspect.Version)
+	default:
+		return "direct download"
+	}
+}
@@ -1,12 +1,13 @@
 package commands
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"strings"
 
+	"github.com/Merith-TK/packwiz-wrapper/internal/core"
 	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
 )
 
 // CmdImport provides mod import functionality
@@ -19,6 +20,14 @@ func CmdImport() (names []string, shortHelp, longHelp string, execute func([]str
   pw import -i file.txt -y - Import from file with auto-confirm
   pw import <url1> <url2>  - Import mods from URLs directly
 
+Import File Format:
+  https://modrinth.com/mod/sodium            - Modrinth/CurseForge URL
+  mr:cc-tweaked:Zoo9N9Dv                     - source:slug[:version] reference
+  https://example.com/pack.zip resourcepacks/ My Pack
+                                             - URL PATH NAME (direct URLs)
+  - [Sodium](https://modrinth.com/mod/sodium) - 'pw modlist' markdown
+  # comment                                  - Ignored, as is any other text
+
 Examples:
   pw import -i import.txt  - Import from import.txt file
   pw import -y             - Import from default file with auto-confirm
@@ -64,31 +73,17 @@ Examples:
 func importFromFile(filename string, autoConfirm bool) error {
 	fmt.Printf("[PackWrap] Importing from file: %s\n", filename)
 
-	file, err := os.Open(filename)
+	entries, err := core.ReadImportFile(filename)
 	if err != nil {
-		return fmt.Errorf("failed to open import file: %w", err)
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	var lines []string
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line != "" && !strings.HasPrefix(line, "#") {
-			lines = append(lines, line)
-		}
+		return err
 	}
 
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("error reading file: %w", err)
-	}
-
-	if len(lines) == 0 {
+	if len(entries) == 0 {
 		fmt.Println("No mods found in import file")
 		return nil
 	}
 
-	return importMods(lines, autoConfirm)
+	return importMods(entries, autoConfirm)
 }
 
 func importFromStrings(urls []string, autoConfirm bool) error {
@@ -96,10 +91,10 @@ func importFromStrings(urls []string, autoConfirm bool) error {
 		return fmt.Errorf("no URLs provided")
 	}
 
-	return importMods(urls, autoConfirm)
+	return importMods(core.ParseImportArgs(urls), autoConfirm)
 }
 
-func importMods(mods []string, autoConfirm bool) error {
+func importMods(entries []packwrap.ImportLine, autoConfirm bool) error {
 	packDir, _ := os.Getwd()
 
 	// Find pack directory using our helper function
@@ -108,9 +103,9 @@ func importMods(mods []string, autoConfirm bool) error {
 		return fmt.Errorf("pack.toml not found")
 	}
 
-	fmt.Printf("Found %d mod(s) to import:\n", len(mods))
-	for i, mod := range mods {
-		fmt.Printf("  %d. %s\n", i+1, mod)
+	fmt.Printf("Found %d mod(s) to import:\n", len(entries))
+	for i, entry := range entries {
+		fmt.Printf("  %d. %s\n", i+1, strings.TrimSpace(entry.Raw))
 	}
 
 	if !autoConfirm {
@@ -124,72 +119,17 @@ func importMods(mods []string, autoConfirm bool) error {
 	}
 
 	fmt.Println("Starting import process...")
-	var errors []string
-
-	for i, mod := range mods {
-		fmt.Printf("\n[%d/%d] Importing: %s\n", i+1, len(mods), mod)
-
-		url, path, name := parseLine(mod, "")
-		fmt.Printf("  URL: %s\n", url)
-		if path != "" {
-			fmt.Printf("  Path: %s\n", path)
-		}
-		if name != "" {
-			fmt.Printf("  Name: %s\n", name)
-		}
-
-		// Use smart mod adding (same logic as pw mod add)
-		if err := AddModSmart(packLocation, url); err != nil {
-			errorMsg := fmt.Sprintf("Failed to import %s: %v", mod, err)
-			errors = append(errors, errorMsg)
-			fmt.Printf("  ERROR: %s\n", errorMsg)
-		} else {
-			fmt.Printf("  SUCCESS: Imported %s\n", mod)
-		}
-	}
+	manager := newManager()
+	result := manager.ImportEntries(packLocation, entries)
 
-	if len(errors) > 0 {
-		fmt.Printf("\nImport completed with %d error(s):\n", len(errors))
-		for _, err := range errors {
-			fmt.Printf("  - %s\n", err)
+	if failed := result.Failed(); len(failed) > 0 {
+		fmt.Printf("\nImport completed with %d error(s):\n", len(failed))
+		for _, line := range failed {
+			fmt.Printf("  - line %d: %s: %s\n", line.Number, line.URL, line.Error)
 		}
-		return fmt.Errorf("%d imports failed", len(errors))
+		return fmt.Errorf("%d imports failed", len(failed))
 	}
 
-	fmt.Printf("\nSuccessfully imported all %d mod(s)!\n", len(mods))
+	fmt.Printf("\nSuccessfully imported all %d mod(s)!\n", len(entries))
 	return nil
 }
-
-// parseLine parses a mod entry line to extract URL, path, and name
-// Returns url, path, name
-func parseLine(line string, previousLine string) (string, string, string) {
-	line = strings.TrimSpace(line)
-
-	// If line doesn't contain a space, treat the whole line as URL
-	if !strings.Contains(line, " ") {
-		return line, "", ""
-	}
-
-	// Split by space to get URL and additional info
-	parts := strings.Fields(line)
-	if len(parts) < 2 {
-		return line, "", ""
-	}
-
-	url := parts[0]
-
-	// Check if second part looks like a path (contains / or \)
-	if strings.Contains(parts[1], "/") || strings.Contains(parts[1], "\\") {
-		// Format: URL PATH [NAME...]
-		path := parts[1]
-		name := ""
-		if len(parts) > 2 {
-			name = strings.Join(parts[2:], " ")
-		}
-		return url, path, name
-	} else {
-		// Format: URL NAME
-		name := strings.Join(parts[1:], " ")
-		return url, "", name
-	}
-}
@@ -116,7 +116,7 @@ func javaStatus(args []string) error {
 
 	// Try to find pack.toml
 	packDir, _ := os.Getwd()
-	packToml, _, err := utils.LoadPackConfig(packDir)
+	packToml, _, err := packwiz.LoadPackConfig(packDir)
 	if err != nil {
 		fmt.Printf("❌ No pack found: %v\n", err)
 		fmt.Println("Run this command from a directory containing pack.toml")
@@ -129,6 +129,12 @@ func javaStatus(args []string) error {
 
 	fmt.Printf("Pack: %s\n", packToml.Name)
 	fmt.Printf("Minecraft Version: %s\n", mcVersion)
+	if loader, loaderVersion := packwiz.ModLoader(packToml); loader != "" {
+		fmt.Printf("Mod Loader: %s %s\n", packwiz.LoaderName(loader), loaderVersion)
+		if loader == packwiz.LoaderNeoForge {
+			fmt.Println("   The NeoForge installer runs with the required Java during 'pw server setup'")
+		}
+	}
 	fmt.Printf("Required Java: %d (minimum: %d)\n", required, strict)
 	fmt.Println()
 
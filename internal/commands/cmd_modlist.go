@@ -5,11 +5,9 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
-	"strings"
 
 	"github.com/Merith-TK/packwiz-wrapper/internal/packwiz"
 	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
-	"github.com/pelletier/go-toml"
 )
 
 // CmdModlist provides mod listing functionality
@@ -66,17 +64,12 @@ func generateModlist(rawOutput bool, showVersions bool, onlyPrint bool) error {
 		return fmt.Errorf("pack.toml not found")
 	}
 
-	// Read index.toml
-	indexFile := filepath.Join(packLocation, "index.toml")
-	indexFileHandler, err := os.Open(indexFile)
+	pack, err := packwiz.ReadPack(packLocation)
 	if err != nil {
-		return fmt.Errorf("failed to open index.toml: %w", err)
+		return err
 	}
-	defer indexFileHandler.Close()
-
-	var index packwiz.IndexToml
-	if err := toml.NewDecoder(indexFileHandler).Decode(&index); err != nil {
-		return fmt.Errorf("failed to decode index.toml: %w", err)
+	for _, err := range pack.Errors {
+		fmt.Printf("Warning: %v\n", err)
 	}
 
 	// Prepare output file (only if not raw output or print only)
@@ -97,35 +90,7 @@ func generateModlist(rawOutput bool, showVersions bool, onlyPrint bool) error {
 		}
 	}
 
-	// Process mod files
-	var modlist []packwiz.ModToml
-	for _, file := range index.Files {
-		if !file.Metafile {
-			continue
-		}
-
-		modFilePath := filepath.Join(packLocation, file.File)
-		modFile, err := os.Open(modFilePath)
-		if err != nil {
-			fmt.Printf("Warning: failed to open %s: %v\n", file.File, err)
-			continue
-		}
-
-		var mod packwiz.ModToml
-		if err := toml.NewDecoder(modFile).Decode(&mod); err != nil {
-			fmt.Printf("Warning: failed to decode %s: %v\n", file.File, err)
-			modFile.Close()
-			continue
-		}
-		modFile.Close()
-
-		// Set mod.Parse.ModID to the last part of the path without the .pw.toml extension
-		// This is needed for CurseForge URLs
-		modID := strings.TrimSuffix(filepath.Base(modFilePath), ".pw.toml")
-		mod.Parse.ModID = modID
-
-		modlist = append(modlist, mod)
-	}
+	modlist := pack.Mods
 
 	// Sort mods by side
 	var clientMods []packwiz.ModToml
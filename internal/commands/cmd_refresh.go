@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/config"
+	"github.com/Merith-TK/packwiz-wrapper/internal/packwiz"
+	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
+)
+
+// CmdRefresh provides a native, incremental index refresh
+func CmdRefresh() (names []string, shortHelp, longHelp string, execute func([]string) error) {
+	return []string{"refresh"},
+		"Refresh index.toml, rehashing only changed files",
+		`Refresh Commands:
+  pw refresh              - Update index.toml and the pack.toml index hash
+  pw refresh -j <n>       - Hash up to n files in parallel (default: CPU count)
+  pw refresh --no-cache   - Rehash every file, ignoring the local cache
+  pw refresh --packwiz    - Use packwiz's own refresh instead
+
+The native refresh produces the same index.toml as 'packwiz refresh', but keeps
+a size/modification time cache (in the pw data directory, outside the pack) so
+unchanged files are not hashed again. packwrap.toml is added to .packwizignore
+so neither refresh ever indexes it. Other packwiz refresh flags such as --build
+are passed through to packwiz.`,
+		func(args []string) error {
+			options := packwiz.RefreshOptions{}
+
+			for i := 0; i < len(args); i++ {
+				switch arg := args[i]; arg {
+				case "--no-cache":
+					options.NoCache = true
+				case "-j", "--jobs":
+					if i+1 >= len(args) {
+						return fmt.Errorf("%s requires a number", arg)
+					}
+					n, err := strconv.Atoi(args[i+1])
+					if err != nil || n < 1 {
+						return fmt.Errorf("invalid job count: %s", args[i+1])
+					}
+					options.Jobs = n
+					i++
+				case "--packwiz":
+					return ExecuteSelfCommand(append([]string{"refresh"}, append(args[:i:i], args[i+1:]...)...), "")
+				default:
+					// Leave anything we do not handle natively to packwiz
+					return ExecuteSelfCommand(append([]string{"refresh"}, args...), "")
+				}
+			}
+
+			packDir, _ := os.Getwd()
+			return refreshPack(packDir, options)
+		}
+}
+
+// refreshPack runs a native refresh of the pack containing packDir and prints a summary
+func refreshPack(packDir string, options packwiz.RefreshOptions) error {
+	packLocation := utils.FindPackToml(packDir)
+	if packLocation == "" {
+		return fmt.Errorf("pack.toml not found")
+	}
+
+	result, err := packwiz.Refresh(packLocation, options)
+	if err != nil {
+		return fmt.Errorf("failed to refresh pack: %w", err)
+	}
+
+	if result.IgnoreUpdated {
+		fmt.Printf("Added %s to %s\n", config.FileName, packwiz.IgnoreFile)
+	}
+
+	if !result.Changed {
+		fmt.Printf("Index is up to date (%d files, %d hashed)\n", result.Files, result.Hashed)
+		return nil
+	}
+
+	fmt.Printf("Index refreshed: %d files (%d hashed, %d added, %d updated, %d removed)\n",
+		result.Files, result.Hashed, result.Added, result.Updated, result.Removed)
+	return nil
+}
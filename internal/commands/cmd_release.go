@@ -5,6 +5,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
 )
 
 // CmdRelease provides release and changelog generation functionality
@@ -115,8 +117,7 @@ func generateReleaseFiles() error {
 
 	// This would typically build all export formats
 	packDir, _ := os.Getwd()
-	packName := filepath.Base(packDir)
-	if err := executeBuildFormat("all", packDir, packName, false); err != nil {
+	if _, err := newManager().ExportPack(packDir, packwrap.ExportAll, packwrap.ExportOptions{}); err != nil {
 		return fmt.Errorf("failed to build release files: %w", err)
 	}
 
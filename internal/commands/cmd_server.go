@@ -1,15 +1,23 @@
 package commands
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
-	"github.com/BurntSushi/toml"
-	"github.com/Merith-TK/packwiz-wrapper/internal/build"
+	"github.com/Merith-TK/packwiz-wrapper/internal/core"
 	"github.com/Merith-TK/packwiz-wrapper/internal/packwiz"
 	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
 )
 
 // CmdServer provides comprehensive server management functionality
@@ -19,19 +27,66 @@ func CmdServer() (names []string, shortHelp, longHelp string, execute func([]str
 		`Server Management Commands:
   pw server setup         - Download and deploy all server files
   pw server start         - Start the server (foreground)
-  pw server stop          - Stop the running server (if managed by pw)
+  pw server start --detach
+                          - Start the server in the background
+  pw server start --supervise [options]
+                          - Keep the server running: restart it after a crash,
+                            waiting longer after every crash in a row
+  pw server stop [--timeout <duration>]
+                          - Stop the background server, killing it after the
+                            timeout (default 30s)
+  pw server logs [-f] [-n <lines>]
+                          - Show the end of logs/latest.log, -f keeps following it
+  pw server test [--timeout <duration>]
+                          - Boot the server until it has started, then stop it;
+                            fails with the mods to blame on a crash (default 5m)
   pw server reset         - Delete and redeploy all server files
   pw server delete        - Delete all server files
-  pw server status        - Show server status and information
+  pw server status        - Show server status, and the restart history of a
+                            supervised server
+
+Supervise Options (combine with --detach to supervise in the background):
+  --max-restarts <n>      - Give up after n crashes in a row (default 5); a server
+                            that stays up for 10 minutes starts a new count
+  --backoff <duration>    - Wait before the first restart (default 10s), doubled
+                            after every crash in a row
+  --max-backoff <duration>
+                          - Longest wait between restarts (default 5m)
+
+Every crash is archived to .run/crashes/<time>/ with the crash reports and the
+console output of the run, and recorded in .run/supervisor.json.
 
 Examples:
   pw server setup         - Set up server for the first time
   pw server start         - Start the configured server
+  pw server start -d      - Start it in the background
+  pw server start -s -d   - Keep it running in the background, restarting it on crashes
+  pw server logs -f       - Follow the server log
+  pw server stop          - Stop the background server
+  pw server test          - Check that the pack boots before a release
   pw server reset         - Clean and reconfigure server
   pw server delete        - Remove all server files
 
 The server command automatically detects pack versions and downloads
-the appropriate server JAR and Java requirements.`,
+the appropriate server JAR and Java requirements.
+
+Configuration:
+  Memory, JVM flags and server.properties come from the [server] section of
+  packwrap.toml next to pack.toml, which 'pw build server' uses as well:
+
+    [server]
+    java = "/usr/lib/jvm/java-21/bin/java"  # default: a Java matching the pack
+    min-memory = "2G"
+    max-memory = "6G"
+    flags = "aikar"           # Aikar's G1 tuning flags
+    jvm-args = ["-Dfml.queryResult=confirm"]
+    port = 25565
+    motd = "My Pack"
+    difficulty = "normal"
+
+    [server.properties]       # any other server.properties key
+    max-players = 10
+    white-list = true`,
 		func(args []string) error {
 			if len(args) == 0 {
 				return fmt.Errorf("server command requires a subcommand. Use 'pw help server' for available commands")
@@ -53,6 +108,10 @@ the appropriate server JAR and Java requirements.`,
 				return serverDelete(subArgs)
 			case "status":
 				return serverStatus(subArgs)
+			case "logs", "log":
+				return serverLogs(subArgs)
+			case "test":
+				return serverTest(subArgs)
 			default:
 				return fmt.Errorf("unknown server subcommand: %s", subcommand)
 			}
@@ -63,100 +122,370 @@ the appropriate server JAR and Java requirements.`,
 func serverSetup(args []string) error {
 	packDir, _ := os.Getwd()
 
-	// Find and parse pack.toml
-	packToml, packLocation, err := utils.LoadPackConfig(packDir)
-	if err != nil {
-		return fmt.Errorf("failed to load pack configuration: %w", err)
+	manager := newManager()
+	if err := manager.SetupServer(packDir, nil); err != nil {
+		return err
 	}
 
-	// Determine Minecraft version
-	mcVersion := getMinecraftVersion(packToml)
-	if mcVersion == "" {
-		return fmt.Errorf("could not determine Minecraft version from pack.toml")
+	fmt.Println("Use 'pw server start' to launch the server")
+	return nil
+}
+
+// serverStart starts the configured server in the foreground, in the
+// background with --detach, or under a restart watchdog with --supervise
+func serverStart(args []string) error {
+	packDir, _ := os.Getwd()
+
+	options, err := parseStartArgs(args)
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("Setting up server for Minecraft %s...\n", mcVersion)
+	manager := newManager()
+	if options.supervise {
+		return serverSupervise(manager, packDir, options)
+	}
+	if options.detach {
+		pid, err := manager.StartDetachedServer(packDir)
+		if err != nil {
+			return withSetupHint(err)
+		}
+		fmt.Printf("✅ Server running in the background (PID %d)\n", pid)
+		fmt.Println("Use 'pw server logs -f' to follow its log and 'pw server stop' to stop it")
+		return nil
+	}
 
-	// Ensure Java is available (download if necessary)
-	java, err := utils.EnsureJava(mcVersion)
+	server, err := manager.StartTestServer(packDir)
 	if err != nil {
-		fmt.Printf("Java setup warning: %v\n", err)
-		fmt.Println("Server may not start correctly without compatible Java")
-		// Continue anyway - user might have Java in PATH
-	} else {
-		fmt.Printf("Using Java %s (version %d)\n", java.Version, java.Major)
+		return withSetupHint(err)
 	}
 
-	// Create server run directory
-	runDir := filepath.Join(packDir, ".run")
-	if err := os.MkdirAll(runDir, 0755); err != nil {
-		return fmt.Errorf("failed to create .run directory: %w", err)
+	fmt.Println("Press Ctrl+C to stop the server")
+
+	// Forward console input to the server
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if err := server.SendCommand(scanner.Text()); err != nil {
+				return
+			}
+		}
+	}()
+
+	// Shut the server down cleanly on Ctrl+C
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	go func() {
+		if _, ok := <-interrupt; ok {
+			fmt.Println("\n⏹️  Stopping server...")
+			server.Stop(30 * time.Second)
+		}
+	}()
+
+	for line := range server.Output() {
+		fmt.Println(line)
 	}
 
-	// Create server configuration files
-	if err := createServerConfig(runDir, packLocation); err != nil {
-		return fmt.Errorf("failed to create server configuration: %w", err)
+	return server.Wait()
+}
+
+// startOptions are the options of pw server start
+type startOptions struct {
+	detach    bool
+	supervise bool
+	restart   packwrap.SuperviseOptions
+}
+
+// parseStartArgs parses the options of pw server start
+func parseStartArgs(args []string) (startOptions, error) {
+	var options startOptions
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		switch name {
+		case "--detach", "-d":
+			options.detach = true
+			continue
+		case "--supervise", "-s":
+			options.supervise = true
+			continue
+		case "--max-restarts", "--backoff", "--max-backoff":
+		default:
+			return options, fmt.Errorf("unknown start option: %s", args[i])
+		}
+
+		if !hasValue {
+			if i+1 >= len(args) {
+				return options, fmt.Errorf("%s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		if name == "--max-restarts" {
+			restarts, err := strconv.Atoi(value)
+			if err != nil || restarts <= 0 {
+				return options, fmt.Errorf("invalid restart count: %s", value)
+			}
+			options.restart.MaxRestarts = restarts
+			continue
+		}
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration <= 0 {
+			return options, fmt.Errorf("invalid %s: %s", strings.TrimPrefix(name, "--"), value)
+		}
+		if name == "--backoff" {
+			options.restart.Backoff = duration
+		} else {
+			options.restart.MaxBackoff = duration
+		}
 	}
 
-	// Install mods using packwiz installer
-	if err := installMods(runDir, packLocation); err != nil {
-		return fmt.Errorf("failed to install mods: %w", err)
+	if !options.supervise && options.restart != (packwrap.SuperviseOptions{}) {
+		return options, fmt.Errorf("--max-restarts, --backoff and --max-backoff require --supervise")
 	}
+	return options, nil
+}
 
-	// Download appropriate server JAR
-	if err := downloadServerJar(runDir, packToml, mcVersion); err != nil {
-		return fmt.Errorf("failed to download server JAR: %w", err)
+// serverSupervise runs the server under the restart watchdog, in this process
+// or, with --detach, in a background pw process
+func serverSupervise(manager *core.Manager, packDir string, options startOptions) error {
+	if options.detach {
+		executable, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to find the pw executable: %w", err)
+		}
+		command := []string{executable, "server", "start", "--supervise"}
+		if options.restart.MaxRestarts > 0 {
+			command = append(command, "--max-restarts", strconv.Itoa(options.restart.MaxRestarts))
+		}
+		if options.restart.Backoff > 0 {
+			command = append(command, "--backoff", options.restart.Backoff.String())
+		}
+		if options.restart.MaxBackoff > 0 {
+			command = append(command, "--max-backoff", options.restart.MaxBackoff.String())
+		}
+
+		pid, err := manager.StartDetachedSupervisor(packDir, command)
+		if err != nil {
+			return withSetupHint(err)
+		}
+		fmt.Printf("✅ Supervisor running in the background (PID %d), its log is .run/supervisor.log\n", pid)
+		fmt.Println("Use 'pw server status' for the restart history and 'pw server stop' to stop it")
+		return nil
 	}
 
-	fmt.Println("✅ Server setup completed successfully!")
-	fmt.Println("Use 'pw server start' to launch the server")
+	fmt.Println("Press Ctrl+C to stop the server, use 'pw server logs -f' to follow it")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := manager.SuperviseServer(ctx, packDir, options.restart); err != nil {
+		return withSetupHint(err)
+	}
 	return nil
 }
 
-// serverStart starts the configured server
-func serverStart(args []string) error {
+// withSetupHint points to 'pw server setup' when err is about a missing server
+func withSetupHint(err error) error {
+	if errors.Is(err, core.ErrServerNotSetUp) {
+		return fmt.Errorf("%w\nRun 'pw server setup' first", err)
+	}
+	return err
+}
+
+// serverStop stops the server started with --detach
+func serverStop(args []string) error {
 	packDir, _ := os.Getwd()
-	runDir := filepath.Join(packDir, ".run")
 
-	// Verify server is set up
-	if err := verifyServerSetup(runDir); err != nil {
-		return fmt.Errorf("server not properly set up: %w\nRun 'pw server setup' first", err)
+	timeout, err := parseTimeoutArgs("stop", args, 30*time.Second)
+	if err != nil {
+		return err
 	}
 
-	// Load pack config for Java validation
-	packToml, _, err := utils.LoadPackConfig(packDir)
-	var javaCmd = "java" // Default fallback
+	return newManager().StopServer(packDir, timeout)
+}
+
+// serverTest boots the server until it has started and reports why it failed otherwise
+func serverTest(args []string) error {
+	packDir, _ := os.Getwd()
+
+	timeout, err := parseTimeoutArgs("test", args, core.DefaultServerTestTimeout)
+	if err != nil {
+		return err
+	}
 
+	result, err := newManager().TestServer(packDir, timeout)
 	if err != nil {
-		fmt.Printf("Warning: could not load pack config: %v\n", err)
-	} else {
-		mcVersion := getMinecraftVersion(packToml)
-		if java, err := utils.FindCompatibleJava(mcVersion); err == nil {
-			fmt.Printf("Using Java %s for Minecraft %s\n", java.Version, mcVersion)
-			javaCmd = java.Path
-		} else {
-			fmt.Printf("Java warning: %v\n", err)
+		return withSetupHint(err)
+	}
+
+	if result.Passed {
+		fmt.Printf("✅ Server test passed: started in %s\n", result.Duration.Round(100*time.Millisecond))
+		if len(result.Errors) > 0 {
+			fmt.Printf("⚠️  %d errors were logged while starting, see .run/logs/latest.log\n", len(result.Errors))
 		}
+		return nil
 	}
 
-	fmt.Println("🚀 Starting Minecraft server...")
-	fmt.Println("Press Ctrl+C to stop the server")
+	fmt.Printf("❌ Server test failed after %s: %s\n", result.Duration.Round(100*time.Millisecond), result.Reason)
+	if result.CrashReport != "" {
+		fmt.Printf("   Crash report: %s\n", result.CrashReport)
+	}
+	if len(result.Mods) > 0 {
+		fmt.Printf("   Mods involved: %s\n", strings.Join(result.Mods, ", "))
+	}
+	if len(result.Errors) > 0 {
+		fmt.Printf("   Errors (%d):\n", len(result.Errors))
+		for i, line := range result.Errors {
+			if i == 10 {
+				fmt.Printf("     ... %d more in .run/logs/latest.log\n", len(result.Errors)-i)
+				break
+			}
+			fmt.Printf("     %s\n", line)
+		}
+	}
+	if result.CrashReport != "" || len(result.Errors) > 0 {
+		fmt.Println("Run 'pw analyze-crash' to find the metafiles of the mods involved")
+	}
+	return fmt.Errorf("server test failed: %s", result.Reason)
+}
 
-	// Start server with appropriate memory allocation
-	cmd := exec.Command(javaCmd, "-Xmx2G", "-Xms1G", "-jar", "server.jar", "nogui")
-	cmd.Dir = runDir
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
+// parseTimeoutArgs parses an optional --timeout <duration> option
+func parseTimeoutArgs(subcommand string, args []string, timeout time.Duration) (time.Duration, error) {
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		if name != "--timeout" {
+			return 0, fmt.Errorf("unknown %s option: %s", subcommand, args[i])
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return 0, fmt.Errorf("--timeout requires a value")
+			}
+			i++
+			value = args[i]
+		}
 
-	return cmd.Run()
+		var err error
+		if timeout, err = time.ParseDuration(value); err != nil || timeout <= 0 {
+			return 0, fmt.Errorf("invalid timeout: %s", value)
+		}
+	}
+	return timeout, nil
 }
 
-// serverStop stops the running server (placeholder for future implementation)
-func serverStop(args []string) error {
-	fmt.Println("⚠️  Server stop functionality not yet implemented")
-	fmt.Println("Use Ctrl+C in the server terminal to stop the server")
-	return nil
+// serverLogs prints the end of the server log, optionally following it
+func serverLogs(args []string) error {
+	packDir, _ := os.Getwd()
+
+	follow := false
+	lines := 50
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-f", "--follow":
+			follow = true
+		case "-n", "--lines":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a value", args[i])
+			}
+			i++
+			n, err := strconv.Atoi(args[i])
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid line count: %s", args[i])
+			}
+			lines = n
+		default:
+			return fmt.Errorf("unknown logs option: %s", args[i])
+		}
+	}
+
+	logPath := core.ServerLogPath(packDir)
+	file, err := os.Open(logPath)
+	if os.IsNotExist(err) && !follow {
+		return fmt.Errorf("no server log found at %s", logPath)
+	}
+
+	var offset int64
+	if err == nil {
+		tail, err := lastLines(file, lines)
+		if err == nil {
+			offset, err = file.Seek(0, io.SeekCurrent)
+		}
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read server log: %w", err)
+		}
+		for _, line := range tail {
+			fmt.Println(line)
+		}
+	}
+	if !follow {
+		return nil
+	}
+
+	// Poll for new output until Ctrl+C
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-interrupt:
+			return nil
+		case <-ticker.C:
+			if offset, err = copyNewLogOutput(logPath, offset, os.Stdout); err != nil {
+				return fmt.Errorf("failed to read server log: %w", err)
+			}
+		}
+	}
+}
+
+// lastLines returns up to n lines from the end of r, reading it to the end
+func lastLines(r io.Reader, n int) ([]string, error) {
+	if n == 0 {
+		_, err := io.Copy(io.Discard, r)
+		return nil, err
+	}
+
+	ring := make([]string, 0, n)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(ring) == n {
+			ring = append(ring[1:], scanner.Text())
+		} else {
+			ring = append(ring, scanner.Text())
+		}
+	}
+	return ring, scanner.Err()
+}
+
+// copyNewLogOutput copies whatever was appended to the log at path since offset
+// and returns the new offset. A log that shrank was rotated by a server restart
+// and is read from the beginning.
+func copyNewLogOutput(path string, offset int64, out io.Writer) (int64, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return offset, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return offset, err
+	}
+	if info.Size() < offset {
+		offset = 0
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return offset, err
+	}
+
+	written, err := io.Copy(out, file)
+	return offset + written, err
 }
 
 // serverReset deletes and redeploys all server files
@@ -173,26 +502,14 @@ func serverReset(args []string) error {
 // serverDelete removes all server files
 func serverDelete(args []string) error {
 	packDir, _ := os.Getwd()
-	runDir := filepath.Join(packDir, ".run")
-
-	if _, err := os.Stat(runDir); os.IsNotExist(err) {
-		fmt.Println("ℹ️  No server directory found")
-		return nil
-	}
 
-	fmt.Println("🗑️  Deleting server files...")
-	if err := os.RemoveAll(runDir); err != nil {
-		return fmt.Errorf("failed to delete server directory: %w", err)
-	}
-
-	fmt.Println("✅ Server files deleted")
-	return nil
+	return newManager().CleanServer(packDir)
 }
 
 // serverStatus shows current server status and information
 func serverStatus(args []string) error {
 	packDir, _ := os.Getwd()
-	runDir := filepath.Join(packDir, ".run")
+	runDir := core.ServerRunDir(packDir)
 
 	fmt.Println("📊 Server Status")
 	fmt.Println("================")
@@ -204,13 +521,29 @@ func serverStatus(args []string) error {
 		fmt.Println("Status: ✅ Set up")
 	}
 
+	if status, err := newManager().ServerStatus(packDir); err == nil {
+		if status.Running {
+			uptime := time.Since(status.StartedAt).Round(time.Second)
+			fmt.Printf("Running: ✅ PID %d, up %s\n", status.PID, uptime)
+		} else {
+			fmt.Println("Running: ⏹️  No")
+		}
+		fmt.Printf("Port: %d\n", status.Port)
+		if status.Supervisor != nil {
+			printSupervisorStatus(status.Supervisor)
+		}
+	}
+
 	// Load pack information
-	if packToml, _, err := utils.LoadPackConfig(packDir); err == nil {
-		mcVersion := getMinecraftVersion(packToml)
+	if packToml, _, err := packwiz.LoadPackConfig(packDir); err == nil {
+		mcVersion := core.MinecraftVersion(packToml)
 		fmt.Printf("Minecraft Version: %s\n", mcVersion)
 
-		if packToml.Versions.Fabric != "" {
-			fmt.Printf("Fabric Version: %s\n", packToml.Versions.Fabric)
+		loader, loaderVersion := packwiz.ModLoader(packToml)
+		if loader == "" {
+			fmt.Println("Mod Loader: Vanilla")
+		} else {
+			fmt.Printf("%s Version: %s\n", packwiz.LoaderName(loader), loaderVersion)
 		}
 
 		// Check Java compatibility
@@ -228,9 +561,8 @@ func serverStatus(args []string) error {
 	// Only check server files if we have a server directory
 	if _, err := os.Stat(runDir); err == nil {
 		// Check server files
-		serverJar := filepath.Join(runDir, "server.jar")
-		if _, err := os.Stat(serverJar); err == nil {
-			fmt.Println("Server JAR: ✅ Present")
+		if args, err := core.ServerLaunchArgs(runDir); err == nil {
+			fmt.Printf("Server JAR: ✅ Present (%s)\n", args[len(args)-1])
 		} else {
 			fmt.Println("Server JAR: ❌ Missing")
 		}
@@ -248,133 +580,33 @@ func serverStatus(args []string) error {
 	return nil
 }
 
-// Helper functions
-
-func getMinecraftVersion(packToml *packwiz.PackToml) string {
-	// Prefer versions.minecraft, fallback to mc-version
-	if packToml.Versions.Minecraft != "" {
-		return packToml.Versions.Minecraft
+// printSupervisorStatus prints the state and restart history of the watchdog
+func printSupervisorStatus(supervisor *packwrap.SupervisorStatus) {
+	switch {
+	case supervisor.Running:
+		fmt.Printf("Supervisor: ✅ PID %d, up %s, at most %d restarts in a row\n",
+			supervisor.PID, time.Since(supervisor.StartedAt).Round(time.Second), supervisor.MaxRestarts)
+	case supervisor.GaveUp:
+		fmt.Printf("Supervisor: ❌ Gave up after %d restarts in a row\n", supervisor.MaxRestarts)
+	default:
+		fmt.Println("Supervisor: ⏹️  Not running")
 	}
-	return packToml.McVersion
-}
 
-func createServerConfig(runDir, packLocation string) error {
-	// Create eula.txt
-	eulaPath := filepath.Join(runDir, "eula.txt")
-	if err := os.WriteFile(eulaPath, []byte("eula=true\n"), 0644); err != nil {
-		return fmt.Errorf("failed to create eula.txt: %w", err)
+	if len(supervisor.Restarts) == 0 {
+		return
 	}
-	fmt.Println("✅ Created eula.txt")
-
-	// Copy server icon if available
-	iconSrc := filepath.Join(packLocation, "icon.png")
-	if _, err := os.Stat(iconSrc); err == nil {
-		iconDst := filepath.Join(runDir, "server-icon.png")
-		if err := copyFile(iconSrc, iconDst); err != nil {
-			fmt.Printf("⚠️  Warning: failed to copy server icon: %v\n", err)
+	fmt.Printf("Restart History (last %d):\n", len(supervisor.Restarts))
+	for _, restart := range supervisor.Restarts {
+		line := fmt.Sprintf("  %s  exit code %d after %s", restart.CrashedAt.Format("2006-01-02 15:04:05"),
+			restart.ExitCode, restart.Uptime.Round(time.Second))
+		if restart.Backoff > 0 {
+			line += fmt.Sprintf(", restarted after %s", restart.Backoff)
 		} else {
-			fmt.Println("✅ Copied server icon")
+			line += ", not restarted"
 		}
-	}
-
-	return nil
-}
-
-func installMods(runDir, packLocation string) error {
-	fmt.Println("📦 Installing mods using packwiz installer...")
-
-	packTomlPath := filepath.Join(packLocation, "pack.toml")
-
-	// Download packwiz installer if needed
-	installerPath := filepath.Join(packLocation, "packwiz-installer-bootstrap.jar")
-	if _, err := os.Stat(installerPath); os.IsNotExist(err) {
-		fmt.Println("⬇️  Downloading packwiz installer...")
-		if err := build.DownloadPackwizInstaller(installerPath); err != nil {
-			return fmt.Errorf("failed to download packwiz installer: %w", err)
-		}
-	}
-
-	// Determine which Java to use
-	javaCmd := "java" // Default fallback
-
-	// Try to read pack.toml to get MC version for Java selection
-	if data, err := os.ReadFile(packTomlPath); err == nil {
-		var packToml packwiz.PackToml
-		if err := toml.Unmarshal(data, &packToml); err == nil {
-			mcVersion := getMinecraftVersion(&packToml)
-			if java, err := utils.FindCompatibleJava(mcVersion); err == nil {
-				javaCmd = java.Path
-				fmt.Printf("Using Java %s for packwiz installer\n", java.Version)
-			}
+		fmt.Println(line)
+		if restart.Archive != "" {
+			fmt.Printf("    Archived to %s\n", filepath.Join(".run", restart.Archive))
 		}
 	}
-
-	// Run installer
-	cmd := exec.Command(javaCmd, "-jar", installerPath, packTomlPath, "-s", "server")
-	cmd.Dir = runDir
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("packwiz installer failed: %w", err)
-	}
-
-	fmt.Println("✅ Mods installed successfully")
-	return nil
-}
-
-func downloadServerJar(runDir string, packToml *packwiz.PackToml, mcVersion string) error {
-	serverJarPath := filepath.Join(runDir, "server.jar")
-
-	// Skip if already exists
-	if _, err := os.Stat(serverJarPath); err == nil {
-		fmt.Println("✅ Server JAR already exists")
-		return nil
-	}
-
-	fmt.Println("⬇️  Downloading server JAR...")
-
-	// Determine server type and version
-	if packToml.Versions.Fabric != "" {
-		return downloadFabricServer(serverJarPath, mcVersion, packToml.Versions.Fabric)
-	}
-
-	// TODO: Add support for Forge, Quilt, Vanilla
-	return fmt.Errorf("unsupported server type - only Fabric is currently supported")
-}
-
-func downloadFabricServer(serverJarPath, mcVersion, fabricVersion string) error {
-	// Use Fabric API to get the appropriate server JAR
-	url := fmt.Sprintf("https://meta.fabricmc.net/v2/versions/loader/%s/%s/1.1.0/server/jar",
-		mcVersion, fabricVersion)
-
-	fmt.Printf("Downloading Fabric server for MC %s with Fabric %s...\n", mcVersion, fabricVersion)
-
-	downloader := &utils.HTTPDownloader{}
-	if err := downloader.DownloadFile(url, serverJarPath); err != nil {
-		return fmt.Errorf("failed to download server JAR: %w", err)
-	}
-
-	fmt.Println("✅ Server JAR downloaded successfully")
-	return nil
-}
-
-func verifyServerSetup(runDir string) error {
-	// Check if server.jar exists
-	serverJarPath := filepath.Join(runDir, "server.jar")
-	if _, err := os.Stat(serverJarPath); os.IsNotExist(err) {
-		return fmt.Errorf("server.jar not found")
-	}
-
-	// Check if eula.txt exists
-	eulaPath := filepath.Join(runDir, "eula.txt")
-	if _, err := os.Stat(eulaPath); os.IsNotExist(err) {
-		return fmt.Errorf("eula.txt not found")
-	}
-
-	return nil
-}
-
-func copyFile(src, dst string) error {
-	return utils.CopyFile(src, dst)
 }
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"reflect"
@@ -8,7 +9,9 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/Merith-TK/packwiz-wrapper/internal/config"
 	"github.com/Merith-TK/packwiz-wrapper/internal/packwiz"
+	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
 )
 
 // Command interface for all commands - minimal and simple
@@ -102,32 +105,33 @@ func (b *BaseCommand) ExecutePackwizCommand(args []string) error {
 	return ExecuteSelfCommand(args, b.PackDir)
 }
 
-// ExecuteSelfCommand executes a command by calling the current executable
+// ExecuteSelfCommand runs a packwiz command against the pack in packDir and returns its error.
+// The pack is passed to packwiz with --pack-file, so the working directory is never changed.
 func ExecuteSelfCommand(args []string, packDir string) error {
-	// Change to pack directory if specified
-	if packDir != "" {
-		oldDir, err := os.Getwd()
+	if packDir == "" {
+		wd, err := os.Getwd()
 		if err != nil {
 			return fmt.Errorf("failed to get working directory: %w", err)
 		}
-		defer os.Chdir(oldDir)
-
-		if err := os.Chdir(packDir); err != nil {
-			return fmt.Errorf("failed to change to pack directory: %w", err)
-		}
+		packDir = wd
 	}
 
-	// For self-execution, we can use the integrated packwiz directly
-	// Save original args and replace with the command we want to execute
-	originalArgs := os.Args
-	os.Args = append([]string{os.Args[0]}, args...)
+	packLocation := utils.FindPackToml(packDir)
+	if packLocation == "" {
+		return fmt.Errorf("pack.toml not found in %s", packDir)
+	}
 
-	// Call packwiz directly
-	packwiz.PackwizExecute()
+	// Commands like add and remove refresh the index, which must not pick up packwrap.toml
+	if updated, err := packwiz.IgnoreWrapperConfig(packLocation); err != nil {
+		return err
+	} else if updated {
+		fmt.Printf("Added %s to %s\n", config.FileName, packwiz.IgnoreFile)
+	}
 
-	// Restore original args
-	os.Args = originalArgs
-	return nil
+	// Run packwiz in a child process so failures are returned instead of exiting
+	runner := &packwiz.Runner{Dir: packLocation, Stdout: os.Stdout, Stderr: os.Stderr}
+	_, err := runner.Run(context.Background(), utils.PackFileArgs(args, packLocation)...)
+	return err
 }
 
 // CommandRegistry holds all registered commands
@@ -1,8 +1,15 @@
 package commands
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/core"
 )
 
 func TestCmdDetectBasic(t *testing.T) {
@@ -39,6 +46,11 @@ func TestCmdBuildBasic(t *testing.T) {
 	if !strings.Contains(longHelp, "curseforge") {
 		t.Error("Build help should mention curseforge")
 	}
+
+	err := execute([]string{"cf", "mr"})
+	if err == nil || !strings.Contains(err.Error(), "unexpected argument: mr") {
+		t.Errorf("Expected a second target to be rejected, got %v", err)
+	}
 }
 
 func TestCmdVersionBasic(t *testing.T) {
@@ -80,3 +92,149 @@ func TestCmdModBasic(t *testing.T) {
 		t.Error("CmdMod should have execute function")
 	}
 }
+
+func TestParseCacheLimits(t *testing.T) {
+	ages := map[string]time.Duration{"30d": 30 * 24 * time.Hour, "12h": 12 * time.Hour}
+	for value, expected := range ages {
+		if got, err := parseAge(value); err != nil || got != expected {
+			t.Errorf("parseAge(%q) = %v, %v; want %v", value, got, err, expected)
+		}
+	}
+
+	sizes := map[string]int64{"500MB": 500 << 20, "2G": 2 << 30, "1.5KiB": 1536, "1024": 1024}
+	for value, expected := range sizes {
+		if got, err := parseSize(value); err != nil || got != expected {
+			t.Errorf("parseSize(%q) = %v, %v; want %v", value, got, err, expected)
+		}
+	}
+
+	for _, value := range []string{"soon", "-1d"} {
+		if _, err := parseAge(value); err == nil {
+			t.Errorf("Expected parseAge(%q) to fail", value)
+		}
+	}
+	if _, err := parseSize("big"); err == nil {
+		t.Error("Expected parseSize(\"big\") to fail")
+	}
+}
+
+func TestServerLogTail(t *testing.T) {
+	tail, err := lastLines(strings.NewReader("one\ntwo\nthree\nfour\n"), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(tail, ",") != "three,four" {
+		t.Errorf("Expected the last two lines, got %v", tail)
+	}
+
+	logPath := filepath.Join(t.TempDir(), "latest.log")
+	if err := os.WriteFile(logPath, []byte("started\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	offset, err := copyNewLogOutput(logPath, 0, &out)
+	if err != nil || offset != 8 || out.String() != "started\n" {
+		t.Fatalf("Expected the whole log, got %q at %d (%v)", out.String(), offset, err)
+	}
+
+	// Appended output is copied from the offset
+	file, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString("joined the game\n")
+	file.Close()
+	out.Reset()
+	if offset, err = copyNewLogOutput(logPath, offset, &out); err != nil || out.String() != "joined the game\n" {
+		t.Errorf("Expected the appended line, got %q (%v)", out.String(), err)
+	}
+
+	// A restart replaces the log, which is then read from the start
+	if err := os.WriteFile(logPath, []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if _, err := copyNewLogOutput(logPath, offset, &out); err != nil || out.String() != "new\n" {
+		t.Errorf("Expected the rotated log, got %q (%v)", out.String(), err)
+	}
+}
+
+func TestParseBisectArgs(t *testing.T) {
+	options, err := parseBisectArgs([]string{"--timeout", "10m", "--until=Preparing spawn area", "--fail-on", "Mixin apply failed", "--keep"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if options.Timeout != 10*time.Minute || options.Success != "Preparing spawn area" ||
+		options.Failure != "Mixin apply failed" || !options.Keep {
+		t.Errorf("Unexpected options %+v", options)
+	}
+
+	for _, args := range [][]string{{"--timeout", "soon"}, {"--until"}, {"--fast"}} {
+		if _, err := parseBisectArgs(args); err == nil {
+			t.Errorf("Expected %v to fail", args)
+		}
+	}
+}
+
+func TestParseStartArgs(t *testing.T) {
+	options, err := parseStartArgs([]string{"-s", "-d", "--max-restarts", "3", "--backoff=30s", "--max-backoff", "10m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !options.supervise || !options.detach || options.restart.MaxRestarts != 3 ||
+		options.restart.Backoff != 30*time.Second || options.restart.MaxBackoff != 10*time.Minute {
+		t.Errorf("Unexpected options %+v", options)
+	}
+
+	for _, args := range [][]string{{"--max-restarts", "0", "-s"}, {"--backoff", "soon", "-s"}, {"--backoff", "1m"}, {"--forever"}} {
+		if _, err := parseStartArgs(args); err == nil {
+			t.Errorf("Expected %v to fail", args)
+		}
+	}
+}
+
+// TestCmdChangeOnlyNeedsPackToml changes a pack whose index is missing
+func TestCmdChangeOnlyNeedsPackToml(t *testing.T) {
+	packDir := t.TempDir()
+	packFile := filepath.Join(packDir, "pack.toml")
+	content := "name = \"Old\" # the pack name\n[index]\nfile = \"index.toml\"\n[versions]\nminecraft = \"1.20.1\"\n"
+	if err := os.WriteFile(packFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(packDir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	_, _, _, execute := CmdChange()
+	if err := execute([]string{"name", "New", "fabric", "0.15.11"}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(packFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "name = \"New\" # the pack name\n[index]\nfile = \"index.toml\"\n[versions]\nminecraft = \"1.20.1\"\nfabric = \"0.15.11\"\n"
+	if string(data) != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, data)
+	}
+}
+
+func TestWithSetupHint(t *testing.T) {
+	notSetUp := fmt.Errorf("%w: server.jar not found", core.ErrServerNotSetUp)
+	if err := withSetupHint(notSetUp); !strings.HasSuffix(err.Error(), "Run 'pw server setup' first") {
+		t.Errorf("Expected a setup hint, got %q", err)
+	}
+
+	crashed := errors.New("server crashed 6 times in a row, giving up")
+	if err := withSetupHint(crashed); err != crashed {
+		t.Errorf("Expected the error unchanged, got %q", err)
+	}
+}
@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/core"
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
+)
+
+// newManager returns a manager that logs to the terminal and prints its events
+func newManager() *core.Manager {
+	manager := core.NewManager(&core.ConsoleLogger{})
+	manager.Subscribe(printEvent)
+	return manager
+}
+
+// printEvent renders a manager event as a terminal line
+func printEvent(event packwrap.Event) {
+	switch event.Type {
+	case packwrap.EventStepStarted, packwrap.EventExportStarted:
+		if event.Total > 0 {
+			fmt.Printf("[%d/%d] %s...\n", event.Current, event.Total, event.Message)
+		} else {
+			fmt.Printf("%s...\n", event.Message)
+		}
+	case packwrap.EventStepFinished:
+		fmt.Printf("  SUCCESS: %s\n", event.Message)
+	case packwrap.EventStepFailed:
+		fmt.Fprintf(os.Stderr, "  ERROR: %s: %s\n", event.Message, event.Error)
+	case packwrap.EventModAdded, packwrap.EventModRemoved:
+		fmt.Printf("  %s\n", event.Message)
+	case packwrap.EventExportFinished:
+		if event.Path != "" {
+			fmt.Printf("%s: %s\n", event.Message, event.Path)
+		} else {
+			fmt.Println(event.Message)
+		}
+	case packwrap.EventDownloadProgress:
+		if event.Total > 0 {
+			fmt.Printf("\r  %s: %d%%", event.Message, event.Current*100/event.Total)
+			if event.Current == event.Total {
+				fmt.Println()
+			}
+		} else {
+			fmt.Printf("  %s: %d MiB\n", event.Message, event.Current>>20)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+)
+
+// FileName is the per-pack wrapper configuration, kept next to pack.toml
+const FileName = "packwrap.toml"
+
+// Config holds the wrapper settings of a single pack. packwiz ignores this file.
+type Config struct {
+	Build  Build  `toml:"build"`
+	Server Server `toml:"server"`
+}
+
+// Build configures 'pw build' and exports
+type Build struct {
+	// NameTemplate is a text/template for artifact names without their extension,
+	// e.g. "{{.Name}}-{{.Version}}-{{.Format}}". See build.NameData for the fields.
+	NameTemplate string `toml:"name-template,omitempty"`
+
+	// OutputDir receives artifacts, relative to the pack directory. Defaults to .build.
+	OutputDir string `toml:"output-dir,omitempty"`
+
+	// Per format file filters. CurseForge and Modrinth exports are written by
+	// packwiz, which only uses .packwizignore.
+	MultiMC Filter `toml:"multimc,omitempty"`
+	Prism   Prism  `toml:"prism,omitempty"`
+	Technic Filter `toml:"technic,omitempty"`
+	Server  Filter `toml:"server,omitempty"`
+}
+
+// Prism configures Prism Launcher instance exports
+type Prism struct {
+	Filter
+
+	// MinMemory and MaxMemory are the instance's JVM heap limits in MiB
+	MinMemory int `toml:"min-memory,omitempty"`
+	MaxMemory int `toml:"max-memory,omitempty"`
+
+	// BundleMods ships the mod jars in the instance for offline play instead
+	// of installing them with packwiz-installer-bootstrap on launch
+	BundleMods bool `toml:"bundle-mods,omitempty"`
+}
+
+// Filter adjusts which pack files an export copies, using .packwizignore
+// (gitignore) pattern syntax. Patterns starting with / are relative to the
+// pack root, others match at any depth.
+type Filter struct {
+	// Exclude skips files on top of the format's defaults
+	Exclude []string `toml:"exclude,omitempty"`
+
+	// Include re-includes files skipped by the defaults, Exclude or .packwizignore
+	Include []string `toml:"include,omitempty"`
+}
+
+// Filter returns the file filter configured for an export format
+func (b Build) Filter(format string) Filter {
+	switch format {
+	case "multimc":
+		return b.MultiMC
+	case "prism":
+		return b.Prism.Filter
+	case "technic":
+		return b.Technic
+	case "server":
+		return b.Server
+	default:
+		return Filter{}
+	}
+}
+
+// Load reads the wrapper config from packLocation. A missing file is not an
+// error and yields the defaults.
+func Load(packLocation string) (*Config, error) {
+	cfg := &Config{}
+
+	path := filepath.Join(packLocation, FileName)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return cfg, nil
+	}
+
+	if _, err := toml.DecodeFile(path, cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", FileName, err)
+	}
+	if err := cfg.Server.validate(); err != nil {
+		return nil, fmt.Errorf("invalid [server] section in %s: %w", FileName, err)
+	}
+	return cfg, nil
+}
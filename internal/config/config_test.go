@@ -0,0 +1,137 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadMissingFileUsesDefaults(t *testing.T) {
+	cfg, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Build.NameTemplate != "" || cfg.Build.OutputDir != "" {
+		t.Errorf("Expected empty defaults, got %+v", cfg.Build)
+	}
+}
+
+func TestLoadBuildSection(t *testing.T) {
+	dir := t.TempDir()
+	content := `[build]
+name-template = "{{.Name}}-{{.Version}}"
+output-dir = "dist"
+`
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Build.NameTemplate != "{{.Name}}-{{.Version}}" || cfg.Build.OutputDir != "dist" {
+		t.Errorf("Unexpected build config: %+v", cfg.Build)
+	}
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte("[build\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir); err == nil {
+		t.Error("Expected an error for invalid TOML")
+	}
+}
+
+func TestLoadPrismSection(t *testing.T) {
+	dir := t.TempDir()
+	content := `[build.prism]
+exclude = ["*.psd"]
+max-memory = 6144
+bundle-mods = true
+`
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	prism := cfg.Build.Prism
+	if prism.MaxMemory != 6144 || !prism.BundleMods {
+		t.Errorf("Unexpected prism config: %+v", prism)
+	}
+	if filter := cfg.Build.Filter("prism"); len(filter.Exclude) != 1 || filter.Exclude[0] != "*.psd" {
+		t.Errorf("Expected the prism filter to be decoded, got %+v", filter)
+	}
+}
+
+func TestLoadServerSection(t *testing.T) {
+	dir := t.TempDir()
+	content := `[server]
+max-memory = "6G"
+flags = "aikar"
+jvm-args = ["-Dlog4j2.formatMsgNoLookups=true"]
+port = 25570
+motd = "Test Pack"
+difficulty = "hard"
+
+[server.properties]
+max-players = 8
+white-list = true
+motd = "From properties"
+`
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	args := cfg.Server.JavaArgs()
+	if args[0] != "-Xms"+DefaultServerMinMemory || args[1] != "-Xmx6G" {
+		t.Errorf("Expected default min and configured max memory, got %v", args[:2])
+	}
+	if len(args) != 2+len(AikarFlags)+1 || args[2] != AikarFlags[0] || args[len(args)-1] != "-Dlog4j2.formatMsgNoLookups=true" {
+		t.Errorf("Expected Aikar's flags followed by the extra JVM args, got %v", args)
+	}
+
+	properties := cfg.Server.ServerProperties()
+	expected := map[string]string{
+		"server-port": "25570",
+		"motd":        "From properties",
+		"difficulty":  "hard",
+		"max-players": "8",
+		"white-list":  "true",
+	}
+	if len(properties) != len(expected) {
+		t.Errorf("Expected %v, got %v", expected, properties)
+	}
+	for key, value := range expected {
+		if properties[key] != value {
+			t.Errorf("Expected %s=%s, got %q", key, value, properties[key])
+		}
+	}
+}
+
+func TestLoadInvalidServerSection(t *testing.T) {
+	for _, content := range []string{
+		"[server]\nmax-memory = \"lots\"\n",
+		"[server]\nflags = \"fast\"\n",
+		"[server]\nport = 70000\n",
+		"[server]\ndifficulty = \"extreme\"\n",
+	} {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, FileName), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(dir); err == nil {
+			t.Errorf("Expected an error for %q", content)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Defaults used when the [server] section leaves a setting out
+const (
+	DefaultServerMinMemory = "1G"
+	DefaultServerMaxMemory = "4G"
+)
+
+// FlagsAikar selects Aikar's G1 tuning flags, see https://docs.papermc.io/paper/aikars-flags
+const FlagsAikar = "aikar"
+
+// AikarFlags are Aikar's recommended JVM flags for heaps up to 12G
+var AikarFlags = []string{
+	"-XX:+UseG1GC",
+	"-XX:+ParallelRefProcEnabled",
+	"-XX:MaxGCPauseMillis=200",
+	"-XX:+UnlockExperimentalVMOptions",
+	"-XX:+DisableExplicitGC",
+	"-XX:+AlwaysPreTouch",
+	"-XX:G1NewSizePercent=30",
+	"-XX:G1MaxNewSizePercent=40",
+	"-XX:G1HeapRegionSize=8M",
+	"-XX:G1ReservePercent=20",
+	"-XX:G1HeapWastePercent=5",
+	"-XX:G1MixedGCCountTarget=4",
+	"-XX:InitiatingHeapOccupancyPercent=15",
+	"-XX:G1MixedGCLiveThresholdPercent=90",
+	"-XX:G1RSetUpdatingPauseTimePercent=5",
+	"-XX:SurvivorRatio=32",
+	"-XX:+PerfDisableSharedMem",
+	"-XX:MaxTenuringThreshold=1",
+	"-Dusing.aikars.flags=https://mcflags.emc.gs",
+	"-Daikars.new.flags=true",
+}
+
+// Server configures the test server run by 'pw server' and the start scripts
+// and server.properties of 'pw build server'
+type Server struct {
+	// Java is the java executable for 'pw server', overriding the automatic
+	// choice of a Java matching the Minecraft version
+	Java string `toml:"java,omitempty"`
+
+	// MinMemory and MaxMemory are heap sizes in java's -Xms/-Xmx syntax, e.g. 2G or 4096M
+	MinMemory string `toml:"min-memory,omitempty"`
+	MaxMemory string `toml:"max-memory,omitempty"`
+
+	// Flags names a preset of JVM flags; only "aikar" is known
+	Flags string `toml:"flags,omitempty"`
+
+	// JVMArgs are passed to java after the memory and preset flags
+	JVMArgs []string `toml:"jvm-args,omitempty"`
+
+	// Port, MOTD and Difficulty set the matching server.properties keys
+	Port       int    `toml:"port,omitempty"`
+	MOTD       string `toml:"motd,omitempty"`
+	Difficulty string `toml:"difficulty,omitempty"`
+
+	// Properties overrides any other server.properties key, winning over the
+	// settings above
+	Properties map[string]interface{} `toml:"properties,omitempty"`
+}
+
+var memoryPattern = regexp.MustCompile(`^[0-9]+[kKmMgG]?$`)
+
+// validate rejects settings that would only fail once java starts
+func (s Server) validate() error {
+	for name, value := range map[string]string{"min-memory": s.MinMemory, "max-memory": s.MaxMemory} {
+		if value != "" && !memoryPattern.MatchString(value) {
+			return fmt.Errorf("%s must be a size such as 2G or 4096M, got %q", name, value)
+		}
+	}
+	if s.Flags != "" && s.Flags != FlagsAikar {
+		return fmt.Errorf("unknown flags preset %q, expected %q", s.Flags, FlagsAikar)
+	}
+	if s.Port < 0 || s.Port > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535, got %d", s.Port)
+	}
+	switch s.Difficulty {
+	case "", "peaceful", "easy", "normal", "hard":
+	default:
+		return fmt.Errorf("difficulty must be peaceful, easy, normal or hard, got %q", s.Difficulty)
+	}
+	return nil
+}
+
+// JavaArgs returns the JVM arguments the server is started with: heap sizes,
+// the flags preset, then the extra JVM arguments
+func (s Server) JavaArgs() []string {
+	minMemory, maxMemory := s.MinMemory, s.MaxMemory
+	if minMemory == "" {
+		minMemory = DefaultServerMinMemory
+	}
+	if maxMemory == "" {
+		maxMemory = DefaultServerMaxMemory
+	}
+
+	args := []string{"-Xms" + minMemory, "-Xmx" + maxMemory}
+	if s.Flags == FlagsAikar {
+		args = append(args, AikarFlags...)
+	}
+	return append(args, s.JVMArgs...)
+}
+
+// ServerProperties returns the server.properties keys the config sets
+func (s Server) ServerProperties() map[string]string {
+	properties := map[string]string{}
+	if s.Port != 0 {
+		properties["server-port"] = strconv.Itoa(s.Port)
+	}
+	if s.MOTD != "" {
+		properties["motd"] = s.MOTD
+	}
+	if s.Difficulty != "" {
+		properties["difficulty"] = s.Difficulty
+	}
+	for key, value := range s.Properties {
+		properties[strings.TrimSpace(key)] = fmt.Sprint(value)
+	}
+	return properties
+}
@@ -0,0 +1,202 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/packwiz"
+	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
+)
+
+// arbitraryCommands are batch operations that run an external program instead of pw itself
+var arbitraryCommands = map[string]bool{
+	"arb":       true,
+	"arbitrary": true,
+	"exec":      true,
+	"run":       true,
+}
+
+// BatchOperation runs operation in every directory using a bounded worker pool.
+// Each directory's output is captured separately so parallel runs stay readable.
+// Directories that have not started when ctx is cancelled are marked as skipped,
+// running ones are killed.
+func (m *Manager) BatchOperation(ctx context.Context, dirs []string, operation packwrap.BatchOp) ([]packwrap.BatchResult, error) {
+	if operation.Name == "" {
+		return nil, fmt.Errorf("no command specified for batch operation")
+	}
+	if arbitraryCommands[operation.Name] && len(operation.Args) == 0 {
+		return nil, fmt.Errorf("no command specified for arbitrary execution")
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get executable path: %w", err)
+	}
+
+	jobs := operation.Jobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	if jobs > len(dirs) {
+		jobs = len(dirs)
+	}
+
+	results := make([]packwrap.BatchResult, len(dirs))
+	queue := make(chan int)
+	var completeMu sync.Mutex
+	var wg sync.WaitGroup
+
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				if ctx.Err() != nil {
+					results[i] = packwrap.BatchResult{Dir: dirs[i], Skipped: true, Error: ctx.Err().Error()}
+					continue
+				}
+
+				m.emit(packwrap.Event{
+					Type:      packwrap.EventStepStarted,
+					Operation: packwrap.OperationBatch,
+					PackDir:   dirs[i],
+					Subject:   filepath.Base(dirs[i]),
+					Message:   fmt.Sprintf("Running %s in %s", operation.Name, filepath.Base(dirs[i])),
+					Current:   int64(i + 1),
+					Total:     int64(len(dirs)),
+				})
+				results[i] = m.runBatchDir(ctx, executable, dirs[i], operation)
+
+				// Finish events follow OnComplete so renderers can print after the output
+				completeMu.Lock()
+				if operation.OnComplete != nil {
+					operation.OnComplete(results[i])
+				}
+				m.emitBatchResult(results[i], i, len(dirs))
+				completeMu.Unlock()
+			}
+		}()
+	}
+
+dispatch:
+	for i, dir := range dirs {
+		select {
+		case queue <- i:
+		case <-ctx.Done():
+			for j := i; j < len(dirs); j++ {
+				results[j] = packwrap.BatchResult{Dir: dirs[j], Skipped: true, Error: ctx.Err().Error()}
+			}
+			m.logger.Warn("Batch cancelled before %s", filepath.Base(dir))
+			break dispatch
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	for i, result := range results {
+		if result.Skipped {
+			m.emitBatchResult(result, i, len(dirs))
+		}
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+	}
+	if ctx.Err() != nil {
+		return results, fmt.Errorf("batch operation cancelled: %w", ctx.Err())
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("batch operation completed with %d error(s)", failed)
+	}
+	return results, nil
+}
+
+// emitBatchResult reports how the batch went in one directory
+func (m *Manager) emitBatchResult(result packwrap.BatchResult, index, total int) {
+	dirName := filepath.Base(result.Dir)
+	event := packwrap.Event{
+		Operation: packwrap.OperationBatch,
+		PackDir:   result.Dir,
+		Subject:   dirName,
+		Current:   int64(index + 1),
+		Total:     int64(total),
+	}
+
+	switch {
+	case result.Skipped:
+		event.Type = packwrap.EventStepFailed
+		event.Message = fmt.Sprintf("Skipped %s", dirName)
+		event.Error = result.Error
+	case result.Error != "":
+		event.Type = packwrap.EventStepFailed
+		event.Message = fmt.Sprintf("Failed in %s", dirName)
+		event.Error = result.Error
+	default:
+		event.Type = packwrap.EventStepFinished
+		event.Message = fmt.Sprintf("Completed in %s", dirName)
+	}
+	m.emit(event)
+}
+
+// runBatchDir runs the batch operation in a single directory, capturing its output
+func (m *Manager) runBatchDir(ctx context.Context, executable, dir string, operation packwrap.BatchOp) packwrap.BatchResult {
+	result := packwrap.BatchResult{Dir: dir}
+	started := time.Now()
+	var output bytes.Buffer
+
+	var cmd *exec.Cmd
+	if arbitraryCommands[operation.Name] {
+		// Arbitrary commands run in the pack location, or the directory itself without a pack
+		workDir := utils.FindPackToml(dir)
+		if workDir == "" {
+			workDir = dir
+		}
+		fmt.Fprintf(&output, "[PackWrap] Executing arbitrary command in %s: %s\n", workDir, strings.Join(operation.Args, " "))
+		cmd = exec.CommandContext(ctx, operation.Args[0], operation.Args[1:]...)
+		cmd.Dir = workDir
+	} else {
+		args := append([]string{operation.Name}, operation.Args...)
+		cmd = exec.CommandContext(ctx, executable, args...)
+		cmd.Dir = dir
+	}
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	m.logger.Debug("Running %s in %s", operation.Name, dir)
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		result.Error = err.Error()
+	}
+
+	// Refresh if requested, even after a failure so the index matches what is on disk
+	if operation.Refresh && ctx.Err() == nil {
+		if packLocation := utils.FindPackToml(dir); packLocation != "" {
+			fmt.Fprintf(&output, "[PackWrap] Refreshing %s...\n", filepath.Base(dir))
+			if refreshed, err := packwiz.Refresh(packLocation, packwiz.RefreshOptions{}); err != nil {
+				fmt.Fprintf(&output, "[PackWrap] WARNING: failed to refresh %s: %v\n", filepath.Base(dir), err)
+			} else {
+				fmt.Fprintf(&output, "[PackWrap] Index refreshed: %d files (%d hashed)\n", refreshed.Files, refreshed.Hashed)
+			}
+		} else {
+			fmt.Fprintf(&output, "[PackWrap] WARNING: pack.toml not found in %s, skipping refresh\n", dir)
+		}
+	}
+
+	result.Output = output.String()
+	result.Duration = time.Since(started)
+	return result
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
+)
+
+func TestBatchOperationCollectsPerDirResults(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	dirs := []string{t.TempDir(), t.TempDir(), t.TempDir()}
+	manager := NewManager(nil)
+
+	completed := 0
+	results, err := manager.BatchOperation(context.Background(), dirs, packwrap.BatchOp{
+		Name: "arb",
+		Args: []string{"sh", "-c", "echo hello"},
+		Jobs: 2,
+		OnComplete: func(result packwrap.BatchResult) {
+			completed++
+		},
+	})
+	if err != nil {
+		t.Fatalf("BatchOperation failed: %v", err)
+	}
+
+	if len(results) != len(dirs) || completed != len(dirs) {
+		t.Fatalf("Expected %d results and callbacks, got %d and %d", len(dirs), len(results), completed)
+	}
+	for i, result := range results {
+		if result.Dir != dirs[i] {
+			t.Errorf("Result %d: expected dir %s, got %s", i, dirs[i], result.Dir)
+		}
+		if result.Error != "" {
+			t.Errorf("Result %d: unexpected error %s", i, result.Error)
+		}
+	}
+}
+
+func TestBatchOperationReportsFailures(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	dirs := []string{t.TempDir(), t.TempDir()}
+	results, err := NewManager(nil).BatchOperation(context.Background(), dirs, packwrap.BatchOp{
+		Name: "arb",
+		Args: []string{"sh", "-c", "exit 3"},
+	})
+	if err == nil {
+		t.Fatal("Expected an error when commands fail")
+	}
+	for _, result := range results {
+		if result.Error == "" {
+			t.Errorf("Expected %s to record a failure", result.Dir)
+		}
+	}
+}
+
+func TestBatchOperationSkipsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	dirs := []string{t.TempDir(), t.TempDir()}
+	results, err := NewManager(nil).BatchOperation(ctx, dirs, packwrap.BatchOp{
+		Name: "arb",
+		Args: []string{"true"},
+		Jobs: 1,
+	})
+	if err == nil {
+		t.Fatal("Expected a cancellation error")
+	}
+	for _, result := range results {
+		if !result.Skipped {
+			t.Errorf("Expected %s to be skipped", result.Dir)
+		}
+	}
+}
@@ -0,0 +1,384 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/build"
+	"github.com/Merith-TK/packwiz-wrapper/internal/packwiz"
+	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
+)
+
+// bisectRunDir returns the scratch run directory that bisection boots servers in
+func bisectRunDir(packDir string) string {
+	return filepath.Join(packDir, ".bisect")
+}
+
+// Bisect finds the smallest set of the pack's server mods that still stops the
+// deployed server from booting. Every boot runs in a scratch copy of the run
+// directory that holds only some of the mods, plus the mods their jars say they
+// require, and the set is narrowed down with delta debugging.
+func (m *Manager) Bisect(packDir string, options packwrap.BisectOptions) (*packwrap.BisectResult, error) {
+	started := time.Now()
+	if err := m.checkServerStopped(packDir); err != nil {
+		return nil, err
+	}
+
+	check := defaultBootCheck
+	if options.Success != "" {
+		pattern, err := regexp.Compile(options.Success)
+		if err != nil {
+			return nil, fmt.Errorf("invalid success pattern: %w", err)
+		}
+		check.started = pattern
+	}
+	if options.Failure != "" {
+		pattern, err := regexp.Compile(options.Failure)
+		if err != nil {
+			return nil, fmt.Errorf("invalid failure pattern: %w", err)
+		}
+		check.failed = pattern
+	}
+
+	runDir := ServerRunDir(packDir)
+	if err := VerifyServerSetup(runDir); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrServerNotSetUp, err)
+	}
+
+	packLocation := utils.FindPackToml(packDir)
+	if packLocation == "" {
+		return nil, errors.New("pack.toml not found")
+	}
+	data, err := packwiz.ReadPack(packLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &bisector{
+		manager: m,
+		packDir: packDir,
+		runDir:  runDir,
+		scratch: bisectRunDir(packDir),
+		timeout: options.Timeout,
+		check:   check,
+		results: map[string]*packwrap.ServerTestResult{},
+	}
+	if err := b.load(data); err != nil {
+		return nil, err
+	}
+	if !options.Keep {
+		defer os.RemoveAll(b.scratch)
+	}
+
+	metafiles := make([]string, 0, len(b.mods))
+	for metafile := range b.mods {
+		metafiles = append(metafiles, metafile)
+	}
+	sort.Strings(metafiles)
+	m.logger.Info("🔬 Bisecting %d server mods", len(metafiles))
+
+	// Bisection only makes sense when the mods are what breaks the server
+	all, err := b.boot(metafiles)
+	if err != nil {
+		return nil, err
+	}
+	if all.Passed {
+		return nil, fmt.Errorf("the server starts with all %d mods, so there is nothing to bisect", len(metafiles))
+	}
+	none, err := b.boot(nil)
+	if err != nil {
+		return nil, err
+	}
+	if !none.Passed {
+		return nil, fmt.Errorf("the server fails without any of the pack's mods: %s", none.Reason)
+	}
+
+	culprits, err := minimalFailingSet(metafiles, func(set []string) (bool, error) {
+		result, err := b.boot(set)
+		if err != nil {
+			return false, err
+		}
+		return !result.Passed, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &packwrap.BisectResult{Mods: len(metafiles), Boots: b.boots}
+	installed := b.withDependencies(culprits)
+	if failed := b.results[strings.Join(installed, "\n")]; failed != nil {
+		result.Reason = failed.Reason
+	}
+
+	isCulprit := map[string]bool{}
+	for _, metafile := range culprits {
+		isCulprit[metafile] = true
+	}
+	for _, metafile := range installed {
+		suspect := crashSuspect(b.mods[metafile])
+		suspect.Evidence = b.ids[metafile]
+		if isCulprit[metafile] {
+			result.Culprits = append(result.Culprits, *suspect)
+		} else {
+			result.Dependencies = append(result.Dependencies, *suspect)
+		}
+	}
+	result.Duration = time.Since(started)
+	return result, nil
+}
+
+// bisector boots the deployed server with subsets of the pack's server mods
+type bisector struct {
+	manager *Manager
+	packDir string
+	runDir  string
+	scratch string
+	timeout time.Duration
+	check   bootCheck
+
+	mods     map[string]packwiz.ModToml // server metafiles by path
+	targets  map[string]string          // where each metafile's file is installed, relative to the run directory
+	ids      map[string][]string        // mod IDs each metafile's jar declares
+	requires map[string][]string        // metafiles each metafile requires
+
+	results map[string]*packwrap.ServerTestResult // boot results by the installed metafiles
+	boots   int
+}
+
+// load finds the server metafiles, checks that setup installed them and reads
+// their dependencies from the jars
+func (b *bisector) load(data *packwiz.PackData) error {
+	b.mods = map[string]packwiz.ModToml{}
+	b.targets = map[string]string{}
+	b.ids = map[string][]string{}
+	b.requires = map[string][]string{}
+
+	installer := &build.Installer{Side: build.SideServer}
+	for _, target := range installer.ModTargets(data) {
+		if _, err := os.Stat(filepath.Join(b.runDir, filepath.FromSlash(target.Target))); err != nil {
+			return fmt.Errorf("%s is not installed in %s, run 'pw server setup' first", target.Target, b.runDir)
+		}
+		metafile := metafilePath(target.Mod)
+		b.mods[metafile] = target.Mod
+		b.targets[metafile] = target.Target
+	}
+	if len(b.mods) == 0 {
+		return errors.New("the pack has no server mods to bisect")
+	}
+
+	// Map the mod IDs each jar requires to the metafiles that provide them
+	providers := map[string]string{}
+	required := map[string][]string{}
+	for metafile, target := range b.targets {
+		if !strings.HasSuffix(target, ".jar") {
+			continue
+		}
+		info, err := readModJar(filepath.Join(b.runDir, filepath.FromSlash(target)))
+		if err != nil {
+			b.manager.logger.Debug("could not read %s: %v", target, err)
+			continue
+		}
+		b.ids[metafile] = info.IDs
+		required[metafile] = info.Requires
+		for _, id := range info.IDs {
+			providers[id] = metafile
+		}
+	}
+	for metafile, ids := range required {
+		for _, id := range ids {
+			if provider, ok := providers[id]; ok && provider != metafile {
+				b.requires[metafile] = append(b.requires[metafile], provider)
+			}
+		}
+	}
+	return nil
+}
+
+// withDependencies adds the metafiles that metafiles require, directly or not
+func (b *bisector) withDependencies(metafiles []string) []string {
+	set := map[string]bool{}
+	queue := append([]string{}, metafiles...)
+	for len(queue) > 0 {
+		metafile := queue[0]
+		queue = queue[1:]
+		if set[metafile] {
+			continue
+		}
+		set[metafile] = true
+		queue = append(queue, b.requires[metafile]...)
+	}
+	return sortedKeys(set)
+}
+
+// boot starts the server with metafiles and their dependencies installed.
+// Results are remembered, so a set of mods is only ever booted once.
+func (b *bisector) boot(metafiles []string) (*packwrap.ServerTestResult, error) {
+	installed := b.withDependencies(metafiles)
+	key := strings.Join(installed, "\n")
+	if result, ok := b.results[key]; ok {
+		return result, nil
+	}
+
+	b.boots++
+	message := fmt.Sprintf("🔬 Boot %d: %d of %d mods", b.boots, len(metafiles), len(b.mods))
+	if extra := len(installed) - len(metafiles); extra > 0 {
+		message += fmt.Sprintf(" and %d they require", extra)
+	}
+	b.manager.logger.Info("%s", message)
+
+	if err := b.prepare(installed); err != nil {
+		return nil, err
+	}
+	result, err := b.manager.bootServer(b.packDir, b.scratch, b.timeout, b.check)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Passed {
+		b.manager.logger.Info("❌ %s", result.Reason)
+	}
+
+	b.results[key] = result
+	return result, nil
+}
+
+// prepare recreates the scratch run directory from the deployed server with only
+// the files of the installed metafiles, leaving out the world, logs, crash reports
+// and the state of a detached or supervised server
+func (b *bisector) prepare(installed []string) error {
+	if err := os.RemoveAll(b.scratch); err != nil {
+		return fmt.Errorf("failed to clear %s: %w", b.scratch, err)
+	}
+
+	level := serverProperty(b.runDir, "level-name")
+	if level == "" {
+		level = "world"
+	}
+	skip := map[string]bool{
+		filepath.ToSlash(level): true,
+		"logs":                  true,
+		"crash-reports":         true,
+		serverPIDFile:           true,
+		serverConsolePipe:       true,
+		serverOutputFile:        true,
+		serverCrashDir:          true,
+		supervisorStateFile:     true,
+		supervisorLogFile:       true,
+		serverStopFile:          true,
+	}
+	for _, target := range b.targets {
+		skip[target] = true
+	}
+	for _, metafile := range installed {
+		delete(skip, b.targets[metafile])
+	}
+
+	err := filepath.WalkDir(b.runDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(b.runDir, path)
+		if err != nil {
+			return err
+		}
+		if skip[filepath.ToSlash(relPath)] {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		dest := filepath.Join(b.scratch, relPath)
+		switch {
+		case entry.IsDir():
+			return os.MkdirAll(dest, 0755)
+		case !entry.Type().IsRegular():
+			return nil
+		case strings.HasSuffix(entry.Name(), ".jar"):
+			// Jars are only ever read, so linking them saves copying the libraries every boot
+			if os.Link(path, dest) == nil {
+				return nil
+			}
+		}
+		return utils.CopyFile(path, dest)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to prepare %s: %w", b.scratch, err)
+	}
+	return nil
+}
+
+// minimalFailingSet narrows items down with delta debugging to a subset for
+// which fails still holds, but no longer does once any single item is removed.
+// fails must hold for items.
+func minimalFailingSet(items []string, fails func([]string) (bool, error)) ([]string, error) {
+	granularity := 2
+	for len(items) >= 2 {
+		chunks := splitChunks(items, granularity)
+
+		reduced := false
+		for _, chunk := range chunks {
+			failed, err := fails(chunk)
+			if err != nil {
+				return nil, err
+			}
+			if failed {
+				items, granularity, reduced = chunk, 2, true
+				break
+			}
+		}
+
+		// With two chunks each complement is the other chunk, tested above
+		if !reduced && granularity > 2 {
+			for i := range chunks {
+				complement := withoutChunk(chunks, i)
+				failed, err := fails(complement)
+				if err != nil {
+					return nil, err
+				}
+				if failed {
+					items, granularity, reduced = complement, max(granularity-1, 2), true
+					break
+				}
+			}
+		}
+
+		if !reduced {
+			if granularity >= len(items) {
+				break
+			}
+			granularity = min(granularity*2, len(items))
+		}
+	}
+	return items, nil
+}
+
+// splitChunks splits items into n chunks of nearly equal size
+func splitChunks(items []string, n int) [][]string {
+	chunks := make([][]string, 0, n)
+	start := 0
+	for i := 0; i < n; i++ {
+		end := start + (len(items)-start)/(n-i)
+		chunks = append(chunks, items[start:end])
+		start = end
+	}
+	return chunks
+}
+
+// withoutChunk joins every chunk but the one at skip
+func withoutChunk(chunks [][]string, skip int) []string {
+	var items []string
+	for i, chunk := range chunks {
+		if i != skip {
+			items = append(items, chunk...)
+		}
+	}
+	return items
+}
@@ -0,0 +1,280 @@
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
+)
+
+func TestMinimalFailingSet(t *testing.T) {
+	items := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	tests := []struct {
+		name     string
+		failing  []string // fails when all of these are present
+		expected []string
+	}{
+		{"single culprit", []string{"f"}, []string{"f"}},
+		{"first item", []string{"a"}, []string{"a"}},
+		{"conflicting pair", []string{"b", "g"}, []string{"b", "g"}},
+		{"three together", []string{"a", "d", "h"}, []string{"a", "d", "h"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			result, err := minimalFailingSet(items, func(set []string) (bool, error) {
+				calls++
+				present := map[string]bool{}
+				for _, item := range set {
+					present[item] = true
+				}
+				for _, item := range tt.failing {
+					if !present[item] {
+						return false, nil
+					}
+				}
+				return true, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %v, got %v after %d calls", tt.expected, result, calls)
+			}
+		})
+	}
+}
+
+func TestSplitChunks(t *testing.T) {
+	chunks := splitChunks([]string{"a", "b", "c", "d", "e"}, 3)
+	expected := [][]string{{"a"}, {"b", "c"}, {"d", "e"}}
+	if !reflect.DeepEqual(chunks, expected) {
+		t.Errorf("Expected %v, got %v", expected, chunks)
+	}
+}
+
+// writeTestJar writes a zip with the given files, returning its content
+func writeTestJar(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range files {
+		file, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := file.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadModJar(t *testing.T) {
+	dir := t.TempDir()
+	nested := writeTestJar(t, map[string][]byte{
+		"fabric.mod.json": []byte(`{"id": "fabric-api-base", "depends": {"fabricloader": ">=0.15"}}`),
+	})
+	jars := map[string][]byte{
+		"fabric.jar": writeTestJar(t, map[string][]byte{
+			"fabric.mod.json": []byte(`{
+				"schemaVersion": 1,
+				"id": "fabric-api",
+				"provides": ["fabric"],
+				"depends": {"minecraft": "1.20.1", "cloth-config": "*"},
+				"recommends": {"modmenu": "*"},
+				"jars": [{"file": "META-INF/jars/base.jar"}]
+			}`),
+			"META-INF/jars/base.jar": nested,
+		}),
+		"quilt.jar": writeTestJar(t, map[string][]byte{
+			"quilt.mod.json": []byte(`{"quilt_loader": {
+				"id": "quilted",
+				"provides": [{"id": "quilted_alias"}],
+				"depends": ["qsl", {"id": "emi", "optional": true}]
+			}}`),
+		}),
+		"forge.jar": writeTestJar(t, map[string][]byte{
+			"META-INF/mods.toml": []byte(`modLoader = "javafml"
+[[mods]]
+modId = "create"
+[[dependencies.create]]
+modId = "flywheel"
+mandatory = true
+[[dependencies.create]]
+modId = "jei"
+mandatory = false
+`),
+		}),
+		"neoforge.jar": writeTestJar(t, map[string][]byte{
+			"META-INF/neoforge.mods.toml": []byte(`[[mods]]
+modId = "neomod"
+[[dependencies.neomod]]
+modId = "geckolib"
+[[dependencies.neomod]]
+modId = "jei"
+type = "optional"
+`),
+		}),
+		"library.jar": writeTestJar(t, map[string][]byte{"com/example/Library.class": nil}),
+	}
+
+	expected := map[string]modJarInfo{
+		"fabric.jar":   {IDs: []string{"fabric", "fabric-api", "fabric-api-base"}, Requires: []string{"cloth-config", "fabricloader", "minecraft"}},
+		"quilt.jar":    {IDs: []string{"quilted", "quilted_alias"}, Requires: []string{"qsl"}},
+		"forge.jar":    {IDs: []string{"create"}, Requires: []string{"flywheel"}},
+		"neoforge.jar": {IDs: []string{"neomod"}, Requires: []string{"geckolib"}},
+		"library.jar":  {IDs: []string{}, Requires: []string{}},
+	}
+	for name, content := range jars {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		info, err := readModJar(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(info, expected[name]) {
+			t.Errorf("%s: expected %+v, got %+v", name, expected[name], info)
+		}
+	}
+}
+
+// TestBisectWithFakeServer bisects a pack whose stand-in server refuses to start
+// with one mod, or without the library another mod requires
+func TestBisectWithFakeServer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stand-in server is a shell script")
+	}
+
+	tests := []struct {
+		breaking     string
+		culprits     []string
+		dependencies []string
+	}{
+		{breaking: "broken", culprits: []string{"mods/broken.pw.toml"}},
+		{breaking: "addon", culprits: []string{"mods/addon.pw.toml"}, dependencies: []string{"mods/library.pw.toml"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.breaking, func(t *testing.T) {
+			packDir := writeBisectTestPack(t, tt.breaking)
+
+			result, err := NewManager(nil).Bisect(packDir, packwrap.BisectOptions{Timeout: 30 * time.Second})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var culprits, dependencies []string
+			for _, suspect := range result.Culprits {
+				culprits = append(culprits, suspect.Metafile)
+			}
+			for _, suspect := range result.Dependencies {
+				dependencies = append(dependencies, suspect.Metafile)
+			}
+			if !reflect.DeepEqual(culprits, tt.culprits) || !reflect.DeepEqual(dependencies, tt.dependencies) {
+				t.Errorf("Expected culprits %v with dependencies %v, got %v with %v",
+					tt.culprits, tt.dependencies, culprits, dependencies)
+			}
+			if result.Mods != 4 || !strings.Contains(result.Reason, "exit status 1") {
+				t.Errorf("Unexpected result %+v", result)
+			}
+
+			// The deployed server is left alone and the scratch directory removed
+			if _, err := os.Stat(filepath.Join(ServerRunDir(packDir), "mods", "broken.jar")); err != nil {
+				t.Errorf("Expected the run directory to keep every mod: %v", err)
+			}
+			if _, err := os.Stat(bisectRunDir(packDir)); !os.IsNotExist(err) {
+				t.Errorf("Expected the scratch directory to be removed, got %v", err)
+			}
+		})
+	}
+
+	t.Run("nothing to bisect", func(t *testing.T) {
+		packDir := writeBisectTestPack(t, "nothing")
+		_, err := NewManager(nil).Bisect(packDir, packwrap.BisectOptions{Timeout: 30 * time.Second})
+		if err == nil || !strings.Contains(err.Error(), "nothing to bisect") {
+			t.Errorf("Expected the pack to boot with every mod, got %v", err)
+		}
+	})
+}
+
+// writeBisectTestPack writes a deployed pack with the mods addon, broken, library
+// and other, where addon requires library. The stand-in server fails when
+// breaking is installed, when addon is installed without library, or when it
+// finds the world of an earlier boot.
+func writeBisectTestPack(t *testing.T, breaking string) string {
+	t.Helper()
+	packDir := t.TempDir()
+
+	script := fmt.Sprintf(`#!/bin/sh
+if [ -e world ]; then echo 'world of an earlier boot found'; exit 1; fi
+mkdir world
+if [ -f mods/%s.jar ]; then echo '[12:00:00] [main/ERROR]: %s failed to load'; exit 1; fi
+if [ -f mods/addon.jar ] && [ ! -f mods/library.jar ]; then echo 'addon requires library'; exit 1; fi
+echo '[12:00:01] [Server thread/INFO]: Done (1.000s)! For help, type "help"'
+while read line; do [ "$line" = stop ] && exit 0; done
+`, breaking, breaking)
+
+	files := map[string]string{
+		"pack.toml": `name = "Bisect Test"
+[index]
+file = "index.toml"
+hash-format = "sha256"
+hash = ""
+[versions]
+minecraft = "1.20.1"
+fabric = "0.15.0"
+`,
+		"packwrap.toml":        "[server]\njava = \"./fake-java.sh\"\n",
+		"fake-java.sh":         script,
+		".run/server.jar":      "",
+		".run/eula.txt":        "eula=true\n",
+		".run/world/level.dat": "",
+	}
+
+	index := "hash-format = \"sha256\"\n"
+	mods := map[string]string{"addon": "library", "broken": "", "library": "", "other": ""}
+	for mod, dependency := range mods {
+		index += fmt.Sprintf("\n[[files]]\nfile = \"mods/%s.pw.toml\"\nhash = \"\"\nmetafile = true\n", mod)
+		files["mods/"+mod+".pw.toml"] = fmt.Sprintf(`name = "%s"
+filename = "%s.jar"
+side = "both"
+[download]
+url = "https://example.com/%s.jar"
+hash-format = "sha256"
+hash = "1"
+`, mod, mod, mod)
+
+		metadata := fmt.Sprintf(`{"id": "%s", "depends": {"minecraft": "*"}}`, mod)
+		if dependency != "" {
+			metadata = fmt.Sprintf(`{"id": "%s", "depends": {"%s": "*"}}`, mod, dependency)
+		}
+		files[".run/mods/"+mod+".jar"] = string(writeTestJar(t, map[string][]byte{"fabric.mod.json": []byte(metadata)}))
+	}
+	files["index.toml"] = index
+
+	for file, content := range files {
+		path := filepath.Join(packDir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return packDir
+}
@@ -0,0 +1,318 @@
+package core
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/packwiz"
+	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
+)
+
+var (
+	// modIDPatterns find mod IDs in Fabric, Quilt, Forge and NeoForge errors and crash reports
+	modIDPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`'[^']*' \(([a-z0-9_.-]+)\)`),                   // Fabric and Quilt dependency errors
+		regexp.MustCompile(`ModID: ([a-z0-9_.-]+)`),                        // Forge mod construction errors
+		regexp.MustCompile(`(?i)\bmod ?id '([a-z0-9_.-]+)'`),               // Forge and NeoForge loading errors
+		regexp.MustCompile(`^\s*[^\s(][^(]* \(([a-z0-9_.-]+)\), Version:`), // "Suspected Mods" in crash reports
+		regexp.MustCompile(`Mixin \[[^\]]*\] from mod ([a-z0-9_.-]+)`),     // failed mixins on every loader
+		regexp.MustCompile(`^-- MOD ([a-z0-9_.-]+) --`),                    // Forge crash report sections
+		regexp.MustCompile(`^-- Mod loading issue for: ([a-z0-9_.-]+) --`), // NeoForge crash report sections
+	}
+
+	// jarPattern finds jar file names, e.g. in stack frames like "~[sodium-0.5.3.jar:?]"
+	jarPattern = regexp.MustCompile(`([A-Za-z0-9_.+-]+\.jar)`)
+
+	// jarBasePattern strips the version, and anything after it, from a jar file name
+	jarBasePattern = regexp.MustCompile(`^(.+?)[-_+ ](?:v|mc)?[0-9]`)
+)
+
+// platformModIDs are reported by loaders as mods but never come from the pack
+var platformModIDs = map[string]bool{
+	"minecraft":    true,
+	"java":         true,
+	"forge":        true,
+	"neoforge":     true,
+	"fabricloader": true,
+	"quilt_loader": true,
+	"mixin":        true,
+}
+
+// crashEvidence holds what a crash report or log blames, sorted and deduplicated
+type crashEvidence struct {
+	ModIDs []string
+	Jars   []string
+}
+
+// findCrashEvidence extracts the mod IDs and jar file names lines mention
+func findCrashEvidence(lines []string) crashEvidence {
+	ids, jars := map[string]bool{}, map[string]bool{}
+	for _, line := range lines {
+		for _, pattern := range modIDPatterns {
+			for _, match := range pattern.FindAllStringSubmatch(line, -1) {
+				ids[match[1]] = true
+			}
+		}
+		for _, jar := range jarPattern.FindAllString(line, -1) {
+			jars[jar] = true
+		}
+	}
+	return crashEvidence{ModIDs: sortedKeys(ids), Jars: sortedKeys(jars)}
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// readCrashReport reads a crash report up to its system details, which list
+// every installed mod and would implicate all of them
+func readCrashReport(path string) ([]string, error) {
+	lines, err := readTextLines(path)
+	if err != nil {
+		return nil, err
+	}
+	for i, line := range lines {
+		if strings.HasPrefix(line, "-- System Details --") {
+			return lines[:i], nil
+		}
+	}
+	return lines, nil
+}
+
+// logErrorBlocks keeps the ERROR and FATAL lines of a log together with the
+// lines continuing them, such as stack traces. Other lines, like the list of
+// loaded mods, would implicate every mod.
+func logErrorBlocks(lines []string) []string {
+	var blocks []string
+	inBlock := false
+	for _, line := range lines {
+		switch {
+		case serverErrorPattern.MatchString(line):
+			inBlock = true
+		case strings.HasPrefix(line, "["):
+			inBlock = false
+		}
+		if inBlock {
+			blocks = append(blocks, line)
+		}
+	}
+	return blocks
+}
+
+// readTextLines reads a text file line by line
+func readTextLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// CrashFile returns the newest crash report of the test server in packDir, or
+// its latest.log when the server ran again since, or never crashed
+func CrashFile(packDir string) (string, error) {
+	runDir := ServerRunDir(packDir)
+	report := latestCrashReport(runDir, time.Time{})
+
+	logPath := ServerLogPath(packDir)
+	if logInfo, err := os.Stat(logPath); err == nil {
+		reportInfo, err := os.Stat(report)
+		if report == "" || err != nil || logInfo.ModTime().After(reportInfo.ModTime()) {
+			return logPath, nil
+		}
+	}
+	if report != "" {
+		return report, nil
+	}
+	return "", fmt.Errorf("no crash report or log found in %s", runDir)
+}
+
+// AnalyzeCrash reads a crash report or log and maps the mod IDs and jars it
+// blames back to the pack's metafiles. An empty file analyzes CrashFile.
+func (m *Manager) AnalyzeCrash(packDir, file string) (*packwrap.CrashAnalysis, error) {
+	if file == "" {
+		var err error
+		if file, err = CrashFile(packDir); err != nil {
+			return nil, err
+		}
+	}
+
+	lines, err := readTextLines(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	analysis := &packwrap.CrashAnalysis{File: file}
+	for i, line := range lines {
+		if i < 5 && strings.Contains(line, "Minecraft Crash Report") {
+			analysis.CrashReport = true
+		}
+		if description, ok := strings.CutPrefix(line, "Description: "); ok && analysis.Description == "" {
+			analysis.Description = description
+		}
+	}
+	if analysis.CrashReport {
+		lines, err = readCrashReport(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+	} else {
+		lines = logErrorBlocks(lines)
+	}
+
+	packLocation := utils.FindPackToml(packDir)
+	if packLocation == "" {
+		return nil, errors.New("pack.toml not found")
+	}
+	data, err := packwiz.ReadPack(packLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	evidence := findCrashEvidence(lines)
+	suspects := map[int]*packwrap.CrashSuspect{}
+	blame := func(clue string, index int) {
+		if index < 0 {
+			if !platformModIDs[clue] {
+				analysis.Unmatched = append(analysis.Unmatched, clue)
+			}
+			return
+		}
+		if suspects[index] == nil {
+			suspects[index] = crashSuspect(data.Mods[index])
+		}
+		suspects[index].Evidence = append(suspects[index].Evidence, clue)
+	}
+	for _, id := range evidence.ModIDs {
+		blame(id, matchModID(data.Mods, id))
+	}
+	for _, jar := range evidence.Jars {
+		index := matchJar(data.Mods, jar)
+		// Only the pack's own jars are worth reporting; the rest are libraries
+		if index >= 0 {
+			blame(jar, index)
+		}
+	}
+
+	for _, suspect := range suspects {
+		analysis.Suspects = append(analysis.Suspects, *suspect)
+	}
+	sort.Slice(analysis.Suspects, func(i, j int) bool {
+		return analysis.Suspects[i].Metafile < analysis.Suspects[j].Metafile
+	})
+	return analysis, nil
+}
+
+// crashSuspect describes a metafile and where its mod comes from
+func crashSuspect(mod packwiz.ModToml) *packwrap.CrashSuspect {
+	suspect := &packwrap.CrashSuspect{
+		Metafile: metafilePath(mod),
+		Name:     mod.Name,
+		Filename: mod.Filename,
+		Source:   "url",
+	}
+
+	switch {
+	case mod.Update.Modrinth.ModID != "":
+		suspect.Source = "modrinth"
+		suspect.Project = mod.Update.Modrinth.ModID
+		suspect.Version = mod.Update.Modrinth.Version
+	case mod.Update.Curseforge.ProjectID != 0:
+		suspect.Source = "curseforge"
+		suspect.Project = strconv.Itoa(mod.Update.Curseforge.ProjectID)
+		suspect.Version = strconv.Itoa(mod.Update.Curseforge.FileID)
+	}
+	return suspect
+}
+
+// metafilePath returns the path of a mod's metafile, relative to the pack
+func metafilePath(mod packwiz.ModToml) string {
+	return path.Join(mod.Parse.Path, mod.Parse.ModID+".pw.toml")
+}
+
+// matchJar returns the index of the mod installed as jar, or -1
+func matchJar(mods []packwiz.ModToml, jar string) int {
+	for i, mod := range mods {
+		if mod.Filename == jar {
+			return i
+		}
+	}
+	return -1
+}
+
+// matchModID returns the index of the mod a loader's mod ID most likely
+// belongs to, or -1. Mod IDs are not recorded in metafiles, so the ID is
+// compared with the metafile name, the mod name and the jar name in turn.
+func matchModID(mods []packwiz.ModToml, id string) int {
+	want := normalizeModName(id)
+	if want == "" {
+		return -1
+	}
+
+	matchers := []func(packwiz.ModToml) string{
+		func(mod packwiz.ModToml) string { return mod.Parse.ModID },
+		func(mod packwiz.ModToml) string { return mod.Name },
+		func(mod packwiz.ModToml) string { return jarBaseName(mod.Filename) },
+	}
+	for _, name := range matchers {
+		for i, mod := range mods {
+			if normalizeModName(name(mod)) == want {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// jarBaseName strips the version and a trailing loader name from a jar file
+// name, e.g. "sodium-fabric-0.5.3+mc1.20.1.jar" becomes "sodium"
+func jarBaseName(filename string) string {
+	base := strings.TrimSuffix(filename, ".jar")
+	if match := jarBasePattern.FindStringSubmatch(base); match != nil {
+		base = match[1]
+	}
+	lower := strings.ToLower(base)
+	for _, loader := range []string{"neoforge", "forge", "fabric", "quilt"} {
+		for _, separator := range []string{"-", "_", "+"} {
+			lower = strings.TrimSuffix(lower, separator+loader)
+		}
+	}
+	return lower
+}
+
+// normalizeModName lowercases a name and drops everything but letters and digits
+func normalizeModName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		default:
+			return -1
+		}
+	}, name)
+}
@@ -0,0 +1,240 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeCrashTestPack(t *testing.T) string {
+	t.Helper()
+	packDir := t.TempDir()
+	files := map[string]string{
+		"pack.toml": `name = "Crash Test"
+[index]
+file = "index.toml"
+hash-format = "sha256"
+hash = ""
+[versions]
+minecraft = "1.20.1"
+fabric = "0.15.0"
+`,
+		"index.toml": `hash-format = "sha256"
+
+[[files]]
+file = "mods/fabric-api.pw.toml"
+hash = ""
+metafile = true
+
+[[files]]
+file = "mods/ferritecore.pw.toml"
+hash = ""
+metafile = true
+
+[[files]]
+file = "mods/lithium.pw.toml"
+hash = ""
+metafile = true
+
+[[files]]
+file = "mods/sodium.pw.toml"
+hash = ""
+metafile = true
+`,
+		"mods/fabric-api.pw.toml": `name = "Fabric API"
+filename = "fabric-api-0.92.0+1.20.1.jar"
+[download]
+url = "https://cdn.modrinth.com/fabric-api.jar"
+hash-format = "sha512"
+hash = "1"
+[update.modrinth]
+mod-id = "P7dR8mSH"
+version = "tFw0iWAk"
+`,
+		"mods/ferritecore.pw.toml": `name = "FerriteCore"
+filename = "ferritecore-6.0.1-fabric.jar"
+[download]
+hash-format = "sha1"
+hash = "1"
+mode = "metadata:curseforge"
+[update.curseforge]
+file-id = 4810975
+project-id = 429235
+`,
+		"mods/lithium.pw.toml": `name = "Lithium"
+filename = "lithium-fabric-mc1.20.1-0.11.2.jar"
+[download]
+url = "https://cdn.modrinth.com/lithium.jar"
+hash-format = "sha512"
+hash = "1"
+[update.modrinth]
+mod-id = "gvQqBUqZ"
+version = "ZSNsJrPI"
+`,
+		"mods/sodium.pw.toml": `name = "Sodium"
+filename = "sodium-fabric-0.5.3+mc1.20.1.jar"
+[download]
+url = "https://cdn.modrinth.com/sodium.jar"
+hash-format = "sha512"
+hash = "1"
+[update.modrinth]
+mod-id = "AANobbMI"
+version = "OihdIimA"
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(packDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return packDir
+}
+
+func TestAnalyzeCrashReport(t *testing.T) {
+	packDir := writeCrashTestPack(t)
+	report := filepath.Join(t.TempDir(), "crash-2024-01-01_12.00.00-server.txt")
+	content := `---- Minecraft Crash Report ----
+// Quite honestly, I wouldn't worry myself about that.
+
+Time: 2024-01-01 12:00:00
+Description: Exception in server tick loop
+
+org.spongepowered.asm.mixin.transformer.throwables.MixinTransformerError: An unexpected critical error was encountered
+Caused by: org.spongepowered.asm.mixin.throwables.MixinApplyError: Mixin [lithium.mixins.json:ai.pathing.MixinPathNode] from mod lithium failed injection check
+	at me.jellysquid.mods.lithium.Foo.bar(Foo.java:10) ~[ferritecore-6.0.1-fabric.jar:?]
+	at net.minecraft.server.MinecraftServer.run(MinecraftServer.java:1) ~[server-intermediary.jar:?]
+	Mod 'Unknown Thing' (unknownthing) 1.0 requires Minecraft
+
+-- System Details --
+	Fabric Mods: 
+		sodium: Sodium 0.5.3+mc1.20.1
+		fabric-api: Fabric API 0.92.0+1.20.1
+`
+	if err := os.WriteFile(report, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	analysis, err := NewManager(nil).AnalyzeCrash(packDir, report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !analysis.CrashReport || analysis.Description != "Exception in server tick loop" {
+		t.Errorf("Expected a crash report with its description, got %+v", analysis)
+	}
+
+	var metafiles []string
+	for _, suspect := range analysis.Suspects {
+		metafiles = append(metafiles, suspect.Metafile)
+	}
+	if got := strings.Join(metafiles, ","); got != "mods/ferritecore.pw.toml,mods/lithium.pw.toml" {
+		t.Fatalf("Expected FerriteCore and Lithium to be blamed, got %s", got)
+	}
+
+	ferritecore, lithium := analysis.Suspects[0], analysis.Suspects[1]
+	if ferritecore.Source != "curseforge" || ferritecore.Project != "429235" || ferritecore.Version != "4810975" {
+		t.Errorf("Unexpected CurseForge source: %+v", ferritecore)
+	}
+	if lithium.Source != "modrinth" || lithium.Project != "gvQqBUqZ" || lithium.Version != "ZSNsJrPI" {
+		t.Errorf("Unexpected Modrinth source: %+v", lithium)
+	}
+	if strings.Join(analysis.Unmatched, ",") != "unknownthing" {
+		t.Errorf("Expected unknownthing to be unmatched, got %v", analysis.Unmatched)
+	}
+}
+
+func TestAnalyzeCrashLog(t *testing.T) {
+	packDir := writeCrashTestPack(t)
+	logPath := ServerLogPath(packDir)
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := `[12:00:00] [main/INFO]: Loading 4 mods:
+	- fabric-api 0.92.0+1.20.1
+	- lithium 0.11.2
+	- sodium 0.5.3+mc1.20.1
+[12:00:01] [main/ERROR]: Incompatible mods found!
+net.fabricmc.loader.impl.FormattedException: Some of your mods are incompatible with the game or each other!
+	- Mod 'Sodium' (sodium) 0.5.3+mc1.20.1 requires version 0.93 or later of 'Fabric API' (fabric-api)
+[12:00:01] [main/INFO]: Shutting down
+`
+	if err := os.WriteFile(logPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := CrashFile(packDir)
+	if err != nil || file != logPath {
+		t.Fatalf("Expected latest.log without crash reports, got %s (%v)", file, err)
+	}
+
+	analysis, err := NewManager(nil).AnalyzeCrash(packDir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if analysis.CrashReport {
+		t.Error("Expected latest.log to be read as a log")
+	}
+
+	var metafiles []string
+	for _, suspect := range analysis.Suspects {
+		metafiles = append(metafiles, suspect.Metafile)
+	}
+	if got := strings.Join(metafiles, ","); got != "mods/fabric-api.pw.toml,mods/sodium.pw.toml" {
+		t.Errorf("Expected Fabric API and Sodium to be blamed, got %s", got)
+	}
+}
+
+func TestCrashFilePrefersNewestFile(t *testing.T) {
+	packDir := t.TempDir()
+	if _, err := CrashFile(packDir); err == nil {
+		t.Error("Expected an error without a crash report or log")
+	}
+
+	reportDir := filepath.Join(ServerRunDir(packDir), "crash-reports")
+	logPath := ServerLogPath(packDir)
+	for _, dir := range []string{reportDir, filepath.Dir(logPath)} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	report := filepath.Join(reportDir, "crash-server.txt")
+	for _, path := range []string{report, logPath} {
+		if err := os.WriteFile(path, []byte("x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The server crashed, writing the report after the log
+	now := time.Now()
+	os.Chtimes(logPath, now.Add(-time.Minute), now.Add(-time.Minute))
+	if file, _ := CrashFile(packDir); file != report {
+		t.Errorf("Expected the crash report, got %s", file)
+	}
+
+	// The server ran again since
+	os.Chtimes(logPath, now.Add(time.Minute), now.Add(time.Minute))
+	if file, _ := CrashFile(packDir); file != logPath {
+		t.Errorf("Expected latest.log, got %s", file)
+	}
+}
+
+func TestJarBaseName(t *testing.T) {
+	tests := map[string]string{
+		"sodium-fabric-0.5.3+mc1.20.1.jar":   "sodium",
+		"fabric-api-0.92.0+1.20.1.jar":       "fabric-api",
+		"ferritecore-6.0.1-fabric.jar":       "ferritecore",
+		"lithium-fabric-mc1.20.1-0.11.2.jar": "lithium",
+		"jei-1.20.1-forge-15.2.0.27.jar":     "jei",
+		"create.jar":                         "create",
+	}
+	for filename, expected := range tests {
+		if base := jarBaseName(filename); base != expected {
+			t.Errorf("jarBaseName(%s) = %s, expected %s", filename, base, expected)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+//go:build !windows
+
+package core
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// openServerConsole creates the named pipe a detached server reads its console
+// from and opens it for the server. Opening it read-write keeps the pipe open
+// between writers, so the server never sees the end of its input.
+func openServerConsole(path string) (*os.File, error) {
+	os.Remove(path)
+	if err := syscall.Mkfifo(path, 0600); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_RDWR, 0)
+}
+
+// writeServerConsole sends a command to a detached server's console
+func writeServerConsole(path, command string) error {
+	// Non-blocking, so a pipe nobody reads fails instead of hanging
+	pipe, err := os.OpenFile(path, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return err
+	}
+	defer pipe.Close()
+
+	_, err = io.WriteString(pipe, strings.TrimRight(command, "\r\n")+"\n")
+	return err
+}
+
+// detachProcess starts the server in its own session so it outlives the terminal
+func detachProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+// processAlive reports whether a process with the given PID exists
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// processStartTime identifies when the process with the given PID started, so a
+// recorded PID that was reused by another process can be told apart. It returns
+// "" when the start time cannot be determined.
+func processStartTime(pid int) string {
+	// Linux: the start time in clock ticks since boot is field 22 of /proc/<pid>/stat.
+	// The command name in field 2 may contain spaces, so count from its closing parenthesis.
+	if data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat"); err == nil {
+		if end := strings.LastIndexByte(string(data), ')'); end >= 0 {
+			if fields := strings.Fields(string(data[end+1:])); len(fields) > 19 {
+				return fields[19]
+			}
+		}
+		return ""
+	}
+
+	// Other systems have no /proc, but ps reports the start time everywhere
+	output, err := exec.Command("ps", "-o", "lstart=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -0,0 +1,67 @@
+//go:build windows
+
+package core
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// Windows process creation flags missing from the syscall package
+const (
+	detachedProcess = 0x00000008
+	stillActive     = 259
+)
+
+// openServerConsole returns no console on Windows, which has no named pipes in
+// the file system; detached servers are stopped by killing them instead
+func openServerConsole(path string) (*os.File, error) {
+	return nil, nil
+}
+
+// writeServerConsole always fails on Windows, see openServerConsole
+func writeServerConsole(path, command string) error {
+	return errors.New("console input to detached servers is not supported on Windows")
+}
+
+// detachProcess starts the server without a console so it outlives the terminal
+func detachProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess,
+	}
+}
+
+// processAlive reports whether a process with the given PID is still running
+func processAlive(pid int) bool {
+	handle, err := syscall.OpenProcess(syscall.PROCESS_QUERY_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer syscall.CloseHandle(handle)
+
+	var code uint32
+	if err := syscall.GetExitCodeProcess(handle, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
+
+// processStartTime identifies when the process with the given PID started, so a
+// recorded PID that was reused by another process can be told apart. It returns
+// "" when the start time cannot be determined.
+func processStartTime(pid int) string {
+	handle, err := syscall.OpenProcess(syscall.PROCESS_QUERY_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return ""
+	}
+	defer syscall.CloseHandle(handle)
+
+	var created, exited, kernel, user syscall.Filetime
+	if err := syscall.GetProcessTimes(handle, &created, &exited, &kernel, &user); err != nil {
+		return ""
+	}
+	return strconv.FormatInt(created.Nanoseconds(), 10)
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
+)
+
+// eventBus fans events out to subscribers, one event at a time
+type eventBus struct {
+	mu       sync.Mutex
+	handlers []subscription
+	nextID   int
+
+	dispatchMu sync.Mutex
+}
+
+type subscription struct {
+	id      int
+	handler packwrap.EventHandler
+}
+
+// Subscribe registers handler for events from every operation of the manager
+// and returns a function that removes it
+func (m *Manager) Subscribe(handler packwrap.EventHandler) (unsubscribe func()) {
+	bus := &m.events
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	id := bus.nextID
+	bus.nextID++
+	bus.handlers = append(bus.handlers, subscription{id: id, handler: handler})
+
+	return func() {
+		bus.mu.Lock()
+		defer bus.mu.Unlock()
+		for i, sub := range bus.handlers {
+			if sub.id == id {
+				bus.handlers = append(bus.handlers[:i:i], bus.handlers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// emit delivers event to every subscriber. Handlers are called in
+// subscription order and never concurrently, even from parallel batch workers.
+func (m *Manager) emit(event packwrap.Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	bus := &m.events
+	bus.mu.Lock()
+	handlers := bus.handlers
+	bus.mu.Unlock()
+
+	bus.dispatchMu.Lock()
+	defer bus.dispatchMu.Unlock()
+	for _, sub := range handlers {
+		sub.handler(event)
+	}
+}
+
+// stepFailed emits a StepFailed event for err and returns it unchanged
+func (m *Manager) stepFailed(operation, packDir, subject, message string, err error) error {
+	m.emit(packwrap.Event{
+		Type:      packwrap.EventStepFailed,
+		Operation: operation,
+		PackDir:   packDir,
+		Subject:   subject,
+		Message:   message,
+		Error:     err.Error(),
+	})
+	return err
+}
+
+// downloadProgress returns a byte counter callback that emits DownloadProgress
+// events whenever another percent of the file has arrived
+func (m *Manager) downloadProgress(operation, packDir, subject string) func(written, total int64) {
+	lastPercent := int64(-1)
+	return func(written, total int64) {
+		percent := int64(0)
+		if total > 0 {
+			percent = written * 100 / total
+		} else {
+			// Unknown size, report every megabyte instead
+			percent = written >> 20
+		}
+		if percent == lastPercent && written != total {
+			return
+		}
+		lastPercent = percent
+
+		m.emit(packwrap.Event{
+			Type:      packwrap.EventDownloadProgress,
+			Operation: operation,
+			PackDir:   packDir,
+			Subject:   subject,
+			Message:   fmt.Sprintf("Downloading %s", subject),
+			Current:   written,
+			Total:     total,
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
+)
+
+func TestSubscribeAndUnsubscribe(t *testing.T) {
+	manager := NewManager(nil)
+
+	var first, second []packwrap.EventType
+	unsubscribe := manager.Subscribe(func(event packwrap.Event) {
+		first = append(first, event.Type)
+		if event.Time.IsZero() {
+			t.Error("Expected events to be timestamped")
+		}
+	})
+	manager.Subscribe(func(event packwrap.Event) {
+		second = append(second, event.Type)
+	})
+
+	manager.emit(packwrap.Event{Type: packwrap.EventModAdded})
+	unsubscribe()
+	manager.emit(packwrap.Event{Type: packwrap.EventModRemoved})
+
+	if len(first) != 1 || first[0] != packwrap.EventModAdded {
+		t.Errorf("Unexpected events after unsubscribe: %v", first)
+	}
+	if len(second) != 2 {
+		t.Errorf("Expected 2 events for the remaining subscriber, got %v", second)
+	}
+}
+
+func TestDownloadProgressReportsEachPercent(t *testing.T) {
+	manager := NewManager(nil)
+
+	var events []packwrap.Event
+	manager.Subscribe(func(event packwrap.Event) {
+		events = append(events, event)
+	})
+
+	progress := manager.downloadProgress(packwrap.OperationServerSetup, "", "server.jar")
+	for written := int64(0); written <= 1000; written += 4 {
+		progress(written, 1000)
+	}
+
+	if len(events) != 101 {
+		t.Fatalf("Expected one event per percent, got %d", len(events))
+	}
+	if last := events[len(events)-1]; last.Current != 1000 || last.Total != 1000 {
+		t.Errorf("Expected the final event to report the full size, got %d/%d", last.Current, last.Total)
+	}
+}
+
+func TestBatchOperationEmitsEvents(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	dirs := []string{t.TempDir(), t.TempDir()}
+	manager := NewManager(nil)
+
+	counts := map[packwrap.EventType]int{}
+	manager.Subscribe(func(event packwrap.Event) {
+		if event.Operation != packwrap.OperationBatch {
+			t.Errorf("Unexpected operation %q", event.Operation)
+		}
+		counts[event.Type]++
+	})
+
+	manager.BatchOperation(context.Background(), dirs, packwrap.BatchOp{
+		Name: "arb",
+		Args: []string{"sh", "-c", `test "$(basename "$PWD")" = "$(basename "` + dirs[0] + `")"`},
+	})
+
+	if counts[packwrap.EventStepStarted] != 2 || counts[packwrap.EventStepFinished] != 1 || counts[packwrap.EventStepFailed] != 1 {
+		t.Errorf("Unexpected events: %v", counts)
+	}
+}
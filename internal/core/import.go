@@ -0,0 +1,243 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
+)
+
+// markdownLinkPattern matches modlist-style lines such as "- [Sodium](https://modrinth.com/mod/sodium)"
+var markdownLinkPattern = regexp.MustCompile(`^[-*+]?\s*\[([^\]]*)\]\(([^)\s]+)\)`)
+
+// ReadImportFile parses an import file into the mod entries it contains.
+//
+// The grammar is line based:
+//   - blank lines and lines starting with "#" are ignored
+//   - "URL", "URL NAME..." and "URL PATH NAME..." lines are imported, where PATH
+//     is recognised by containing a slash
+//   - "source:slug[:version]" references (mr, modrinth, cf, curseforge) are imported
+//   - markdown links ("- [Name](URL)") as written by 'pw modlist' are imported
+//   - anything else is treated as prose and skipped
+//
+// A trailing " #" starts an inline comment.
+func ReadImportFile(filename string) ([]packwrap.ImportLine, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open import file: %w", err)
+	}
+	defer file.Close()
+
+	return ParseImportLines(file)
+}
+
+// ParseImportLines parses import entries from a reader using the import file grammar
+func ParseImportLines(r io.Reader) ([]packwrap.ImportLine, error) {
+	var entries []packwrap.ImportLine
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		raw := scanner.Text()
+
+		entry, ok := parseImportLine(raw, false)
+		if !ok {
+			continue
+		}
+		entry.Number = lineNumber
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+
+	return entries, nil
+}
+
+// ParseImportArgs turns command line references into import entries.
+// Unlike import files, bare slugs are accepted here.
+func ParseImportArgs(args []string) []packwrap.ImportLine {
+	var entries []packwrap.ImportLine
+	for i, arg := range args {
+		entry, ok := parseImportLine(arg, true)
+		if !ok {
+			continue
+		}
+		entry.Number = i + 1
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// parseImportLine parses a single import line. When allowBare is false, lines
+// whose first field is not a URL or a prefixed mod reference are rejected.
+func parseImportLine(raw string, allowBare bool) (packwrap.ImportLine, bool) {
+	line := strings.TrimSpace(raw)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return packwrap.ImportLine{}, false
+	}
+
+	// Strip inline comments
+	if idx := strings.Index(line, " #"); idx >= 0 {
+		line = strings.TrimSpace(line[:idx])
+	}
+
+	// Markdown links written by 'pw modlist'
+	if match := markdownLinkPattern.FindStringSubmatch(line); match != nil {
+		if !isModReference(match[2]) {
+			return packwrap.ImportLine{}, false
+		}
+		return packwrap.ImportLine{
+			Raw:  raw,
+			URL:  match[2],
+			Name: strings.TrimSpace(match[1]),
+		}, true
+	}
+
+	parts := strings.Fields(line)
+	if !allowBare && !isModReference(parts[0]) {
+		return packwrap.ImportLine{}, false
+	}
+
+	entry := packwrap.ImportLine{Raw: raw, URL: parts[0]}
+	if len(parts) < 2 {
+		return entry, true
+	}
+
+	// Check if second part looks like a path (contains / or \)
+	if strings.Contains(parts[1], "/") || strings.Contains(parts[1], "\\") {
+		// Format: URL PATH [NAME...]
+		entry.Path = strings.ReplaceAll(parts[1], "\\", "/")
+		if len(parts) > 2 {
+			entry.Name = strings.Join(parts[2:], " ")
+		}
+	} else {
+		// Format: URL NAME
+		entry.Name = strings.Join(parts[1:], " ")
+	}
+
+	return entry, true
+}
+
+// isModReference reports whether s is a URL or a source-prefixed mod identifier
+func isModReference(s string) bool {
+	if strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") {
+		return true
+	}
+
+	source, _, found := strings.Cut(s, ":")
+	if !found {
+		return false
+	}
+	switch strings.ToLower(source) {
+	case "mr", "modrinth", "cf", "curseforge":
+		return true
+	}
+	return false
+}
+
+// ImportFromFile imports every mod referenced in an import file
+func (m *Manager) ImportFromFile(packDir string, filename string) (*packwrap.ImportResult, error) {
+	entries, err := ReadImportFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	result := m.ImportEntries(packDir, entries)
+	result.Source = filename
+	if failed := len(result.Failed()); failed > 0 {
+		return result, fmt.Errorf("%d of %d imports failed", failed, len(result.Lines))
+	}
+	return result, nil
+}
+
+// ImportEntries imports already parsed entries, recording the outcome of each one
+func (m *Manager) ImportEntries(packDir string, entries []packwrap.ImportLine) *packwrap.ImportResult {
+	result := &packwrap.ImportResult{}
+
+	for i, entry := range entries {
+		m.emit(packwrap.Event{
+			Type:      packwrap.EventStepStarted,
+			Operation: packwrap.OperationImport,
+			PackDir:   packDir,
+			Subject:   entry.URL,
+			Message:   fmt.Sprintf("Importing %s", entry.URL),
+			Current:   int64(i + 1),
+			Total:     int64(len(entries)),
+		})
+		if entry.Path != "" {
+			m.logger.Debug("Path: %s", entry.Path)
+		}
+		if entry.Name != "" {
+			m.logger.Debug("Name: %s", entry.Name)
+		}
+
+		if err := m.importEntry(packDir, entry); err != nil {
+			entry.Error = err.Error()
+			m.stepFailed(packwrap.OperationImport, packDir, entry.URL, "Failed to import "+entry.URL, err)
+		} else {
+			m.emit(packwrap.Event{
+				Type:      packwrap.EventModAdded,
+				Operation: packwrap.OperationImport,
+				PackDir:   packDir,
+				Subject:   entry.URL,
+				Message:   fmt.Sprintf("Imported %s", entry.URL),
+				Current:   int64(i + 1),
+				Total:     int64(len(entries)),
+			})
+		}
+		result.Lines = append(result.Lines, entry)
+	}
+
+	return result
+}
+
+// importEntry adds a single entry to the pack. Modrinth and CurseForge
+// references go through the smart add path, other URLs become URL metafiles.
+func (m *Manager) importEntry(packDir string, entry packwrap.ImportLine) error {
+	source, _, _ := m.parseModIdentifier(entry.URL)
+	isPlatformURL := strings.Contains(entry.URL, "modrinth.com") || strings.Contains(entry.URL, "curseforge.com")
+	if (source != "url" || isPlatformURL) && entry.Path != "" {
+		m.logger.Debug("Ignoring path %s for %s, only direct URLs support a metafile folder", entry.Path, entry.URL)
+	}
+
+	switch {
+	case source != "url":
+		return m.AddMod(packDir, entry.URL)
+	case strings.Contains(entry.URL, "modrinth.com"):
+		return executePackwizCommand(packDir, []string{"modrinth", "add", entry.URL})
+	case strings.Contains(entry.URL, "curseforge.com"):
+		return executePackwizCommand(packDir, []string{"curseforge", "add", entry.URL})
+	}
+
+	name := entry.Name
+	if name == "" {
+		name = nameFromURL(entry.URL)
+	}
+
+	args := []string{"url", "add", name, entry.URL}
+	if entry.Path != "" {
+		args = append(args, "--meta-folder", entry.Path)
+	}
+	return executePackwizCommand(packDir, args)
+}
+
+// nameFromURL derives a metafile name from the file name at the end of a URL
+func nameFromURL(rawURL string) string {
+	name := rawURL
+	if parsed, err := url.Parse(rawURL); err == nil {
+		name = path.Base(parsed.Path)
+	}
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	return strings.TrimSuffix(name, path.Ext(name))
+}
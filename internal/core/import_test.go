@@ -0,0 +1,81 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
+)
+
+func TestParseImportLines(t *testing.T) {
+	input := `as you can see any line that doesnt start with a URL
+gets ignored!
+
+# https://sketchysite.com/mods/iris
+https://www.curseforge.com/minecraft/mc-mods/sodium
+https://modrinth.com/mod/fabric-api Fabric API
+https://example.com/files/pack.zip resourcepacks/ Faithful Pack
+mr:cc-tweaked:Zoo9N9Dv # pinned version
+- [Lithium](https://modrinth.com/mod/lithium)
+## Client Mods
+`
+
+	entries, err := ParseImportLines(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseImportLines failed: %v", err)
+	}
+
+	expected := []packwrap.ImportLine{
+		{Number: 5, URL: "https://www.curseforge.com/minecraft/mc-mods/sodium"},
+		{Number: 6, URL: "https://modrinth.com/mod/fabric-api", Name: "Fabric API"},
+		{Number: 7, URL: "https://example.com/files/pack.zip", Path: "resourcepacks/", Name: "Faithful Pack"},
+		{Number: 8, URL: "mr:cc-tweaked:Zoo9N9Dv"},
+		{Number: 9, URL: "https://modrinth.com/mod/lithium", Name: "Lithium"},
+	}
+
+	if len(entries) != len(expected) {
+		t.Fatalf("Expected %d entries, got %d: %+v", len(expected), len(entries), entries)
+	}
+
+	for i, want := range expected {
+		got := entries[i]
+		if got.Number != want.Number || got.URL != want.URL || got.Path != want.Path || got.Name != want.Name {
+			t.Errorf("Entry %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+}
+
+func TestParseImportArgsAllowsBareSlugs(t *testing.T) {
+	entries := ParseImportArgs([]string{"sodium", "cf:jei"})
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+
+	if entries[0].URL != "sodium" {
+		t.Errorf("Expected bare slug 'sodium', got '%s'", entries[0].URL)
+	}
+}
+
+func TestImportResultSplit(t *testing.T) {
+	result := &packwrap.ImportResult{
+		Lines: []packwrap.ImportLine{
+			{Number: 1, URL: "mr:sodium"},
+			{Number: 2, URL: "mr:missing", Error: "not found"},
+		},
+	}
+
+	if len(result.Succeeded()) != 1 {
+		t.Errorf("Expected 1 succeeded line, got %d", len(result.Succeeded()))
+	}
+
+	failed := result.Failed()
+	if len(failed) != 1 || failed[0].Number != 2 {
+		t.Errorf("Expected line 2 to fail, got %+v", failed)
+	}
+}
+
+func TestNameFromURL(t *testing.T) {
+	if name := nameFromURL("https://example.com/files/My%20Pack.zip?raw=1"); name != "My Pack" {
+		t.Errorf("Expected 'My Pack', got '%s'", name)
+	}
+}
@@ -2,51 +2,44 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
 
+	"github.com/Merith-TK/packwiz-wrapper/internal/build"
 	"github.com/Merith-TK/packwiz-wrapper/internal/packwiz"
 	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
 	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
-	"github.com/pelletier/go-toml"
 )
 
-// executePackwizCommand runs a packwiz command using self-execution
-func executePackwizCommand(args []string) error {
-	// Find the pack directory
-	wd, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("failed to get working directory: %w", err)
-	}
-
-	packLocation := utils.FindPackToml(wd)
+// executePackwizCommand runs a packwiz command against the pack in packDir and returns its error.
+// packwiz runs inside the pack location with an explicit --pack-file, so the
+// caller's working directory is never touched and packs can be handled concurrently.
+func executePackwizCommand(packDir string, args []string) error {
+	packLocation := utils.FindPackToml(packDir)
 	if packLocation == "" {
-		return fmt.Errorf("pack.toml not found")
+		return fmt.Errorf("pack.toml not found in %s", packDir)
 	}
 
-	// Get current executable path
-	executable, err := os.Executable()
-	if err != nil {
-		return fmt.Errorf("failed to get executable path: %w", err)
+	// Commands like add and remove refresh the index, which must not pick up packwrap.toml
+	if _, err := packwiz.IgnoreWrapperConfig(packLocation); err != nil {
+		return err
 	}
 
-	// Execute self with packwiz arguments
-	cmd := exec.Command(executable, args...)
-	cmd.Dir = packLocation
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	return cmd.Run()
+	runner := &packwiz.Runner{Dir: packLocation, Stdout: os.Stdout, Stderr: os.Stderr}
+	_, err := runner.Run(context.Background(), utils.PackFileArgs(args, packLocation)...)
+	return err
 }
 
 // Manager implements the PackManager interface
 type Manager struct {
 	logger packwrap.Logger
+	events eventBus
 }
 
+var _ packwrap.PackManager = (*Manager)(nil)
+
 // NewManager creates a new core manager
 func NewManager(logger packwrap.Logger) *Manager {
 	if logger == nil {
@@ -64,30 +57,25 @@ func (m *Manager) GetPackInfo(packDir string) (*packwrap.PackInfo, error) {
 		return nil, fmt.Errorf("pack.toml not found in %s", packDir)
 	}
 
-	// Read pack.toml
-	packTomlPath := filepath.Join(packLocation, "pack.toml")
-	packFile, err := os.Open(packTomlPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open pack.toml: %w", err)
-	}
-	defer packFile.Close()
-
+	// A pack without a readable index still has info, just no mods
 	var packToml packwiz.PackToml
-	if err := toml.NewDecoder(packFile).Decode(&packToml); err != nil {
-		return nil, fmt.Errorf("failed to decode pack.toml: %w", err)
-	}
-
-	// Count mods
-	modCount, err := m.countMods(packLocation)
-	if err != nil {
+	modCount := 0
+	if pack, err := packwiz.ReadPack(packLocation); err == nil {
+		packToml = pack.Meta
+		modCount = len(pack.Mods)
+	} else {
 		m.logger.Warn("Failed to count mods: %v", err)
-		modCount = 0
+		if packToml, err = packwiz.ReadPackToml(packLocation); err != nil {
+			return nil, err
+		}
 	}
 
 	return &packwrap.PackInfo{
 		Name:        packToml.Name,
 		Author:      packToml.Author,
-		McVersion:   packToml.McVersion,
+		Version:     packToml.Version,
+		McVersion:   MinecraftVersion(&packToml),
+		Loaders:     packLoaders(&packToml),
 		PackFormat:  packToml.PackFormat,
 		Description: packToml.Description,
 		ModCount:    modCount,
@@ -95,20 +83,21 @@ func (m *Manager) GetPackInfo(packDir string) (*packwrap.PackInfo, error) {
 	}, nil
 }
 
-// RefreshPack refreshes the pack
+// RefreshPack refreshes the pack index, rehashing only files that changed
 func (m *Manager) RefreshPack(packDir string) error {
-	// Change to the pack directory
-	oldDir, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("failed to get working directory: %w", err)
+	packLocation := utils.FindPackToml(packDir)
+	if packLocation == "" {
+		return fmt.Errorf("pack.toml not found in %s", packDir)
 	}
-	defer os.Chdir(oldDir)
 
-	if err := os.Chdir(packDir); err != nil {
-		return fmt.Errorf("failed to change to pack directory: %w", err)
+	result, err := packwiz.Refresh(packLocation, packwiz.RefreshOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to refresh pack: %w", err)
 	}
 
-	return executePackwizCommand([]string{"refresh"})
+	m.logger.Info("Index refreshed: %d files (%d hashed, %d added, %d updated, %d removed)",
+		result.Files, result.Hashed, result.Added, result.Updated, result.Removed)
+	return nil
 }
 
 // ListMods lists all mods in the pack
@@ -118,40 +107,16 @@ func (m *Manager) ListMods(packDir string) ([]*packwrap.ModInfo, error) {
 		return nil, fmt.Errorf("pack.toml not found")
 	}
 
-	// Read index.toml
-	indexFile := filepath.Join(packLocation, "index.toml")
-	indexFileHandler, err := os.Open(indexFile)
+	pack, err := packwiz.ReadPack(packLocation)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open index.toml: %w", err)
+		return nil, err
 	}
-	defer indexFileHandler.Close()
-
-	var index packwiz.IndexToml
-	if err := toml.NewDecoder(indexFileHandler).Decode(&index); err != nil {
-		return nil, fmt.Errorf("failed to decode index.toml: %w", err)
+	for _, err := range pack.Errors {
+		m.logger.Warn("%v", err)
 	}
 
 	var mods []*packwrap.ModInfo
-	for _, file := range index.Files {
-		if !file.Metafile {
-			continue
-		}
-
-		modFilePath := filepath.Join(packLocation, file.File)
-		modFile, err := os.Open(modFilePath)
-		if err != nil {
-			m.logger.Warn("Failed to open %s: %v", file.File, err)
-			continue
-		}
-
-		var mod packwiz.ModToml
-		if err := toml.NewDecoder(modFile).Decode(&mod); err != nil {
-			m.logger.Warn("Failed to decode %s: %v", file.File, err)
-			modFile.Close()
-			continue
-		}
-		modFile.Close()
-
+	for _, mod := range pack.Mods {
 		// Determine platform
 		platform := "url"
 		if mod.Update.Modrinth.ModID != "" {
@@ -166,15 +131,30 @@ func (m *Manager) ListMods(packDir string) ([]*packwrap.ModInfo, error) {
 			version = mod.Update.Modrinth.Version
 		}
 
-		mods = append(mods, &packwrap.ModInfo{
-			ID:          strings.TrimSuffix(filepath.Base(modFilePath), ".pw.toml"),
+		info := &packwrap.ModInfo{
+			ID:          mod.Parse.ModID,
 			Name:        mod.Name,
 			Filename:    mod.Filename,
 			Side:        mod.Side,
 			DownloadURL: mod.Download.URL,
 			Version:     version,
 			Platform:    platform,
-		})
+			Folder:      mod.Parse.Path,
+			HashFormat:  mod.Download.HashFormat,
+			Hash:        mod.Download.Hash,
+
+			ModrinthID:          mod.Update.Modrinth.ModID,
+			CurseForgeProjectID: mod.Update.Curseforge.ProjectID,
+			CurseForgeFileID:    mod.Update.Curseforge.FileID,
+		}
+		if mod.Option != nil {
+			info.Option = &packwrap.ModOption{
+				Optional:    mod.Option.Optional,
+				Default:     mod.Option.Default,
+				Description: mod.Option.Description,
+			}
+		}
+		mods = append(mods, info)
 	}
 
 	return mods, nil
@@ -182,17 +162,6 @@ func (m *Manager) ListMods(packDir string) ([]*packwrap.ModInfo, error) {
 
 // AddMod adds a mod to the pack
 func (m *Manager) AddMod(packDir string, modRef string) error {
-	// Change to the pack directory
-	oldDir, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("failed to get working directory: %w", err)
-	}
-	defer os.Chdir(oldDir)
-
-	if err := os.Chdir(packDir); err != nil {
-		return fmt.Errorf("failed to change to pack directory: %w", err)
-	}
-
 	// Parse mod reference and build appropriate command
 	source, slug, version := m.parseModIdentifier(modRef)
 
@@ -213,159 +182,118 @@ func (m *Manager) AddMod(packDir string, modRef string) error {
 		args = []string{"add", modRef}
 	default:
 		// Try modrinth first, then curseforge
-		if err := executePackwizCommand([]string{"modrinth", "add", modRef}); err != nil {
-			return executePackwizCommand([]string{"curseforge", "add", modRef})
+		if err := executePackwizCommand(packDir, []string{"modrinth", "add", modRef}); err != nil {
+			return executePackwizCommand(packDir, []string{"curseforge", "add", modRef})
 		}
 		return nil
 	}
 
-	return executePackwizCommand(args)
+	return executePackwizCommand(packDir, args)
 }
 
 // RemoveMod removes a mod from the pack
 func (m *Manager) RemoveMod(packDir string, modID string) error {
-	// Change to the pack directory
-	oldDir, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("failed to get working directory: %w", err)
-	}
-	defer os.Chdir(oldDir)
-
-	if err := os.Chdir(packDir); err != nil {
-		return fmt.Errorf("failed to change to pack directory: %w", err)
-	}
-
-	return executePackwizCommand([]string{"remove", modID})
+	return executePackwizCommand(packDir, []string{"remove", modID})
 }
 
 // UpdateMod updates a mod in the pack
 func (m *Manager) UpdateMod(packDir string, modID string) error {
-	// Change to the pack directory
-	oldDir, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("failed to get working directory: %w", err)
-	}
-	defer os.Chdir(oldDir)
-
-	if err := os.Chdir(packDir); err != nil {
-		return fmt.Errorf("failed to change to pack directory: %w", err)
-	}
-
-	return executePackwizCommand([]string{"update", modID})
-}
-
-// ImportFromFile imports mods from a file
-func (m *Manager) ImportFromFile(packDir string, filename string) error {
-	// This would use the existing import logic from cmd_import.go
-	// For now, we'll use a simplified approach
-
-	file, err := os.Open(filename)
-	if err != nil {
-		return fmt.Errorf("failed to open import file: %w", err)
-	}
-	defer file.Close()
-
-	// Implementation would go here - simplified for now
-	m.logger.Info("Import from file functionality needs full implementation")
-	return fmt.Errorf("import from file not yet implemented in core")
+	return executePackwizCommand(packDir, []string{"update", modID})
 }
 
 // ImportFromURLs imports mods from URLs
 func (m *Manager) ImportFromURLs(packDir string, urls []string) error {
-	// Change to the pack directory
-	oldDir, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("failed to get working directory: %w", err)
+	entries := ParseImportArgs(urls)
+	if len(entries) == 0 {
+		return fmt.Errorf("no URLs provided")
 	}
-	defer os.Chdir(oldDir)
 
-	if err := os.Chdir(packDir); err != nil {
-		return fmt.Errorf("failed to change to pack directory: %w", err)
-	}
-
-	for _, url := range urls {
-		if err := executePackwizCommand([]string{"add", url}); err != nil {
-			m.logger.Error("Failed to import %s: %v", url, err)
-			return err
-		}
+	result := m.ImportEntries(packDir, entries)
+	if failed := len(result.Failed()); failed > 0 {
+		return fmt.Errorf("%d of %d imports failed", failed, len(result.Lines))
 	}
-
 	return nil
 }
 
-// ExportPack exports the pack to the specified format
-func (m *Manager) ExportPack(packDir string, format packwrap.ExportFormat) (string, error) {
-	// Change to the pack directory
-	oldDir, err := os.Getwd()
-	if err != nil {
-		return "", fmt.Errorf("failed to get working directory: %w", err)
-	}
-	defer os.Chdir(oldDir)
-
-	if err := os.Chdir(packDir); err != nil {
-		return "", fmt.Errorf("failed to change to pack directory: %w", err)
+// ExportPack exports the pack to the specified format and returns the absolute
+// paths of the artifacts. ExportAll builds every format, continuing past failures.
+func (m *Manager) ExportPack(packDir string, format packwrap.ExportFormat, options packwrap.ExportOptions) ([]string, error) {
+	buildOptions := build.Options{
+		PackName:     options.PackName,
+		Output:       options.Output,
+		NameTemplate: options.NameTemplate,
+		UseLocal:     options.MultiMCLocal,
+		BundleMods:   options.BundleMods,
 	}
 
-	switch format {
-	case packwrap.ExportCurseForge:
-		return "", executePackwizCommand([]string{"curseforge", "export"})
-	case packwrap.ExportModrinth:
-		return "", executePackwizCommand([]string{"modrinth", "export"})
-	default:
-		return "", fmt.Errorf("export format %s not yet implemented", format)
+	if format != packwrap.ExportAll {
+		artifact, err := m.exportFormat(packDir, string(format), buildOptions)
+		if err != nil {
+			return nil, err
+		}
+		return []string{artifact}, nil
 	}
-}
 
-// BatchOperation performs a batch operation across multiple directories
-func (m *Manager) BatchOperation(ctx context.Context, dirs []string, operation packwrap.BatchOp) error {
-	// Implementation would be extracted from cmd_batch.go
-	m.logger.Info("Batch operation functionality needs full implementation")
-	return fmt.Errorf("batch operations not yet implemented in core")
-}
-
-// StartTestServer starts a test server
-func (m *Manager) StartTestServer(packDir string) error {
-	// Implementation would be extracted from cmd_server.go
-	m.logger.Info("Start test server functionality needs full implementation")
-	return fmt.Errorf("test server not yet implemented in core")
-}
+	// Every format gets its default name, inside Output when one was given
+	buildOptions.Output = ""
+	buildOptions.OutputDir = options.Output
 
-// SetupServer sets up a test server
-func (m *Manager) SetupServer(packDir string) error {
-	// Implementation would be extracted from cmd_server.go
-	m.logger.Info("Setup server functionality needs full implementation")
-	return fmt.Errorf("server setup not yet implemented in core")
+	var artifacts []string
+	var errs []error
+	for _, name := range build.Formats {
+		artifact, err := m.exportFormat(packDir, name, buildOptions)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		artifacts = append(artifacts, artifact)
+	}
+	return artifacts, errors.Join(errs...)
 }
 
-// CleanServer cleans server files
-func (m *Manager) CleanServer(packDir string) error {
-	// Implementation would be extracted from cmd_server.go
-	m.logger.Info("Clean server functionality needs full implementation")
-	return fmt.Errorf("server cleanup not yet implemented in core")
+// exportFormat runs a single exporter and reports it through events
+func (m *Manager) exportFormat(packDir, format string, options build.Options) (string, error) {
+	m.emit(packwrap.Event{
+		Type:      packwrap.EventExportStarted,
+		Operation: packwrap.OperationExport,
+		PackDir:   packDir,
+		Subject:   format,
+		Message:   fmt.Sprintf("Exporting %s pack", format),
+	})
+
+	artifact, err := build.Export(packDir, format, options)
+	if err != nil {
+		err = fmt.Errorf("failed to export %s pack: %w", format, err)
+		return "", m.stepFailed(packwrap.OperationExport, packDir, format, fmt.Sprintf("Failed to export %s pack", format), err)
+	}
+
+	m.emit(packwrap.Event{
+		Type:      packwrap.EventExportFinished,
+		Operation: packwrap.OperationExport,
+		PackDir:   packDir,
+		Subject:   format,
+		Message:   fmt.Sprintf("Exported %s pack", format),
+		Path:      artifact,
+	})
+	return artifact, nil
 }
 
 // Helper methods
 
-func (m *Manager) countMods(packLocation string) (int, error) {
-	indexFile := filepath.Join(packLocation, "index.toml")
-	indexFileHandler, err := os.Open(indexFile)
-	if err != nil {
-		return 0, err
-	}
-	defer indexFileHandler.Close()
-
-	var index packwiz.IndexToml
-	if err := toml.NewDecoder(indexFileHandler).Decode(&index); err != nil {
-		return 0, err
-	}
-
-	count := 0
-	for _, file := range index.Files {
-		if file.Metafile {
-			count++
+// packLoaders returns the mod loaders pinned in the [versions] table of pack.toml
+func packLoaders(packToml *packwiz.PackToml) map[string]string {
+	loaders := map[string]string{}
+	for name, version := range map[string]string{
+		"fabric":   packToml.Versions.Fabric,
+		"forge":    packToml.Versions.Forge,
+		"neoforge": packToml.Versions.NeoForge,
+		"quilt":    packToml.Versions.Quilt,
+	} {
+		if version != "" {
+			loaders[name] = version
 		}
 	}
-	return count, nil
+	return loaders
 }
 
 func (m *Manager) parseModIdentifier(identifier string) (source, slug, version string) {
@@ -399,3 +327,26 @@ func (l *NoOpLogger) Info(msg string, args ...interface{})  {}
 func (l *NoOpLogger) Warn(msg string, args ...interface{})  {}
 func (l *NoOpLogger) Error(msg string, args ...interface{}) {}
 func (l *NoOpLogger) Debug(msg string, args ...interface{}) {}
+
+// ConsoleLogger writes log messages to the terminal for CLI commands
+type ConsoleLogger struct {
+	Verbose bool // show debug messages
+}
+
+func (l *ConsoleLogger) Info(msg string, args ...interface{}) {
+	fmt.Printf(msg+"\n", args...)
+}
+
+func (l *ConsoleLogger) Warn(msg string, args ...interface{}) {
+	fmt.Printf("Warning: "+msg+"\n", args...)
+}
+
+func (l *ConsoleLogger) Error(msg string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "ERROR: "+msg+"\n", args...)
+}
+
+func (l *ConsoleLogger) Debug(msg string, args ...interface{}) {
+	if l.Verbose {
+		fmt.Printf("[debug] "+msg+"\n", args...)
+	}
+}
@@ -76,3 +76,89 @@ func TestNoOpLoggerBasic(t *testing.T) {
 	logger.Error("test")
 	logger.Debug("test")
 }
+
+func TestManagerPackAndModDetails(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"pack.toml": `name = "Test"
+version = "1.2.0"
+pack-format = "packwiz:1.1.0"
+
+[index]
+file = "index.toml"
+hash-format = "sha256"
+hash = ""
+
+[versions]
+minecraft = "1.20.1"
+fabric = "0.15.0"
+`,
+		"index.toml": `hash-format = "sha256"
+
+[[files]]
+file = "shaderpacks/complementary.pw.toml"
+hash = "1"
+metafile = true
+`,
+		"shaderpacks/complementary.pw.toml": `name = "Complementary Shaders"
+filename = "complementary.zip"
+side = "client"
+
+[download]
+hash-format = "sha1"
+hash = "abc"
+mode = "metadata:curseforge"
+
+[option]
+optional = true
+description = "Pretty lighting"
+
+[update.curseforge]
+file-id = 456
+project-id = 123
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manager := NewManager(nil)
+	packInfo, err := manager.GetPackInfo(tmpDir)
+	if err != nil {
+		t.Fatalf("GetPackInfo failed: %v", err)
+	}
+	if packInfo.Version != "1.2.0" || packInfo.McVersion != "1.20.1" || packInfo.ModCount != 1 {
+		t.Errorf("unexpected pack info: %+v", packInfo)
+	}
+	if len(packInfo.Loaders) != 1 || packInfo.Loaders["fabric"] != "0.15.0" {
+		t.Errorf("unexpected loaders: %v", packInfo.Loaders)
+	}
+
+	mods, err := manager.ListMods(tmpDir)
+	if err != nil {
+		t.Fatalf("ListMods failed: %v", err)
+	}
+	if len(mods) != 1 {
+		t.Fatalf("expected 1 mod, got %d", len(mods))
+	}
+
+	mod := mods[0]
+	if mod.ID != "complementary" || mod.Folder != "shaderpacks" || mod.Platform != "curseforge" {
+		t.Errorf("unexpected mod: %+v", mod)
+	}
+	if mod.CurseForgeProjectID != 123 || mod.CurseForgeFileID != 456 {
+		t.Errorf("unexpected CurseForge IDs: %d/%d", mod.CurseForgeProjectID, mod.CurseForgeFileID)
+	}
+	if mod.HashFormat != "sha1" || mod.Hash != "abc" {
+		t.Errorf("unexpected hash: %s:%s", mod.HashFormat, mod.Hash)
+	}
+	if mod.Option == nil || !mod.Option.Optional || mod.Option.Default || mod.Option.Description != "Pretty lighting" {
+		t.Errorf("unexpected option: %+v", mod.Option)
+	}
+}
@@ -0,0 +1,203 @@
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// maxNestedJarDepth limits how deep jar-in-jar mods are read
+const maxNestedJarDepth = 2
+
+// modJarInfo is what a mod jar declares about itself in its loader metadata
+type modJarInfo struct {
+	IDs      []string // mod IDs the jar provides, including its jar-in-jar mods
+	Requires []string // mod IDs the jar refuses to load without
+}
+
+// fabricModJSON is the part of fabric.mod.json that names mods and their dependencies
+type fabricModJSON struct {
+	ID       string                     `json:"id"`
+	Provides []string                   `json:"provides"`
+	Depends  map[string]json.RawMessage `json:"depends"`
+	Jars     []struct {
+		File string `json:"file"`
+	} `json:"jars"`
+}
+
+// quiltModJSON is the part of quilt.mod.json that names mods and their dependencies
+type quiltModJSON struct {
+	Loader struct {
+		ID       string            `json:"id"`
+		Provides []json.RawMessage `json:"provides"`
+		Depends  []json.RawMessage `json:"depends"`
+		Jars     []string          `json:"jars"`
+	} `json:"quilt_loader"`
+}
+
+// quiltReference is a provides or depends entry of quilt.mod.json in object form
+type quiltReference struct {
+	ID       string `json:"id"`
+	Optional bool   `json:"optional"`
+}
+
+// forgeModsToml is the part of Forge's mods.toml and NeoForge's neoforge.mods.toml
+// that names mods and their dependencies
+type forgeModsToml struct {
+	Mods []struct {
+		ModID string `toml:"modId"`
+	} `toml:"mods"`
+	Dependencies map[string][]struct {
+		ModID     string `toml:"modId"`
+		Mandatory bool   `toml:"mandatory"` // Forge
+		Type      string `toml:"type"`      // NeoForge: required, optional, incompatible or discouraged
+	} `toml:"dependencies"`
+}
+
+// readModJar reads the Fabric, Quilt, Forge and NeoForge metadata of a mod jar.
+// Jars without metadata, such as plain libraries, declare nothing.
+func readModJar(path string) (modJarInfo, error) {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return modJarInfo{}, err
+	}
+	defer reader.Close()
+
+	info := modJarInfo{}
+	readModJarFiles(&reader.Reader, &info, 0)
+	info.IDs = uniqueStrings(info.IDs)
+	info.Requires = uniqueStrings(info.Requires)
+	return info, nil
+}
+
+// readModJarFiles adds the metadata of an opened jar, and of the jars nested in it, to info
+func readModJarFiles(jar *zip.Reader, info *modJarInfo, depth int) {
+	var nested []string
+
+	if data := readZipFile(jar, "fabric.mod.json"); data != nil {
+		var mod fabricModJSON
+		if json.Unmarshal(data, &mod) == nil {
+			info.IDs = append(append(info.IDs, mod.ID), mod.Provides...)
+			for id := range mod.Depends {
+				info.Requires = append(info.Requires, id)
+			}
+			for _, nestedJar := range mod.Jars {
+				nested = append(nested, nestedJar.File)
+			}
+		}
+	}
+
+	if data := readZipFile(jar, "quilt.mod.json"); data != nil {
+		var mod quiltModJSON
+		if json.Unmarshal(data, &mod) == nil {
+			info.IDs = append(info.IDs, mod.Loader.ID)
+			for _, provided := range mod.Loader.Provides {
+				if id, _ := quiltReferenceID(provided); id != "" {
+					info.IDs = append(info.IDs, id)
+				}
+			}
+			for _, dependency := range mod.Loader.Depends {
+				if id, optional := quiltReferenceID(dependency); id != "" && !optional {
+					info.Requires = append(info.Requires, id)
+				}
+			}
+			nested = append(nested, mod.Loader.Jars...)
+		}
+	}
+
+	for _, name := range []string{"META-INF/mods.toml", "META-INF/neoforge.mods.toml"} {
+		data := readZipFile(jar, name)
+		if data == nil {
+			continue
+		}
+		var mods forgeModsToml
+		if _, err := toml.Decode(string(data), &mods); err != nil {
+			continue
+		}
+
+		// NeoForge dependencies are required unless their type says otherwise
+		neoForge := name == "META-INF/neoforge.mods.toml"
+		for _, mod := range mods.Mods {
+			info.IDs = append(info.IDs, mod.ModID)
+		}
+		for _, dependencies := range mods.Dependencies {
+			for _, dependency := range dependencies {
+				required := dependency.Mandatory || strings.EqualFold(dependency.Type, "required") ||
+					(neoForge && dependency.Type == "")
+				if required {
+					info.Requires = append(info.Requires, dependency.ModID)
+				}
+			}
+		}
+	}
+
+	// Forge and NeoForge list jar-in-jar mods separately
+	if data := readZipFile(jar, "META-INF/jarjar/metadata.json"); data != nil {
+		var metadata struct {
+			Jars []struct {
+				Path string `json:"path"`
+			} `json:"jars"`
+		}
+		if json.Unmarshal(data, &metadata) == nil {
+			for _, nestedJar := range metadata.Jars {
+				nested = append(nested, nestedJar.Path)
+			}
+		}
+	}
+
+	if depth >= maxNestedJarDepth {
+		return
+	}
+	for _, name := range nested {
+		data := readZipFile(jar, name)
+		if data == nil {
+			continue
+		}
+		if nestedJar, err := zip.NewReader(bytes.NewReader(data), int64(len(data))); err == nil {
+			readModJarFiles(nestedJar, info, depth+1)
+		}
+	}
+}
+
+// quiltReferenceID returns the mod ID of a quilt.mod.json reference, which is
+// either a string or an object
+func quiltReferenceID(raw json.RawMessage) (id string, optional bool) {
+	if json.Unmarshal(raw, &id) == nil {
+		return id, false
+	}
+	var reference quiltReference
+	if json.Unmarshal(raw, &reference) != nil {
+		return "", false
+	}
+	return reference.ID, reference.Optional
+}
+
+// readZipFile returns the content of a file in a zip, or nil when it is missing or unreadable
+func readZipFile(archive *zip.Reader, name string) []byte {
+	file, err := archive.Open(strings.TrimPrefix(name, "/"))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// uniqueStrings sorts values and drops duplicates and empty strings
+func uniqueStrings(values []string) []string {
+	set := map[string]bool{}
+	for _, value := range values {
+		if value != "" {
+			set[value] = true
+		}
+	}
+	return sortedKeys(set)
+}
@@ -0,0 +1,141 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/packwiz"
+	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
+)
+
+// ReinstallMods refreshes the pack, then removes and re-adds every mod so packwiz
+// regenerates its metafiles. With keepVersions the current Modrinth version or
+// CurseForge file is kept, otherwise the latest compatible version is installed.
+func (m *Manager) ReinstallMods(packDir string, keepVersions bool) error {
+	packLocation := utils.FindPackToml(packDir)
+	if packLocation == "" {
+		return fmt.Errorf("pack.toml not found in %s", packDir)
+	}
+
+	const totalSteps = 3
+	step := func(current int, message string) {
+		m.emit(packwrap.Event{
+			Type:      packwrap.EventStepStarted,
+			Operation: packwrap.OperationReinstall,
+			PackDir:   packDir,
+			Subject:   "pack",
+			Message:   message,
+			Current:   int64(current),
+			Total:     totalSteps,
+		})
+	}
+
+	step(1, "Refreshing pack")
+	if err := m.RefreshPack(packLocation); err != nil {
+		return m.stepFailed(packwrap.OperationReinstall, packDir, "pack", "Failed to refresh pack", err)
+	}
+
+	pack, err := packwiz.ReadPack(packLocation)
+	if err != nil {
+		return m.stepFailed(packwrap.OperationReinstall, packDir, "pack", "Failed to read mod metadata", err)
+	}
+	for _, err := range pack.Errors {
+		m.stepFailed(packwrap.OperationReinstall, packDir, "pack", "Skipping unreadable metafile", err)
+	}
+
+	mods := pack.Mods
+	if len(mods) == 0 {
+		m.logger.Info("No mods found to reinstall")
+		return nil
+	}
+
+	// Remove all mods first
+	step(2, fmt.Sprintf("Removing %d mod(s)", len(mods)))
+	for i, mod := range mods {
+		if err := executePackwizCommand(packLocation, []string{"remove", mod.Parse.ModID}); err != nil {
+			m.stepFailed(packwrap.OperationReinstall, packDir, mod.Parse.ModID, "Failed to remove "+mod.Name, err)
+			continue
+		}
+		m.emit(packwrap.Event{
+			Type:      packwrap.EventModRemoved,
+			Operation: packwrap.OperationReinstall,
+			PackDir:   packDir,
+			Subject:   mod.Parse.ModID,
+			Message:   fmt.Sprintf("Removed %s", mod.Name),
+			Current:   int64(i + 1),
+			Total:     int64(len(mods)),
+		})
+	}
+
+	// Re-add all mods
+	step(3, fmt.Sprintf("Re-adding %d mod(s)", len(mods)))
+	failed := 0
+	for i, mod := range mods {
+		name := mod.Name
+		if version := reinstallVersion(mod); keepVersions && version != "" {
+			name = fmt.Sprintf("%s (v%s)", mod.Name, version)
+		}
+
+		if err := executePackwizCommand(packLocation, reinstallArgs(mod, keepVersions)); err != nil {
+			failed++
+			m.stepFailed(packwrap.OperationReinstall, packDir, mod.Parse.ModID, "Failed to reinstall "+name, err)
+			continue
+		}
+		m.emit(packwrap.Event{
+			Type:      packwrap.EventModAdded,
+			Operation: packwrap.OperationReinstall,
+			PackDir:   packDir,
+			Subject:   mod.Parse.ModID,
+			Message:   fmt.Sprintf("Reinstalled %s", name),
+			Current:   int64(i + 1),
+			Total:     int64(len(mods)),
+		})
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d mod(s) could not be reinstalled", failed, len(mods))
+	}
+	return nil
+}
+
+// reinstallArgs builds the packwiz command that adds mod back to the pack
+func reinstallArgs(mod packwiz.ModToml, keepVersions bool) []string {
+	var arguments []string
+
+	if mod.Update.Modrinth.ModID != "" {
+		// Modrinth mod
+		arguments = append(arguments, "mr", "add", "--project-id", mod.Update.Modrinth.ModID)
+		if keepVersions && mod.Update.Modrinth.Version != "" {
+			arguments = append(arguments, "--version-id", mod.Update.Modrinth.Version)
+		}
+	} else if mod.Update.Curseforge.ProjectID != 0 {
+		// CurseForge mod
+		arguments = append(arguments, "cf", "add", "--addon-id", fmt.Sprint(mod.Update.Curseforge.ProjectID))
+		if keepVersions && mod.Update.Curseforge.FileID != 0 {
+			arguments = append(arguments, "--file-id", fmt.Sprint(mod.Update.Curseforge.FileID))
+		}
+	} else {
+		// URL mod
+		arguments = append(arguments, "url", "add", mod.Parse.ModID, mod.Download.URL)
+		if mod.Parse.Path != "" {
+			arguments = append(arguments, "--meta-folder", mod.Parse.Path)
+		}
+	}
+
+	return arguments
+}
+
+// reinstallVersion describes the version a mod is pinned to, if any
+func reinstallVersion(mod packwiz.ModToml) string {
+	// Try to get version from update sources
+	if mod.Update.Modrinth.Version != "" {
+		return mod.Update.Modrinth.Version
+	}
+
+	// For CurseForge, we could try to extract from filename or other metadata
+	if mod.Update.Curseforge.FileID != 0 {
+		return fmt.Sprintf("CF:%d", mod.Update.Curseforge.FileID)
+	}
+
+	return ""
+}
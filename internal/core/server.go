@@ -0,0 +1,568 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/build"
+	"github.com/Merith-TK/packwiz-wrapper/internal/config"
+	"github.com/Merith-TK/packwiz-wrapper/internal/packwiz"
+	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
+)
+
+// ErrServerNotSetUp is returned when the run directory holds no launchable server
+var ErrServerNotSetUp = errors.New("server not properly set up")
+
+// ServerRunDir returns the directory the test server for packDir is deployed to
+func ServerRunDir(packDir string) string {
+	return filepath.Join(packDir, ".run")
+}
+
+// MinecraftVersion returns the Minecraft version a pack targets,
+// preferring versions.minecraft over the legacy mc-version field
+func MinecraftVersion(packToml *packwiz.PackToml) string {
+	return packwiz.MinecraftVersion(packToml)
+}
+
+// VerifyServerSetup checks that the files needed to start the server are present
+func VerifyServerSetup(runDir string) error {
+	// Check that the server can be launched
+	if _, err := ServerLaunchArgs(runDir); err != nil {
+		return err
+	}
+
+	// Check if eula.txt exists
+	eulaPath := filepath.Join(runDir, "eula.txt")
+	if _, err := os.Stat(eulaPath); os.IsNotExist(err) {
+		return fmt.Errorf("eula.txt not found")
+	}
+
+	return nil
+}
+
+// SetupServer downloads and deploys all server files into the pack's .run directory.
+// progress is optional and is called once per setup step, alongside the StepStarted event.
+func (m *Manager) SetupServer(packDir string, progress packwrap.ProgressCallback) error {
+	const totalSteps = 4
+	step := func(current int, message string) {
+		m.emit(packwrap.Event{
+			Type:      packwrap.EventStepStarted,
+			Operation: packwrap.OperationServerSetup,
+			PackDir:   packDir,
+			Subject:   "server",
+			Message:   message,
+			Current:   int64(current),
+			Total:     totalSteps,
+		})
+		if progress != nil {
+			progress(current, totalSteps, message)
+		}
+	}
+	fail := func(message string, err error) error {
+		err = fmt.Errorf("%s: %w", message, err)
+		return m.stepFailed(packwrap.OperationServerSetup, packDir, "server", "Server setup failed", err)
+	}
+
+	// Find and parse pack.toml
+	packToml, packLocation, err := packwiz.LoadPackConfig(packDir)
+	if err != nil {
+		return fail("failed to load pack configuration", err)
+	}
+
+	// Determine Minecraft version
+	mcVersion := MinecraftVersion(packToml)
+	if mcVersion == "" {
+		return m.stepFailed(packwrap.OperationServerSetup, packDir, "server", "Server setup failed",
+			fmt.Errorf("could not determine Minecraft version from pack.toml"))
+	}
+
+	m.logger.Info("Setting up server for Minecraft %s...", mcVersion)
+
+	// Ensure Java is available (download if necessary)
+	step(1, "Checking Java installation...")
+	java, err := utils.EnsureJavaWithProgress(mcVersion, func(message string) {
+		m.logger.Info("  %s", message)
+	})
+	if err != nil {
+		// Continue anyway - user might have Java in PATH
+		m.logger.Warn("Java setup: %v", err)
+		m.logger.Warn("Server may not start correctly without compatible Java")
+	} else {
+		m.logger.Info("Using Java %s (version %d)", java.Version, java.Major)
+	}
+
+	// Create server run directory
+	runDir := ServerRunDir(packDir)
+	if err := os.MkdirAll(runDir, 0755); err != nil {
+		return fail("failed to create .run directory", err)
+	}
+
+	// Create server configuration files
+	step(2, "Creating server configuration...")
+	if err := m.createServerConfig(runDir, packLocation); err != nil {
+		return fail("failed to create server configuration", err)
+	}
+
+	// Install mods
+	step(3, "Installing mods...")
+	if err := m.installServerMods(runDir, packLocation); err != nil {
+		return fail("failed to install mods", err)
+	}
+
+	// Download appropriate server JAR
+	step(4, "Downloading server JAR...")
+	if err := m.downloadServerJar(packDir, runDir, packToml, mcVersion); err != nil {
+		return fail("failed to download server JAR", err)
+	}
+
+	m.emit(packwrap.Event{
+		Type:      packwrap.EventStepFinished,
+		Operation: packwrap.OperationServerSetup,
+		PackDir:   packDir,
+		Subject:   "server",
+		Message:   "Server setup completed successfully",
+		Current:   totalSteps,
+		Total:     totalSteps,
+	})
+	return nil
+}
+
+// StartTestServer launches the deployed server and returns a handle to it.
+// The server keeps running until it exits on its own or the handle is stopped.
+func (m *Manager) StartTestServer(packDir string) (packwrap.ServerProcess, error) {
+	if err := m.checkServerStopped(packDir); err != nil {
+		return nil, err
+	}
+	return m.startServer(packDir, ServerRunDir(packDir))
+}
+
+// startServer launches the server deployed in runDir with the pack's settings
+func (m *Manager) startServer(packDir, runDir string) (packwrap.ServerProcess, error) {
+	cmd, err := m.serverCommand(packDir, runDir)
+	if err != nil {
+		return nil, err
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open server input: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open server output: %w", err)
+	}
+	cmd.Stderr = cmd.Stdout
+
+	m.logger.Info("🚀 Starting Minecraft server...")
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start server: %w", err)
+	}
+
+	process := &serverProcess{
+		cmd:    cmd,
+		stdin:  stdin,
+		output: make(chan string, 256),
+		done:   make(chan struct{}),
+	}
+	go process.run(stdout)
+
+	return process, nil
+}
+
+// checkServerStopped refuses to start a server while the detached one is running
+// or its supervisor is about to restart it
+func (m *Manager) checkServerStopped(packDir string) error {
+	status, err := m.ServerStatus(packDir)
+	if err != nil {
+		return nil
+	}
+	if status.Running {
+		return fmt.Errorf("server is already running (PID %d)", status.PID)
+	}
+	if status.Supervisor != nil && status.Supervisor.Running {
+		return fmt.Errorf("server is supervised by PID %d, which is about to restart it", status.Supervisor.PID)
+	}
+	return nil
+}
+
+// serverCommand builds the command that runs the server deployed in runDir,
+// refusing when the server is missing
+func (m *Manager) serverCommand(packDir, runDir string) (*exec.Cmd, error) {
+	// Verify server is set up
+	if err := VerifyServerSetup(runDir); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrServerNotSetUp, err)
+	}
+
+	launchArgs, err := ServerLaunchArgs(runDir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrServerNotSetUp, err)
+	}
+
+	// Pick up changes to the [server] section since setup
+	serverCfg, err := serverConfig(packDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyServerProperties(runDir, serverCfg); err != nil {
+		return nil, err
+	}
+
+	javaCmd := m.serverJava(packDir)
+
+	// Start server with the configured memory and JVM flags
+	args := append(serverCfg.JavaArgs(), launchArgs...)
+	cmd := exec.Command(javaCmd, append(args, "nogui")...)
+	cmd.Dir = runDir
+	return cmd, nil
+}
+
+// serverConfig loads the [server] section of the pack's packwrap.toml
+func serverConfig(packDir string) (config.Server, error) {
+	packLocation := utils.FindPackToml(packDir)
+	if packLocation == "" {
+		return config.Server{}, nil
+	}
+
+	cfg, err := config.Load(packLocation)
+	if err != nil {
+		return config.Server{}, err
+	}
+	return cfg.Server, nil
+}
+
+// applyServerProperties writes the server.properties keys set in the config,
+// keeping every other key the server wrote
+func applyServerProperties(runDir string, serverCfg config.Server) error {
+	properties := serverCfg.ServerProperties()
+	if len(properties) == 0 {
+		return nil
+	}
+	return utils.UpdateProperties(filepath.Join(runDir, "server.properties"), properties)
+}
+
+// CleanServer removes all deployed server files
+func (m *Manager) CleanServer(packDir string) error {
+	runDir := ServerRunDir(packDir)
+
+	if err := m.checkServerStopped(packDir); err != nil {
+		return fmt.Errorf("%w, stop it with 'pw server stop' first", err)
+	}
+
+	if _, err := os.Stat(runDir); os.IsNotExist(err) {
+		m.logger.Info("ℹ️  No server directory found")
+		return nil
+	}
+
+	m.logger.Info("🗑️  Deleting server files...")
+	if err := os.RemoveAll(runDir); err != nil {
+		return fmt.Errorf("failed to delete server directory: %w", err)
+	}
+
+	m.logger.Info("✅ Server files deleted")
+	return nil
+}
+
+// serverJava picks a Java executable compatible with the pack, falling back to java on PATH.
+// The java setting in the [server] section of packwrap.toml takes precedence.
+func (m *Manager) serverJava(packDir string) string {
+	packToml, packLocation, err := packwiz.LoadPackConfig(packDir)
+	if err != nil {
+		m.logger.Warn("could not load pack config: %v", err)
+		return "java"
+	}
+
+	if cfg, err := config.Load(packLocation); err == nil && cfg.Server.Java != "" {
+		java := cfg.Server.Java
+		if !filepath.IsAbs(java) && strings.ContainsAny(java, `/\`) {
+			java = filepath.Join(packLocation, java)
+		}
+		m.logger.Info("Using Java %s from %s", java, config.FileName)
+		return java
+	}
+
+	mcVersion := MinecraftVersion(packToml)
+	java, err := utils.FindCompatibleJava(mcVersion)
+	if err != nil {
+		m.logger.Warn("Java: %v", err)
+		return "java"
+	}
+
+	m.logger.Info("Using Java %s for Minecraft %s", java.Version, mcVersion)
+	return java.Path
+}
+
+func (m *Manager) createServerConfig(runDir, packLocation string) error {
+	// Create eula.txt
+	eulaPath := filepath.Join(runDir, "eula.txt")
+	if err := os.WriteFile(eulaPath, []byte("eula=true\n"), 0644); err != nil {
+		return fmt.Errorf("failed to create eula.txt: %w", err)
+	}
+	m.logger.Info("✅ Created eula.txt")
+
+	// Copy server icon if available
+	iconSrc := filepath.Join(packLocation, "icon.png")
+	if _, err := os.Stat(iconSrc); err == nil {
+		iconDst := filepath.Join(runDir, "server-icon.png")
+		if err := utils.CopyFile(iconSrc, iconDst); err != nil {
+			m.logger.Warn("failed to copy server icon: %v", err)
+		} else {
+			m.logger.Info("✅ Copied server icon")
+		}
+	}
+
+	// Apply the [server] section of packwrap.toml
+	cfg, err := config.Load(packLocation)
+	if err != nil {
+		return err
+	}
+	if err := applyServerProperties(runDir, cfg.Server); err != nil {
+		return err
+	}
+	if len(cfg.Server.ServerProperties()) > 0 {
+		m.logger.Info("✅ Applied server.properties from %s", config.FileName)
+	}
+
+	return nil
+}
+
+// installServerMods installs the pack's server side files into runDir with
+// the native installer, so no Java is needed before the server starts
+func (m *Manager) installServerMods(runDir, packLocation string) error {
+	installer := &build.Installer{
+		Side:  build.SideServer,
+		Cache: utils.DefaultCache(),
+		Progress: func(done, total int, file string) {
+			m.logger.Debug("[%d/%d] %s", done, total, file)
+		},
+	}
+
+	result, err := installer.Install(packLocation, runDir)
+	if err != nil {
+		return err
+	}
+
+	m.logger.Info("✅ Mods installed successfully (%d downloaded, %d cached, %d copied, %d up to date, %d removed)",
+		result.Downloaded, result.Cached, result.Copied, result.Skipped, result.Removed)
+	return nil
+}
+
+func (m *Manager) downloadServerJar(packDir, runDir string, packToml *packwiz.PackToml, mcVersion string) error {
+	serverJarPath := filepath.Join(runDir, "server.jar")
+
+	// Skip if already installed
+	if _, err := ServerLaunchArgs(runDir); err == nil {
+		m.logger.Info("✅ Server JAR already exists")
+		return nil
+	}
+
+	// Determine server type and version
+	loader, loaderVersion := packwiz.ModLoader(packToml)
+	switch loader {
+	case packwiz.LoaderFabric:
+		return m.downloadFabricServer(packDir, serverJarPath, mcVersion, loaderVersion)
+	case packwiz.LoaderNeoForge:
+		return m.installNeoForgeServer(packDir, runDir, mcVersion, loaderVersion)
+	case packwiz.LoaderForge:
+		return m.installForgeServer(packDir, runDir, mcVersion, loaderVersion)
+	case packwiz.LoaderQuilt:
+		return m.installQuiltServer(packDir, runDir, mcVersion, loaderVersion)
+	case "":
+		return m.downloadVanillaServer(packDir, serverJarPath, mcVersion)
+	}
+
+	return fmt.Errorf("unsupported server type: %s", loader)
+}
+
+func (m *Manager) downloadFabricServer(packDir, serverJarPath, mcVersion, fabricVersion string) error {
+	// Use Fabric API to get the appropriate server JAR
+	url := fmt.Sprintf("https://meta.fabricmc.net/v2/versions/loader/%s/%s/1.1.0/server/jar",
+		mcVersion, fabricVersion)
+
+	m.logger.Info("Downloading Fabric server for MC %s with Fabric %s...", mcVersion, fabricVersion)
+
+	downloader := &utils.HTTPDownloader{
+		Progress: m.downloadProgress(packwrap.OperationServerSetup, packDir, "server.jar"),
+	}
+	if err := downloader.DownloadFile(url, serverJarPath); err != nil {
+		return fmt.Errorf("failed to download server JAR: %w", err)
+	}
+
+	m.logger.Info("✅ Server JAR downloaded successfully")
+	return nil
+}
+
+// serverProcess is the handle returned by StartTestServer
+type serverProcess struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	output chan string
+	done   chan struct{}
+	err    error
+
+	stdinMu sync.Mutex
+}
+
+// run forwards console output line by line and records the exit status. Lines
+// are queued rather than sent directly, so the process is reaped even when
+// nobody reads Output.
+func (p *serverProcess) run(stdout io.Reader) {
+	queue := newLineQueue()
+	go queue.forward(p.output)
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		queue.push(scanner.Text())
+	}
+	queue.close()
+
+	p.err = p.cmd.Wait()
+	close(p.done)
+}
+
+// lineQueue buffers console lines without limit between the process output and
+// a reader that may fall behind
+type lineQueue struct {
+	mu     sync.Mutex
+	lines  []string
+	closed bool
+	ready  chan struct{}
+}
+
+func newLineQueue() *lineQueue {
+	return &lineQueue{ready: make(chan struct{}, 1)}
+}
+
+// push queues a line
+func (q *lineQueue) push(line string) {
+	q.mu.Lock()
+	q.lines = append(q.lines, line)
+	q.mu.Unlock()
+	q.notify()
+}
+
+// close marks the end of the output
+func (q *lineQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.notify()
+}
+
+func (q *lineQueue) notify() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// forward sends every queued line to output in order, closing it after the last one
+func (q *lineQueue) forward(output chan<- string) {
+	defer close(output)
+	for {
+		q.mu.Lock()
+		lines, closed := q.lines, q.closed
+		q.lines = nil
+		q.mu.Unlock()
+
+		for _, line := range lines {
+			output <- line
+		}
+		if len(lines) == 0 {
+			if closed {
+				return
+			}
+			<-q.ready
+		}
+	}
+}
+
+func (p *serverProcess) PID() int {
+	return p.cmd.Process.Pid
+}
+
+func (p *serverProcess) Output() <-chan string {
+	return p.output
+}
+
+func (p *serverProcess) SendCommand(command string) error {
+	p.stdinMu.Lock()
+	defer p.stdinMu.Unlock()
+
+	select {
+	case <-p.done:
+		return fmt.Errorf("server is not running")
+	default:
+	}
+
+	if _, err := io.WriteString(p.stdin, strings.TrimRight(command, "\r\n")+"\n"); err != nil {
+		return fmt.Errorf("failed to send command: %w", err)
+	}
+	return nil
+}
+
+func (p *serverProcess) Stop(timeout time.Duration) error {
+	select {
+	case <-p.done:
+		return nil
+	default:
+	}
+
+	// Ask the server to save and shut down, then kill it if it does not comply
+	if err := p.SendCommand("stop"); err != nil {
+		return p.cmd.Process.Kill()
+	}
+
+	select {
+	case <-p.done:
+		return nil
+	case <-time.After(timeout):
+		if err := p.cmd.Process.Kill(); err != nil {
+			return fmt.Errorf("failed to kill server: %w", err)
+		}
+		<-p.done
+		return nil
+	}
+}
+
+func (p *serverProcess) Wait() error {
+	<-p.done
+	return p.err
+}
+
+// logWriter forwards subprocess output to a logger one line at a time
+type logWriter struct {
+	logger  packwrap.Logger
+	pending []byte
+}
+
+func (w *logWriter) Write(data []byte) (int, error) {
+	w.pending = append(w.pending, data...)
+	for {
+		idx := bytes.IndexByte(w.pending, '\n')
+		if idx < 0 {
+			break
+		}
+		w.logger.Info("%s", strings.TrimRight(string(w.pending[:idx]), "\r"))
+		w.pending = w.pending[idx+1:]
+	}
+	return len(data), nil
+}
+
+// Flush logs any trailing output that did not end in a newline
+func (w *logWriter) Flush() {
+	if len(w.pending) > 0 {
+		w.logger.Info("%s", strings.TrimRight(string(w.pending), "\r"))
+		w.pending = nil
+	}
+}
@@ -0,0 +1,262 @@
+package core
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
+)
+
+// Files a detached server leaves in the run directory
+const (
+	serverPIDFile     = "server.pid"   // PID and process start time of the detached server, its mtime is the start time
+	serverConsolePipe = "console.pipe" // named pipe feeding the server console
+	serverOutputFile  = "console.log"  // stdout and stderr of the detached server
+)
+
+// DefaultServerPort is the port Minecraft listens on without a server-port property
+const DefaultServerPort = 25565
+
+// ServerLogPath returns the log file the server of packDir writes
+func ServerLogPath(packDir string) string {
+	return filepath.Join(ServerRunDir(packDir), "logs", "latest.log")
+}
+
+// StartDetachedServer launches the deployed server in the background and returns
+// its PID. The PID and the console pipe are recorded in the run directory so that
+// StopServer and ServerStatus work from later invocations.
+func (m *Manager) StartDetachedServer(packDir string) (int, error) {
+	if err := m.checkServerStopped(packDir); err != nil {
+		return 0, err
+	}
+
+	cmd, err := m.launchDetachedServer(packDir)
+	if err != nil {
+		return 0, err
+	}
+
+	// Reap the server if this process outlives it
+	go cmd.Wait()
+
+	return cmd.Process.Pid, nil
+}
+
+// launchDetachedServer starts the deployed server without a terminal, writing its
+// output to the run directory and recording its PID. The caller must wait for it.
+func (m *Manager) launchDetachedServer(packDir string) (*exec.Cmd, error) {
+	runDir := ServerRunDir(packDir)
+
+	cmd, err := m.serverCommand(packDir, runDir)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := os.Create(filepath.Join(runDir, serverOutputFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create server output file: %w", err)
+	}
+	defer output.Close()
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	console, err := openServerConsole(filepath.Join(runDir, serverConsolePipe))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create server console: %w", err)
+	}
+	if console != nil {
+		defer console.Close()
+		cmd.Stdin = console
+	}
+	detachProcess(cmd)
+
+	m.logger.Info("🚀 Starting Minecraft server in the background...")
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start server: %w", err)
+	}
+
+	// The start time tells the server apart from a process that reuses its PID later
+	pid := cmd.Process.Pid
+	record := strconv.Itoa(pid) + "\n" + processStartTime(pid) + "\n"
+	if err := os.WriteFile(filepath.Join(runDir, serverPIDFile), []byte(record), 0644); err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, fmt.Errorf("failed to record server PID: %w", err)
+	}
+	return cmd, nil
+}
+
+// StopServer asks the detached server to shut down through its console and
+// kills it when it is still running after timeout. A supervised server is not
+// restarted, and a supervisor waiting to restart the server exits.
+func (m *Manager) StopServer(packDir string, timeout time.Duration) error {
+	runDir := ServerRunDir(packDir)
+	status, started, err := detachedServerStatus(runDir)
+	if err != nil {
+		return err
+	}
+
+	supervisor := readSupervisorState(runDir)
+	supervised := supervisor != nil && supervisor.Running
+	if supervised {
+		if err := os.WriteFile(filepath.Join(runDir, serverStopFile), nil, 0644); err != nil {
+			return fmt.Errorf("failed to stop the supervisor: %w", err)
+		}
+	}
+
+	if !status.Running {
+		if !supervised {
+			return fmt.Errorf("server is not running")
+		}
+		m.logger.Info("⏹️  Stopping the supervisor (PID %d) while it waits to restart the server...", supervisor.PID)
+		if !waitForExit(supervisor.PID, supervisor.ProcessStart, timeout) {
+			return fmt.Errorf("supervisor did not stop within %s", timeout)
+		}
+		m.logger.Info("✅ Supervisor stopped")
+		return nil
+	}
+
+	defer removeServerState(runDir)
+	if err := m.stopServerProcess(runDir, status.PID, started, timeout); err != nil {
+		return err
+	}
+
+	// Let the supervisor notice the stop, so the run directory is free afterwards.
+	// One running in this process can only notice once StopServer returns.
+	if supervised && supervisor.PID != os.Getpid() && !waitForExit(supervisor.PID, supervisor.ProcessStart, timeout) {
+		m.logger.Warn("Supervisor (PID %d) is still running", supervisor.PID)
+	}
+	return nil
+}
+
+// stopServerProcess sends stop to the console of the detached server with the
+// given PID and start time, killing it when it does not exit within timeout
+func (m *Manager) stopServerProcess(runDir string, pid int, started string, timeout time.Duration) error {
+	m.logger.Info("⏹️  Stopping server (PID %d)...", pid)
+	if err := writeServerConsole(filepath.Join(runDir, serverConsolePipe), "stop"); err != nil {
+		m.logger.Warn("Could not send stop to the server console: %v", err)
+	} else {
+		if waitForExit(pid, started, timeout) {
+			m.logger.Info("✅ Server stopped")
+			return nil
+		}
+		m.logger.Warn("Server did not stop within %s, killing it", timeout)
+	}
+
+	// Never kill a process that took over the PID after the server exited
+	if !sameProcess(pid, started) {
+		m.logger.Info("✅ Server stopped")
+		return nil
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("failed to find server process: %w", err)
+	}
+	if err := process.Kill(); err != nil && sameProcess(pid, started) {
+		return fmt.Errorf("failed to kill server: %w", err)
+	}
+
+	m.logger.Info("✅ Server killed")
+	return nil
+}
+
+// waitForExit polls until the process with the given PID and start time has
+// exited, reporting whether it did within timeout
+func waitForExit(pid int, started string, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for sameProcess(pid, started) && time.Now().Before(deadline) {
+		time.Sleep(250 * time.Millisecond)
+	}
+	return !sameProcess(pid, started)
+}
+
+// sameProcess reports whether the process with the given PID is still the one
+// that started at started, as recorded by processStartTime. Without a recorded
+// or current start time it only checks that the PID is in use.
+func sameProcess(pid int, started string) bool {
+	if !processAlive(pid) {
+		return false
+	}
+	if started == "" {
+		return true
+	}
+	current := processStartTime(pid)
+	return current == "" || current == started
+}
+
+// ServerStatus reports whether a detached server is running for packDir. Files
+// left behind by a server that has exited are removed.
+func (m *Manager) ServerStatus(packDir string) (*packwrap.ServerStatus, error) {
+	status, _, err := detachedServerStatus(ServerRunDir(packDir))
+	return status, err
+}
+
+// detachedServerStatus implements ServerStatus, also returning the start time
+// recorded for the server process
+func detachedServerStatus(runDir string) (*packwrap.ServerStatus, string, error) {
+	status := &packwrap.ServerStatus{Port: serverPort(runDir), Supervisor: readSupervisorStatus(runDir)}
+
+	pidPath := filepath.Join(runDir, serverPIDFile)
+	data, err := os.ReadFile(pidPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return status, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read server PID: %w", err)
+	}
+
+	// After a crash or reboot the PID may belong to an unrelated process
+	pidLine, started, _ := strings.Cut(string(data), "\n")
+	started = strings.TrimSpace(started)
+	pid, err := strconv.Atoi(strings.TrimSpace(pidLine))
+	if err != nil || pid <= 0 || !sameProcess(pid, started) {
+		removeServerState(runDir)
+		return status, "", nil
+	}
+
+	status.Running = true
+	status.PID = pid
+	if info, err := os.Stat(pidPath); err == nil {
+		status.StartedAt = info.ModTime()
+	}
+	return status, started, nil
+}
+
+// removeServerState deletes the PID file and console pipe of a detached server
+func removeServerState(runDir string) {
+	os.Remove(filepath.Join(runDir, serverPIDFile))
+	os.Remove(filepath.Join(runDir, serverConsolePipe))
+}
+
+// serverPort reads server-port from server.properties
+func serverPort(runDir string) int {
+	if port, err := strconv.Atoi(serverProperty(runDir, "server-port")); err == nil && port > 0 {
+		return port
+	}
+	return DefaultServerPort
+}
+
+// serverProperty reads a key from server.properties, or returns "" when it is not set
+func serverProperty(runDir, name string) string {
+	file, err := os.Open(filepath.Join(runDir, "server.properties"))
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if ok && strings.TrimSpace(key) == name {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,295 @@
+package core
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
+)
+
+// Download locations of the vanilla server and the loader installers
+var (
+	versionManifestURL   = "https://piston-meta.mojang.com/mc/game/version_manifest_v2.json"
+	quiltInstallerMaven  = "https://maven.quiltmc.org/repository/release/org/quiltmc/quilt-installer"
+	forgeMaven           = "https://maven.minecraftforge.net/net/minecraftforge/forge"
+	neoForgeMaven        = "https://maven.neoforged.net/releases/net/neoforged"
+	quiltServerLaunchJar = "quilt-server-launch.jar"
+)
+
+// ServerLaunchArgs returns the java arguments, after any memory flags, that start
+// the server installed in runDir. Each loader leaves a different layout:
+//
+//   - Quilt: quilt-server-launch.jar, next to the vanilla server.jar it loads
+//   - NeoForge and Forge 1.17+: an argument file under libraries/ written by the installer
+//   - Fabric and vanilla: server.jar
+//   - Forge before 1.17: forge-<version>.jar
+func ServerLaunchArgs(runDir string) ([]string, error) {
+	if fileExists(filepath.Join(runDir, quiltServerLaunchJar)) {
+		return []string{"-jar", quiltServerLaunchJar}, nil
+	}
+
+	argsFile := "unix_args.txt"
+	if runtime.GOOS == "windows" {
+		argsFile = "win_args.txt"
+	}
+	for _, pattern := range []string{
+		filepath.Join(runDir, "libraries", "net", "neoforged", "*", "*", argsFile),
+		filepath.Join(runDir, "libraries", "net", "minecraftforge", "forge", "*", argsFile),
+	} {
+		matches, _ := filepath.Glob(pattern)
+		if len(matches) == 0 {
+			continue
+		}
+		sort.Strings(matches)
+
+		// user_jvm_args.txt is where the installer's run scripts expect custom flags
+		var args []string
+		if fileExists(filepath.Join(runDir, "user_jvm_args.txt")) {
+			args = append(args, "@user_jvm_args.txt")
+		}
+		rel, err := filepath.Rel(runDir, matches[len(matches)-1])
+		if err != nil {
+			return nil, err
+		}
+		return append(args, "@"+filepath.ToSlash(rel)), nil
+	}
+
+	if fileExists(filepath.Join(runDir, "server.jar")) {
+		return []string{"-jar", "server.jar"}, nil
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(runDir, "forge-*.jar"))
+	sort.Strings(matches)
+	for _, match := range matches {
+		if !strings.HasSuffix(match, "-installer.jar") {
+			return []string{"-jar", filepath.Base(match)}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("server.jar not found")
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// neoForgeInstallerURL returns the NeoForge installer for a loader version. The
+// 1.20.1 releases were published as a Forge fork under net.neoforged:forge.
+func neoForgeInstallerURL(mcVersion, neoForgeVersion string) string {
+	artifact, version := "neoforge", neoForgeVersion
+	if mcVersion == "1.20.1" {
+		artifact, version = "forge", "1.20.1-"+strings.TrimPrefix(neoForgeVersion, "1.20.1-")
+	}
+	return fmt.Sprintf("%s/%s/%s/%s-%s-installer.jar", neoForgeMaven, artifact, version, artifact, version)
+}
+
+// forgeInstallerURL returns the Forge installer for a loader version. packwiz
+// records Forge versions without the Minecraft prefix the Forge maven uses.
+func forgeInstallerURL(mcVersion, forgeVersion string) string {
+	version := mcVersion + "-" + strings.TrimPrefix(forgeVersion, mcVersion+"-")
+	return fmt.Sprintf("%s/%s/forge-%s-installer.jar", forgeMaven, version, version)
+}
+
+// installNeoForgeServer runs the NeoForge installer in server mode inside runDir
+func (m *Manager) installNeoForgeServer(packDir, runDir, mcVersion, neoForgeVersion string) error {
+	m.logger.Info("Downloading NeoForge %s installer for MC %s...", neoForgeVersion, mcVersion)
+	url := neoForgeInstallerURL(mcVersion, neoForgeVersion)
+	if err := m.runServerInstaller(packDir, runDir, "NeoForge", url, "--installServer"); err != nil {
+		return err
+	}
+
+	m.logger.Info("✅ NeoForge server installed successfully")
+	return nil
+}
+
+// installForgeServer runs the Forge installer in server mode inside runDir
+func (m *Manager) installForgeServer(packDir, runDir, mcVersion, forgeVersion string) error {
+	m.logger.Info("Downloading Forge %s installer for MC %s...", forgeVersion, mcVersion)
+	url := forgeInstallerURL(mcVersion, forgeVersion)
+	if err := m.runServerInstaller(packDir, runDir, "Forge", url, "--installServer"); err != nil {
+		return err
+	}
+
+	m.logger.Info("✅ Forge server installed successfully")
+	return nil
+}
+
+// installQuiltServer runs the latest quilt-installer in server mode inside runDir.
+// It also downloads the vanilla server.jar that quilt-server-launch.jar loads.
+func (m *Manager) installQuiltServer(packDir, runDir, mcVersion, quiltVersion string) error {
+	installerVersion, err := latestMavenRelease(quiltInstallerMaven + "/maven-metadata.xml")
+	if err != nil {
+		return fmt.Errorf("failed to find the latest Quilt installer: %w", err)
+	}
+
+	absRunDir, err := filepath.Abs(runDir)
+	if err != nil {
+		return err
+	}
+
+	m.logger.Info("Downloading Quilt installer %s for MC %s with Quilt %s...", installerVersion, mcVersion, quiltVersion)
+	url := fmt.Sprintf("%s/%s/quilt-installer-%s.jar", quiltInstallerMaven, installerVersion, installerVersion)
+	if err := m.runServerInstaller(packDir, runDir, "Quilt", url,
+		"install", "server", mcVersion, quiltVersion, "--download-server", "--install-dir="+absRunDir); err != nil {
+		return err
+	}
+
+	m.logger.Info("✅ Quilt server installed successfully")
+	return nil
+}
+
+// runServerInstaller downloads a loader installer into runDir, runs it with
+// args and removes it again, checking that it left a launchable server behind
+func (m *Manager) runServerInstaller(packDir, runDir, loaderName, url string, args ...string) error {
+	installerName := strings.ToLower(loaderName) + "-installer.jar"
+	installerPath := filepath.Join(runDir, installerName)
+	defer os.Remove(installerPath)
+	defer os.Remove(installerPath + ".log")
+
+	downloader := &utils.HTTPDownloader{
+		Progress: m.downloadProgress(packwrap.OperationServerSetup, packDir, installerName),
+	}
+	if err := downloader.DownloadFile(url, installerPath); err != nil {
+		return fmt.Errorf("failed to download %s installer: %w", loaderName, err)
+	}
+
+	m.logger.Info("Running %s installer...", loaderName)
+	output := &logWriter{logger: m.logger}
+	cmd := exec.Command(m.serverJava(packDir), append([]string{"-jar", installerName}, args...)...)
+	cmd.Dir = runDir
+	cmd.Stdout = output
+	cmd.Stderr = output
+	err := cmd.Run()
+	output.Flush()
+	if err != nil {
+		return fmt.Errorf("%s installer failed: %w", loaderName, err)
+	}
+
+	if _, err := ServerLaunchArgs(runDir); err != nil {
+		return fmt.Errorf("%s installer finished without installing a server: %w", loaderName, err)
+	}
+	return nil
+}
+
+// downloadVanillaServer downloads the vanilla server for mcVersion from
+// Mojang's version manifest and checks its hash
+func (m *Manager) downloadVanillaServer(packDir, serverJarPath, mcVersion string) error {
+	m.logger.Info("Downloading vanilla server for MC %s...", mcVersion)
+
+	url, sha1, err := vanillaServerDownload(mcVersion)
+	if err != nil {
+		return err
+	}
+
+	downloader := &utils.HTTPDownloader{
+		Progress: m.downloadProgress(packwrap.OperationServerSetup, packDir, "server.jar"),
+	}
+	if err := downloader.DownloadFile(url, serverJarPath); err != nil {
+		return fmt.Errorf("failed to download server JAR: %w", err)
+	}
+
+	actual, err := utils.HashFile(serverJarPath, "sha1")
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(actual, sha1) {
+		os.Remove(serverJarPath)
+		return fmt.Errorf("server JAR hash mismatch: expected %s, got %s", sha1, actual)
+	}
+
+	m.logger.Info("✅ Server JAR downloaded successfully")
+	return nil
+}
+
+// vanillaServerDownload looks up the server download of a Minecraft version
+func vanillaServerDownload(mcVersion string) (url, sha1 string, err error) {
+	var manifest struct {
+		Versions []struct {
+			ID  string `json:"id"`
+			URL string `json:"url"`
+		} `json:"versions"`
+	}
+	if err := getJSON(versionManifestURL, &manifest); err != nil {
+		return "", "", fmt.Errorf("failed to read version manifest: %w", err)
+	}
+
+	versionURL := ""
+	for _, version := range manifest.Versions {
+		if version.ID == mcVersion {
+			versionURL = version.URL
+			break
+		}
+	}
+	if versionURL == "" {
+		return "", "", fmt.Errorf("minecraft version %s not found in the version manifest", mcVersion)
+	}
+
+	var version struct {
+		Downloads struct {
+			Server *struct {
+				SHA1 string `json:"sha1"`
+				URL  string `json:"url"`
+			} `json:"server"`
+		} `json:"downloads"`
+	}
+	if err := getJSON(versionURL, &version); err != nil {
+		return "", "", fmt.Errorf("failed to read version %s: %w", mcVersion, err)
+	}
+	if version.Downloads.Server == nil {
+		return "", "", fmt.Errorf("minecraft %s has no server download", mcVersion)
+	}
+	return version.Downloads.Server.URL, version.Downloads.Server.SHA1, nil
+}
+
+// latestMavenRelease returns the release version listed in a maven-metadata.xml
+func latestMavenRelease(metadataURL string) (string, error) {
+	resp, err := http.Get(metadataURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	var metadata struct {
+		Versioning struct {
+			Release string `xml:"release"`
+			Latest  string `xml:"latest"`
+		} `xml:"versioning"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return "", err
+	}
+	if metadata.Versioning.Release != "" {
+		return metadata.Versioning.Release, nil
+	}
+	if metadata.Versioning.Latest != "" {
+		return metadata.Versioning.Latest, nil
+	}
+	return "", fmt.Errorf("no release listed in %s", metadataURL)
+}
+
+// getJSON decodes the JSON response of a GET request into v
+func getJSON(url string, v interface{}) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,209 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/packwiz"
+	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
+)
+
+// Limits of a server test
+const (
+	DefaultServerTestTimeout = 5 * time.Minute
+	serverTestStopTimeout    = 30 * time.Second
+	maxServerTestErrors      = 50
+)
+
+var (
+	// serverDonePattern matches the line every server prints once it accepts players
+	serverDonePattern = regexp.MustCompile(`Done \([0-9.,]+m?s\)! For help`)
+
+	// serverErrorPattern matches log4j ERROR and FATAL lines, e.g. "[main/ERROR]" or "[12:00:00 ERROR]"
+	serverErrorPattern = regexp.MustCompile(`\[[^\]]*[/ ](ERROR|FATAL)\]`)
+
+	// crashReportPattern matches where the server says it saved a crash report
+	crashReportPattern = regexp.MustCompile(`(?i)crash report (?:has been )?saved to:?\s*(?:#@!@#\s*)?(\S+\.txt)`)
+)
+
+// bootCheck decides when a booting server has started or failed
+type bootCheck struct {
+	started *regexp.Regexp // a matching console line passes the boot
+	failed  *regexp.Regexp // a matching console line fails it; nil fails only on exit or timeout
+}
+
+// defaultBootCheck passes once the server accepts players
+var defaultBootCheck = bootCheck{started: serverDonePattern}
+
+// TestServer boots the deployed server without a console, waits until it has
+// finished starting and stops it again. A server that crashes, exits or does
+// not start within timeout fails the test.
+func (m *Manager) TestServer(packDir string, timeout time.Duration) (*packwrap.ServerTestResult, error) {
+	if err := m.checkServerStopped(packDir); err != nil {
+		return nil, err
+	}
+	return m.bootServer(packDir, ServerRunDir(packDir), timeout, defaultBootCheck)
+}
+
+// bootServer boots the server deployed in runDir until check passes or fails it,
+// then stops it and collects the crash report and the mods it blames
+func (m *Manager) bootServer(packDir, runDir string, timeout time.Duration, check bootCheck) (*packwrap.ServerTestResult, error) {
+	if timeout <= 0 {
+		timeout = DefaultServerTestTimeout
+	}
+
+	started := time.Now()
+	process, err := m.startServer(packDir, runDir)
+	if err != nil {
+		return nil, err
+	}
+
+	m.logger.Info("⏳ Waiting up to %s for the server to start...", timeout)
+	result, crashReport := m.watchServerTest(process, timeout, check)
+	result.Duration = time.Since(started)
+
+	// Find the crash report, written as the server died
+	if crashReport != "" && !filepath.IsAbs(crashReport) {
+		crashReport = filepath.Join(runDir, crashReport)
+	}
+	if crashReport == "" && !result.Passed {
+		crashReport = latestCrashReport(runDir, started)
+	}
+
+	evidence := result.Errors
+	if crashReport != "" {
+		result.CrashReport = crashReport
+		if lines, err := readCrashReport(crashReport); err == nil {
+			evidence = append(append([]string{}, evidence...), lines...)
+		}
+	}
+	result.Mods = implicatedMods(evidence, m.packModJars(packDir))
+
+	return result, nil
+}
+
+// watchServerTest reads the console until check passes or fails the boot, the
+// server exits or the timeout passes, then stops it. It returns the result and
+// the crash report path the server printed, if any.
+func (m *Manager) watchServerTest(process packwrap.ServerProcess, timeout time.Duration, check bootCheck) (*packwrap.ServerTestResult, string) {
+	result := &packwrap.ServerTestResult{}
+	crashReport := ""
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	output := process.Output()
+	for {
+		select {
+		case line, ok := <-output:
+			if !ok {
+				err := process.Wait()
+				result.Reason = "server exited before it finished starting"
+				if err != nil {
+					result.Reason = fmt.Sprintf("%s: %v", result.Reason, err)
+				}
+				return result, crashReport
+			}
+
+			m.logger.Debug("[server] %s", line)
+			if serverErrorPattern.MatchString(line) && len(result.Errors) < maxServerTestErrors {
+				result.Errors = append(result.Errors, line)
+			}
+			if match := crashReportPattern.FindStringSubmatch(line); match != nil {
+				crashReport = match[1]
+			}
+			if check.failed != nil && check.failed.MatchString(line) {
+				result.Reason = fmt.Sprintf("server logged %q", strings.TrimSpace(line))
+				stopServerTest(process)
+				return result, crashReport
+			}
+			if check.started.MatchString(line) {
+				m.logger.Info("✅ Server started, stopping it...")
+				result.Passed = true
+				if err := stopServerTest(process); err != nil {
+					result.Passed = false
+					result.Reason = fmt.Sprintf("server started but did not stop cleanly: %v", err)
+				}
+				return result, crashReport
+			}
+
+		case <-timer.C:
+			result.Reason = fmt.Sprintf("server did not finish starting within %s", timeout)
+			stopServerTest(process)
+			return result, crashReport
+		}
+	}
+}
+
+// stopServerTest stops the server, draining its console so it can exit
+func stopServerTest(process packwrap.ServerProcess) error {
+	go func() {
+		for range process.Output() {
+		}
+	}()
+	return process.Stop(serverTestStopTimeout)
+}
+
+// latestCrashReport returns the newest crash report written since started
+func latestCrashReport(runDir string, started time.Time) string {
+	matches, _ := filepath.Glob(filepath.Join(runDir, "crash-reports", "*.txt"))
+
+	latest, latestTime := "", started.Add(-time.Second)
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err == nil && info.ModTime().After(latestTime) {
+			latest, latestTime = match, info.ModTime()
+		}
+	}
+	return latest
+}
+
+// packModJars maps the jar file names of the pack's mods to their names
+func (m *Manager) packModJars(packDir string) map[string]string {
+	jars := map[string]string{}
+	packLocation := utils.FindPackToml(packDir)
+	if packLocation == "" {
+		return jars
+	}
+
+	data, err := packwiz.ReadPack(packLocation)
+	if err != nil {
+		m.logger.Debug("could not read pack: %v", err)
+		return jars
+	}
+	for _, mod := range data.Mods {
+		if strings.HasSuffix(mod.Filename, ".jar") {
+			jars[mod.Filename] = mod.Name
+		}
+	}
+	return jars
+}
+
+// implicatedMods names the mods that lines blame: mod IDs from loader errors
+// and crash reports, and the pack's mods whose jars appear in stack traces
+func implicatedMods(lines []string, jars map[string]string) []string {
+	evidence := findCrashEvidence(lines)
+
+	found := map[string]bool{}
+	for _, id := range evidence.ModIDs {
+		found[id] = true
+	}
+	for _, jar := range evidence.Jars {
+		if name, ok := jars[jar]; ok {
+			found[name] = true
+		}
+	}
+
+	mods := make([]string, 0, len(found))
+	for mod := range found {
+		mods = append(mods, mod)
+	}
+	sort.Strings(mods)
+	return mods
+}
@@ -0,0 +1,203 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServerProcess replays console lines without starting a server
+type fakeServerProcess struct {
+	output   chan string
+	done     chan struct{}
+	err      error
+	stopOnce sync.Once
+	stopped  bool
+}
+
+func newFakeServerProcess(lines ...string) *fakeServerProcess {
+	process := &fakeServerProcess{output: make(chan string, len(lines)), done: make(chan struct{})}
+	for _, line := range lines {
+		process.output <- line
+	}
+	return process
+}
+
+func (p *fakeServerProcess) exit(err error) {
+	p.stopOnce.Do(func() {
+		p.err = err
+		close(p.output)
+		close(p.done)
+	})
+}
+
+func (p *fakeServerProcess) PID() int                 { return 1 }
+func (p *fakeServerProcess) Output() <-chan string    { return p.output }
+func (p *fakeServerProcess) SendCommand(string) error { return nil }
+func (p *fakeServerProcess) Wait() error              { <-p.done; return p.err }
+func (p *fakeServerProcess) Stop(timeout time.Duration) error {
+	p.stopped = true
+	p.exit(nil)
+	return nil
+}
+
+func TestWatchServerTest(t *testing.T) {
+	manager := NewManager(nil)
+
+	t.Run("started", func(t *testing.T) {
+		process := newFakeServerProcess(
+			"[12:00:00] [main/INFO]: Loading Minecraft 1.21.1 with Fabric Loader 0.16.5",
+			"[12:00:01] [Worker-Main-1/ERROR]: Failed to load texture",
+			`[12:00:05] [Server thread/INFO]: Done (4.215s)! For help, type "help"`,
+		)
+		result, _ := manager.watchServerTest(process, time.Minute, defaultBootCheck)
+		if !result.Passed || !process.stopped {
+			t.Errorf("Expected a passed test that stopped the server, got %+v", result)
+		}
+		if len(result.Errors) != 1 {
+			t.Errorf("Expected one error line, got %v", result.Errors)
+		}
+	})
+
+	t.Run("crashed", func(t *testing.T) {
+		process := newFakeServerProcess(
+			"[12:00:00] [main/FATAL]: Failed to start the minecraft server",
+			"This crash report has been saved to: ./crash-reports/crash-server.txt",
+		)
+		process.exit(errors.New("exit status 1"))
+		result, crashReport := manager.watchServerTest(process, time.Minute, defaultBootCheck)
+		if result.Passed || !strings.Contains(result.Reason, "exit status 1") {
+			t.Errorf("Expected a failure naming the exit status, got %+v", result)
+		}
+		if crashReport != "./crash-reports/crash-server.txt" {
+			t.Errorf("Expected the printed crash report, got %q", crashReport)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		process := newFakeServerProcess("[12:00:00] [main/INFO]: Loading")
+		result, _ := manager.watchServerTest(process, 50*time.Millisecond, defaultBootCheck)
+		if result.Passed || !strings.Contains(result.Reason, "did not finish starting") || !process.stopped {
+			t.Errorf("Expected a stopped server and a timeout, got %+v", result)
+		}
+	})
+
+	t.Run("custom check", func(t *testing.T) {
+		check := bootCheck{
+			started: regexp.MustCompile(`Preparing spawn area: 100%`),
+			failed:  regexp.MustCompile(`Mixin apply failed`),
+		}
+		process := newFakeServerProcess(
+			`[12:00:05] [Server thread/INFO]: Done (4.215s)! For help, type "help"`,
+			"[12:00:06] [Server thread/ERROR]: Mixin apply failed broken.mixins.json:ServerMixin",
+		)
+		result, _ := manager.watchServerTest(process, time.Minute, check)
+		if result.Passed || !strings.Contains(result.Reason, "Mixin apply failed") || !process.stopped {
+			t.Errorf("Expected the failure pattern to fail the boot, got %+v", result)
+		}
+	})
+}
+
+func TestImplicatedMods(t *testing.T) {
+	lines := []string{
+		"[main/ERROR]: Incompatible mods found!",
+		" - Mod 'Sodium' (sodium) 0.5.3 is incompatible with mod 'OptiFabric' (optifabric).",
+		"[main/ERROR] [net.minecraftforge.fml.ModLoader/LOADING]: Failed to create mod instance. ModID: examplemod",
+		"Mod ID 'brokenmod' is missing a dependency",
+		"Suspected Mods: ",
+		"\tLithium (lithium), Version: 0.11.2",
+		"\tat me.jellysquid.Foo.bar(Foo.java:10) ~[ferritecore-6.0.1-fabric.jar:?]",
+		"\tat net.minecraft.server.Main.main(Main.java:1) ~[server-1.21.1.jar:?]",
+	}
+	jars := map[string]string{"ferritecore-6.0.1-fabric.jar": "FerriteCore"}
+
+	mods := implicatedMods(lines, jars)
+	expected := "FerriteCore,brokenmod,examplemod,lithium,optifabric,sodium"
+	if got := strings.Join(mods, ","); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestReadCrashReportStopsAtSystemDetails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crash.txt")
+	content := "---- Minecraft Crash Report ----\nDescription: Exception in server tick loop\n\n-- System Details --\n\t\tsodium-0.5.3.jar |Sodium |sodium\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lines, err := readCrashReport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 3 || strings.Contains(strings.Join(lines, "\n"), "sodium") {
+		t.Errorf("Expected the report without its system details, got %q", lines)
+	}
+}
+
+// TestServerWithFakeServer runs the whole test against a shell script standing
+// in for java, configured through packwrap.toml
+func TestServerWithFakeServer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stand-in server is a shell script")
+	}
+
+	scripts := map[string]string{
+		"started": `#!/bin/sh
+echo '[12:00:00] [Server thread/INFO]: Starting minecraft server version 1.21.1'
+echo '[12:00:01] [Server thread/INFO]: Done (1.000s)! For help, type "help"'
+while read line; do [ "$line" = stop ] && exit 0; done
+`,
+		"crashed": `#!/bin/sh
+mkdir -p crash-reports
+printf 'Description: Mod loading error\n\nSuspected Mods: \n\tBroken Mod (brokenmod), Version: 1.0\n' > crash-reports/crash-test-server.txt
+echo '[12:00:00] [main/ERROR]: Failed to create mod instance. ModID: brokenmod'
+echo 'This crash report has been saved to: ./crash-reports/crash-test-server.txt'
+exit 1
+`,
+	}
+
+	for name, script := range scripts {
+		t.Run(name, func(t *testing.T) {
+			packDir := t.TempDir()
+			if err := os.MkdirAll(ServerRunDir(packDir), 0755); err != nil {
+				t.Fatal(err)
+			}
+			files := map[string]string{
+				"pack.toml":       "name = \"Test\"\n[versions]\nminecraft = \"1.21.1\"\n",
+				"packwrap.toml":   "[server]\njava = \"./fake-java.sh\"\n",
+				"fake-java.sh":    script,
+				".run/server.jar": "",
+				".run/eula.txt":   "eula=true\n",
+			}
+			for file, content := range files {
+				if err := os.WriteFile(filepath.Join(packDir, filepath.FromSlash(file)), []byte(content), 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			result, err := NewManager(nil).TestServer(packDir, 30*time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if name == "started" {
+				if !result.Passed {
+					t.Errorf("Expected the test to pass, got %+v", result)
+				}
+				return
+			}
+			if result.Passed || !strings.HasSuffix(result.CrashReport, "crash-test-server.txt") {
+				t.Errorf("Expected a failure with the crash report, got %+v", result)
+			}
+			if strings.Join(result.Mods, ",") != "brokenmod" {
+				t.Errorf("Expected brokenmod to be blamed, got %v", result.Mods)
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
+)
+
+// Supervisor defaults
+const (
+	DefaultMaxRestarts       = 5
+	DefaultRestartBackoff    = 10 * time.Second
+	DefaultMaxRestartBackoff = 5 * time.Minute
+
+	// serverStableUptime is how long a server has to stay up before its next
+	// crash counts as the first in a row again
+	serverStableUptime = 10 * time.Minute
+
+	// maxRestartHistory is how many crashes the state file remembers
+	maxRestartHistory = 20
+)
+
+// Files the supervisor keeps in the run directory
+const (
+	supervisorStateFile = "supervisor.json" // PID of the supervisor and the restart history
+	supervisorLogFile   = "supervisor.log"  // output of a supervisor started in the background
+	serverStopFile      = "server.stop"     // written by StopServer so the server is not restarted
+	serverCrashDir      = "crashes"         // a timestamped directory of reports and console output per crash
+)
+
+// SuperviseServer runs the deployed server in the background like
+// StartDetachedServer and restarts it whenever it exits with an error, waiting
+// longer after every crash. It gives up after MaxRestarts crashes in a row and
+// returns once the server exits cleanly, is stopped with StopServer or ctx is done.
+// Every crash is archived and recorded in the restart history of ServerStatus.
+func (m *Manager) SuperviseServer(ctx context.Context, packDir string, options packwrap.SuperviseOptions) error {
+	if options.MaxRestarts <= 0 {
+		options.MaxRestarts = DefaultMaxRestarts
+	}
+	if options.Backoff <= 0 {
+		options.Backoff = DefaultRestartBackoff
+	}
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = DefaultMaxRestartBackoff
+	}
+	options.MaxBackoff = max(options.MaxBackoff, options.Backoff)
+
+	if err := m.checkServerStopped(packDir); err != nil {
+		return err
+	}
+	runDir := ServerRunDir(packDir)
+	if err := VerifyServerSetup(runDir); err != nil {
+		return fmt.Errorf("%w: %w", ErrServerNotSetUp, err)
+	}
+	os.Remove(filepath.Join(runDir, serverStopFile))
+
+	// Keep the history of earlier supervisors
+	state := readSupervisorStatus(runDir)
+	if state == nil {
+		state = &packwrap.SupervisorStatus{}
+	}
+	state.Running = true
+	state.PID = os.Getpid()
+	state.StartedAt = time.Now()
+	state.MaxRestarts = options.MaxRestarts
+	state.GaveUp = false
+	if err := writeSupervisorStatus(runDir, state); err != nil {
+		return err
+	}
+	defer func() {
+		state.Running = false
+		if err := writeSupervisorStatus(runDir, state); err != nil {
+			m.logger.Warn("%v", err)
+		}
+		os.Remove(filepath.Join(runDir, serverStopFile))
+	}()
+
+	crashes := 0
+	backoff := options.Backoff
+	for {
+		started := time.Now()
+		crashFiles := serverCrashFiles(runDir)
+		cmd, err := m.launchDetachedServer(packDir)
+		if err != nil {
+			return err
+		}
+		// Identify the server process, which may be reaped and its PID reused before it is stopped
+		processStart := processStartTime(cmd.Process.Pid)
+		m.logger.Info("👀 Supervising server (PID %d)", cmd.Process.Pid)
+
+		exited := make(chan error, 1)
+		go func() {
+			exited <- cmd.Wait()
+		}()
+
+		var exitErr error
+		select {
+		case exitErr = <-exited:
+		case <-ctx.Done():
+			err := m.stopServerProcess(runDir, cmd.Process.Pid, processStart, serverTestStopTimeout)
+			<-exited
+			removeServerState(runDir)
+			return err
+		}
+		removeServerState(runDir)
+		uptime := time.Since(started)
+
+		if fileExists(filepath.Join(runDir, serverStopFile)) {
+			m.logger.Info("⏹️  Server stopped, no longer supervising it")
+			return nil
+		}
+		if exitErr == nil {
+			m.logger.Info("⏹️  Server exited cleanly, no longer supervising it")
+			return nil
+		}
+
+		if uptime >= serverStableUptime {
+			crashes, backoff = 0, options.Backoff
+		}
+		crashes++
+
+		restart := packwrap.ServerRestart{CrashedAt: time.Now(), ExitCode: exitCode(exitErr), Uptime: uptime}
+		if archive, err := archiveServerCrash(runDir, crashFiles, restart.CrashedAt); err != nil {
+			m.logger.Warn("Could not archive the crash: %v", err)
+		} else {
+			restart.Archive = archive
+		}
+		giveUp := crashes > options.MaxRestarts
+		if !giveUp {
+			restart.Backoff = backoff
+		}
+
+		state.Restarts = append(state.Restarts, restart)
+		if len(state.Restarts) > maxRestartHistory {
+			state.Restarts = state.Restarts[len(state.Restarts)-maxRestartHistory:]
+		}
+		state.GaveUp = giveUp
+		if err := writeSupervisorStatus(runDir, state); err != nil {
+			m.logger.Warn("%v", err)
+		}
+
+		if giveUp {
+			return fmt.Errorf("server crashed %d times in a row, giving up", crashes)
+		}
+		m.logger.Warn("💥 Server crashed with exit code %d after %s, restarting in %s (%d of %d)",
+			restart.ExitCode, uptime.Round(time.Second), backoff, crashes, options.MaxRestarts)
+		if !waitForRestart(ctx, runDir, backoff) {
+			m.logger.Info("⏹️  Stopped while waiting to restart the server")
+			return nil
+		}
+		backoff = min(backoff*2, options.MaxBackoff)
+	}
+}
+
+// StartDetachedSupervisor runs command, a pw invocation that supervises the
+// server of packDir, in the background and returns its PID. Its output goes to
+// supervisor.log in the run directory.
+func (m *Manager) StartDetachedSupervisor(packDir string, command []string) (int, error) {
+	if err := m.checkServerStopped(packDir); err != nil {
+		return 0, err
+	}
+	runDir := ServerRunDir(packDir)
+	if err := VerifyServerSetup(runDir); err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrServerNotSetUp, err)
+	}
+
+	output, err := os.Create(filepath.Join(runDir, supervisorLogFile))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create supervisor log: %w", err)
+	}
+	defer output.Close()
+
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = packDir
+	cmd.Stdout = output
+	cmd.Stderr = output
+	detachProcess(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start supervisor: %w", err)
+	}
+	pid := cmd.Process.Pid
+	cmd.Process.Release()
+	return pid, nil
+}
+
+// supervisorState is the content of the supervisor state file: the status and
+// the start time of the supervisor process, to tell it apart from a process that
+// reuses its PID
+type supervisorState struct {
+	packwrap.SupervisorStatus
+	ProcessStart string `json:"process_start,omitempty"`
+}
+
+// readSupervisorStatus loads the supervisor state of a run directory, or nil
+// when the server was never supervised
+func readSupervisorStatus(runDir string) *packwrap.SupervisorStatus {
+	if state := readSupervisorState(runDir); state != nil {
+		return &state.SupervisorStatus
+	}
+	return nil
+}
+
+// readSupervisorState loads the supervisor state file of a run directory, or nil
+// when the server was never supervised
+func readSupervisorState(runDir string) *supervisorState {
+	data, err := os.ReadFile(filepath.Join(runDir, supervisorStateFile))
+	if err != nil {
+		return nil
+	}
+
+	var state supervisorState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil
+	}
+	// A supervisor that was killed never recorded that it stopped
+	state.Running = state.Running && sameProcess(state.PID, state.ProcessStart)
+	return &state
+}
+
+// writeSupervisorStatus saves the supervisor state of a run directory
+func writeSupervisorStatus(runDir string, status *packwrap.SupervisorStatus) error {
+	state := supervisorState{SupervisorStatus: *status}
+	if status.Running {
+		state.ProcessStart = processStartTime(status.PID)
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(runDir, supervisorStateFile), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to save supervisor state: %w", err)
+	}
+	return nil
+}
+
+// serverCrashFiles lists the crash reports and JVM error logs in runDir with
+// their modification times
+func serverCrashFiles(runDir string) map[string]time.Time {
+	reports, _ := filepath.Glob(filepath.Join(runDir, "crash-reports", "*.txt"))
+	jvmLogs, _ := filepath.Glob(filepath.Join(runDir, "hs_err_pid*.log"))
+
+	files := map[string]time.Time{}
+	for _, file := range append(reports, jvmLogs...) {
+		if info, err := os.Stat(file); err == nil {
+			files[file] = info.ModTime()
+		}
+	}
+	return files
+}
+
+// archiveServerCrash copies the crash files that are not in before, or changed
+// since, and the console output into a directory under crashes named after the
+// time of the crash. It returns the directory relative to runDir.
+func archiveServerCrash(runDir string, before map[string]time.Time, crashed time.Time) (string, error) {
+	var files []string
+	for file, modTime := range serverCrashFiles(runDir) {
+		if previous, ok := before[file]; !ok || !modTime.Equal(previous) {
+			files = append(files, file)
+		}
+	}
+	if console := filepath.Join(runDir, serverOutputFile); fileExists(console) {
+		files = append(files, console)
+	}
+
+	name := crashed.Format("2006-01-02_15.04.05")
+	archive := filepath.Join(serverCrashDir, name)
+	for i := 2; fileExists(filepath.Join(runDir, archive)); i++ {
+		archive = filepath.Join(serverCrashDir, fmt.Sprintf("%s-%d", name, i))
+	}
+	if err := os.MkdirAll(filepath.Join(runDir, archive), 0755); err != nil {
+		return "", err
+	}
+
+	for _, file := range files {
+		if err := utils.CopyFile(file, filepath.Join(runDir, archive, filepath.Base(file))); err != nil {
+			return "", err
+		}
+	}
+	return archive, nil
+}
+
+// waitForRestart waits for backoff, returning false when the supervisor is
+// stopped in the meantime
+func waitForRestart(ctx context.Context, runDir string, backoff time.Duration) bool {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	stopFile := filepath.Join(runDir, serverStopFile)
+	for {
+		select {
+		case <-timer.C:
+			return !fileExists(stopFile)
+		case <-ticker.C:
+			if fileExists(stopFile) {
+				return false
+			}
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// exitCode returns the exit code of a finished process, or -1 when it was killed by a signal
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
@@ -0,0 +1,179 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
+)
+
+// writeSuperviseTestPack writes a deployed pack whose server is the given shell script
+func writeSuperviseTestPack(t *testing.T, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the stand-in server is a shell script")
+	}
+
+	packDir := t.TempDir()
+	if err := os.MkdirAll(ServerRunDir(packDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"pack.toml":       "name = \"Test\"\n[versions]\nminecraft = \"1.21.1\"\n",
+		"packwrap.toml":   "[server]\njava = \"./fake-java.sh\"\n",
+		"fake-java.sh":    script,
+		".run/server.jar": "",
+		".run/eula.txt":   "eula=true\n",
+	}
+	for file, content := range files {
+		if err := os.WriteFile(filepath.Join(packDir, filepath.FromSlash(file)), []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return packDir
+}
+
+func TestSuperviseServerRestartsAfterCrashes(t *testing.T) {
+	packDir := writeSuperviseTestPack(t, `#!/bin/sh
+runs=$(cat runs 2>/dev/null || echo 0)
+runs=$((runs + 1))
+echo $runs > runs
+echo "run $runs"
+if [ $runs -le 2 ]; then
+  mkdir -p crash-reports
+  echo 'Description: Exception in server tick loop' > crash-reports/crash-$runs-server.txt
+  exit 1
+fi
+`)
+
+	manager := NewManager(nil)
+	options := packwrap.SuperviseOptions{Backoff: 10 * time.Millisecond, MaxBackoff: 15 * time.Millisecond}
+	if err := manager.SuperviseServer(context.Background(), packDir, options); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := manager.ServerStatus(packDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	supervisor := status.Supervisor
+	if supervisor == nil || supervisor.Running || supervisor.GaveUp || len(supervisor.Restarts) != 2 {
+		t.Fatalf("Expected a stopped supervisor with two restarts, got %+v", supervisor)
+	}
+
+	runDir := ServerRunDir(packDir)
+	backoffs := []time.Duration{10 * time.Millisecond, 15 * time.Millisecond}
+	for i, restart := range supervisor.Restarts {
+		run := strconv.Itoa(i + 1)
+		expectedBackoff := backoffs[i]
+		if restart.ExitCode != 1 || restart.Backoff != expectedBackoff {
+			t.Errorf("Restart %d: expected exit code 1 and backoff %s, got %+v", i, expectedBackoff, restart)
+		}
+
+		// Each archive holds its own run's crash report and console output
+		entries, err := os.ReadDir(filepath.Join(runDir, restart.Archive))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		expected := []string{"console.log", "crash-" + run + "-server.txt"}
+		if strings.Join(names, ",") != strings.Join(expected, ",") {
+			t.Errorf("Restart %d: expected archive %v, got %v", i, expected, names)
+		}
+		console, _ := os.ReadFile(filepath.Join(runDir, restart.Archive, "console.log"))
+		if !strings.Contains(string(console), "run "+run) {
+			t.Errorf("Restart %d: expected the console output of its run, got %q", i, console)
+		}
+	}
+}
+
+func TestSuperviseServerGivesUp(t *testing.T) {
+	packDir := writeSuperviseTestPack(t, "#!/bin/sh\nexit 3\n")
+
+	manager := NewManager(nil)
+	options := packwrap.SuperviseOptions{MaxRestarts: 2, Backoff: time.Millisecond}
+	err := manager.SuperviseServer(context.Background(), packDir, options)
+	if err == nil || !strings.Contains(err.Error(), "3 times in a row") {
+		t.Fatalf("Expected the supervisor to give up, got %v", err)
+	}
+
+	supervisor := readSupervisorStatus(ServerRunDir(packDir))
+	if supervisor == nil || !supervisor.GaveUp || len(supervisor.Restarts) != 3 {
+		t.Fatalf("Expected three recorded crashes, got %+v", supervisor)
+	}
+	if last := supervisor.Restarts[2]; last.ExitCode != 3 || last.Backoff != 0 {
+		t.Errorf("Expected the last crash without a restart, got %+v", last)
+	}
+}
+
+func TestSuperviseServerStops(t *testing.T) {
+	// The stand-in server fails on stop, which must not count as a crash
+	script := `#!/bin/sh
+echo started
+while read line; do [ "$line" = stop ] && exit 1; done
+`
+	stops := map[string]func(manager *Manager, packDir string, cancel context.CancelFunc) error{
+		"stop command": func(manager *Manager, packDir string, cancel context.CancelFunc) error {
+			return manager.StopServer(packDir, 5*time.Second)
+		},
+		"cancelled": func(manager *Manager, packDir string, cancel context.CancelFunc) error {
+			cancel()
+			return nil
+		},
+	}
+
+	for name, stop := range stops {
+		t.Run(name, func(t *testing.T) {
+			packDir := writeSuperviseTestPack(t, script)
+			manager := NewManager(nil)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			done := make(chan error, 1)
+			go func() {
+				done <- manager.SuperviseServer(ctx, packDir, packwrap.SuperviseOptions{Backoff: time.Millisecond})
+			}()
+
+			deadline := time.Now().Add(5 * time.Second)
+			for {
+				status, err := manager.ServerStatus(packDir)
+				if err == nil && status.Running && status.Supervisor != nil && status.Supervisor.Running {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatal("Supervised server did not start")
+				}
+				time.Sleep(20 * time.Millisecond)
+			}
+
+			if err := stop(manager, packDir, cancel); err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Fatal(err)
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatal("Supervisor did not exit")
+			}
+
+			status, err := manager.ServerStatus(packDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status.Running || status.Supervisor.Running || len(status.Supervisor.Restarts) != 0 {
+				t.Errorf("Expected everything stopped without restarts, got %+v %+v", status, status.Supervisor)
+			}
+		})
+	}
+}
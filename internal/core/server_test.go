@@ -0,0 +1,456 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/packwiz"
+)
+
+func TestMinecraftVersionPrefersVersionsTable(t *testing.T) {
+	packToml := &packwiz.PackToml{McVersion: "1.19.2"}
+	if version := MinecraftVersion(packToml); version != "1.19.2" {
+		t.Errorf("Expected fallback to mc-version, got '%s'", version)
+	}
+
+	packToml.Versions.Minecraft = "1.20.1"
+	if version := MinecraftVersion(packToml); version != "1.20.1" {
+		t.Errorf("Expected versions.minecraft, got '%s'", version)
+	}
+}
+
+func TestVerifyServerSetup(t *testing.T) {
+	runDir := t.TempDir()
+	if err := VerifyServerSetup(runDir); err == nil {
+		t.Error("Expected an error for an empty run directory")
+	}
+
+	for _, name := range []string{"server.jar", "eula.txt"} {
+		if err := os.WriteFile(filepath.Join(runDir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := VerifyServerSetup(runDir); err != nil {
+		t.Errorf("Expected a complete setup, got %v", err)
+	}
+}
+
+func TestVerifyServerSetupNeoForge(t *testing.T) {
+	runDir := t.TempDir()
+	argsFile := "unix_args.txt"
+	if runtime.GOOS == "windows" {
+		argsFile = "win_args.txt"
+	}
+	argsDir := filepath.Join(runDir, "libraries", "net", "neoforged", "neoforge", "21.1.77")
+	if err := os.MkdirAll(argsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{filepath.Join(argsDir, argsFile), filepath.Join(runDir, "user_jvm_args.txt"), filepath.Join(runDir, "eula.txt")} {
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := VerifyServerSetup(runDir); err != nil {
+		t.Fatalf("Expected a complete NeoForge setup, got %v", err)
+	}
+
+	args, err := ServerLaunchArgs(runDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"@user_jvm_args.txt", "@libraries/net/neoforged/neoforge/21.1.77/" + argsFile}
+	if strings.Join(args, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected %v, got %v", expected, args)
+	}
+}
+
+func TestNeoForgeInstallerURL(t *testing.T) {
+	tests := []struct {
+		mcVersion, version, expected string
+	}{
+		{"1.21.1", "21.1.77", "https://maven.neoforged.net/releases/net/neoforged/neoforge/21.1.77/neoforge-21.1.77-installer.jar"},
+		{"1.20.1", "47.1.106", "https://maven.neoforged.net/releases/net/neoforged/forge/1.20.1-47.1.106/forge-1.20.1-47.1.106-installer.jar"},
+		{"1.20.1", "1.20.1-47.1.106", "https://maven.neoforged.net/releases/net/neoforged/forge/1.20.1-47.1.106/forge-1.20.1-47.1.106-installer.jar"},
+	}
+	for _, test := range tests {
+		if url := neoForgeInstallerURL(test.mcVersion, test.version); url != test.expected {
+			t.Errorf("neoForgeInstallerURL(%s, %s) = %s, expected %s", test.mcVersion, test.version, url, test.expected)
+		}
+	}
+}
+
+func TestServerLaunchArgsLayouts(t *testing.T) {
+	argsFile := "unix_args.txt"
+	if runtime.GOOS == "windows" {
+		argsFile = "win_args.txt"
+	}
+
+	tests := []struct {
+		name     string
+		files    []string
+		expected string
+	}{
+		{"vanilla", []string{"server.jar"}, "-jar server.jar"},
+		{"quilt", []string{"server.jar", "quilt-server-launch.jar"}, "-jar quilt-server-launch.jar"},
+		{"forge", []string{"run.sh", "libraries/net/minecraftforge/forge/1.20.1-47.2.0/unix_args.txt", "libraries/net/minecraftforge/forge/1.20.1-47.2.0/win_args.txt"},
+			"@libraries/net/minecraftforge/forge/1.20.1-47.2.0/" + argsFile},
+		{"legacy forge", []string{"forge-1.12.2-14.23.5.2860-installer.jar", "forge-1.12.2-14.23.5.2860.jar", "minecraft_server.1.12.2.jar"}, "-jar forge-1.12.2-14.23.5.2860.jar"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runDir := t.TempDir()
+			for _, name := range tt.files {
+				path := filepath.Join(runDir, filepath.FromSlash(name))
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			args, err := ServerLaunchArgs(runDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(args, " "); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestForgeInstallerURL(t *testing.T) {
+	expected := "https://maven.minecraftforge.net/net/minecraftforge/forge/1.20.1-47.2.0/forge-1.20.1-47.2.0-installer.jar"
+	for _, version := range []string{"47.2.0", "1.20.1-47.2.0"} {
+		if url := forgeInstallerURL("1.20.1", version); url != expected {
+			t.Errorf("forgeInstallerURL(1.20.1, %s) = %s, expected %s", version, url, expected)
+		}
+	}
+}
+
+func TestVanillaServerDownload(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/manifest.json":
+			fmt.Fprintf(w, `{"versions":[{"id":"1.21.1","url":"%s/1.21.1.json"},{"id":"a1.0.4","url":"%s/a1.0.4.json"}]}`, server.URL, server.URL)
+		case "/1.21.1.json":
+			fmt.Fprint(w, `{"downloads":{"server":{"sha1":"abc","url":"https://example.com/server.jar"}}}`)
+		case "/a1.0.4.json":
+			fmt.Fprint(w, `{"downloads":{"client":{"sha1":"def","url":"https://example.com/client.jar"}}}`)
+		case "/maven-metadata.xml":
+			fmt.Fprint(w, `<metadata><versioning><latest>0.10.0-beta.1</latest><release>0.9.2</release></versioning></metadata>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	original := versionManifestURL
+	versionManifestURL = server.URL + "/manifest.json"
+	defer func() { versionManifestURL = original }()
+
+	url, sha1, err := vanillaServerDownload("1.21.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "https://example.com/server.jar" || sha1 != "abc" {
+		t.Errorf("Unexpected download %s %s", url, sha1)
+	}
+
+	if _, _, err := vanillaServerDownload("a1.0.4"); err == nil {
+		t.Error("Expected an error for a version without a server")
+	}
+	if _, _, err := vanillaServerDownload("9.9.9"); err == nil {
+		t.Error("Expected an error for an unknown version")
+	}
+
+	release, err := latestMavenRelease(server.URL + "/maven-metadata.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release != "0.9.2" {
+		t.Errorf("Expected release 0.9.2, got %s", release)
+	}
+}
+
+func TestCleanServerRemovesRunDir(t *testing.T) {
+	packDir := t.TempDir()
+	runDir := ServerRunDir(packDir)
+	if err := os.MkdirAll(runDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := NewManager(nil)
+	if err := manager.CleanServer(packDir); err != nil {
+		t.Fatalf("CleanServer failed: %v", err)
+	}
+	if _, err := os.Stat(runDir); !os.IsNotExist(err) {
+		t.Error("Expected .run to be removed")
+	}
+
+	// Cleaning again is a no-op
+	if err := manager.CleanServer(packDir); err != nil {
+		t.Errorf("Expected no error without a run directory, got %v", err)
+	}
+}
+
+type recordingLogger struct {
+	NoOpLogger
+	lines []string
+}
+
+func (l *recordingLogger) Info(msg string, args ...interface{}) {
+	l.lines = append(l.lines, args[0].(string))
+}
+
+func TestLogWriterSplitsLines(t *testing.T) {
+	logger := &recordingLogger{}
+	writer := &logWriter{logger: logger}
+
+	writer.Write([]byte("first\r\nsec"))
+	writer.Write([]byte("ond\nthird"))
+	writer.Flush()
+
+	expected := []string{"first", "second", "third"}
+	if len(logger.lines) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, logger.lines)
+	}
+	for i := range expected {
+		if logger.lines[i] != expected[i] {
+			t.Errorf("Line %d: expected '%s', got '%s'", i, expected[i], logger.lines[i])
+		}
+	}
+}
+
+func TestServerStatus(t *testing.T) {
+	packDir := t.TempDir()
+	runDir := ServerRunDir(packDir)
+	if err := os.MkdirAll(runDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	manager := NewManager(nil)
+
+	status, err := manager.ServerStatus(packDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Running || status.Port != DefaultServerPort {
+		t.Errorf("Expected a stopped server on the default port, got %+v", status)
+	}
+
+	// A live PID is reported as running, along with the configured port
+	if err := os.WriteFile(filepath.Join(runDir, "server.properties"), []byte("motd=Test\nserver-port=25570\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pidPath := filepath.Join(runDir, serverPIDFile)
+	if err := os.WriteFile(pidPath, []byte(fmt.Sprintf("%d\n", os.Getpid())), 0644); err != nil {
+		t.Fatal(err)
+	}
+	status, err = manager.ServerStatus(packDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Running || status.PID != os.Getpid() || status.Port != 25570 || status.StartedAt.IsZero() {
+		t.Errorf("Expected a running server on port 25570, got %+v", status)
+	}
+	if err := manager.CleanServer(packDir); err == nil {
+		t.Error("Expected CleanServer to refuse while the server runs")
+	}
+
+	// A PID reused by another process is not the server
+	started := processStartTime(os.Getpid())
+	if started == "" {
+		t.Fatal("Expected the start time of the test process")
+	}
+	if err := os.WriteFile(pidPath, []byte(fmt.Sprintf("%d\n%s\n", os.Getpid(), started)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if status, err = manager.ServerStatus(packDir); err != nil || !status.Running {
+		t.Errorf("Expected the recorded process to be running, got %+v (%v)", status, err)
+	}
+	if err := os.WriteFile(pidPath, []byte(fmt.Sprintf("%d\nearlier\n", os.Getpid())), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if status, err = manager.ServerStatus(packDir); err != nil || status.Running {
+		t.Errorf("Expected a reused PID to be ignored, got %+v (%v)", status, err)
+	}
+	if err := manager.StopServer(packDir, time.Second); err == nil {
+		t.Error("Expected StopServer to leave the process with the reused PID alone")
+	}
+
+	// A stale PID file is removed
+	if err := os.WriteFile(pidPath, []byte("not a pid\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if status, err = manager.ServerStatus(packDir); err != nil || status.Running {
+		t.Errorf("Expected a stale PID to be ignored, got %+v (%v)", status, err)
+	}
+	if _, err := os.Stat(pidPath); !os.IsNotExist(err) {
+		t.Error("Expected the stale PID file to be removed")
+	}
+	if err := manager.StopServer(packDir, time.Second); err == nil {
+		t.Error("Expected StopServer to fail without a running server")
+	}
+}
+
+func TestServerConsolePipe(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("detached servers have no console pipe on Windows")
+	}
+
+	path := filepath.Join(t.TempDir(), serverConsolePipe)
+	console, err := openServerConsole(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer console.Close()
+
+	if err := writeServerConsole(path, "say hi\r\n"); err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(console).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "say hi\n" {
+		t.Errorf("Expected the command on the console, got %q", line)
+	}
+}
+
+func TestServerCommandUsesServerConfig(t *testing.T) {
+	packDir := t.TempDir()
+	runDir := ServerRunDir(packDir)
+	if err := os.MkdirAll(runDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"pack.toml":              "name = \"Test\"\n[versions]\nminecraft = \"1.21.1\"\n",
+		"packwrap.toml":          "[server]\nmin-memory = \"2G\"\nmax-memory = \"8G\"\nmotd = \"Configured\"\n",
+		".run/server.jar":        "",
+		".run/eula.txt":          "eula=true\n",
+		".run/server.properties": "motd=A Minecraft Server\nmax-players=20\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(packDir, filepath.FromSlash(name)), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd, err := NewManager(nil).serverCommand(packDir, ServerRunDir(packDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args := strings.Join(cmd.Args[1:], " "); args != "-Xms2G -Xmx8G -jar server.jar nogui" {
+		t.Errorf("Unexpected server arguments: %s", args)
+	}
+
+	properties, err := os.ReadFile(filepath.Join(runDir, "server.properties"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(properties) != "motd=Configured\nmax-players=20\n" {
+		t.Errorf("Expected the configured MOTD, got %q", properties)
+	}
+}
+
+// TestServerProcessUnreadOutput stops a server whose console output nobody reads
+func TestServerProcessUnreadOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stand-in server is a shell script")
+	}
+
+	packDir := t.TempDir()
+	if err := os.MkdirAll(ServerRunDir(packDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"pack.toml":       "name = \"Test\"\n[versions]\nminecraft = \"1.21.1\"\n",
+		"packwrap.toml":   "[server]\njava = \"./fake-java.sh\"\n",
+		"fake-java.sh":    "#!/bin/sh\nseq 1000\nwhile read line; do [ \"$line\" = stop ] && exit 0; done\n",
+		".run/server.jar": "",
+		".run/eula.txt":   "eula=true\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(packDir, filepath.FromSlash(name)), []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	process, err := NewManager(nil).StartTestServer(packDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- process.Stop(5 * time.Second)
+	}()
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(20 * time.Second):
+		t.Fatal("Stop hung on the unread output")
+	}
+	if err := process.Wait(); err != nil {
+		t.Errorf("Expected a clean exit, got %v", err)
+	}
+}
+
+// TestServerProcessDeliversEveryLine reads the console of a server only after
+// it wrote far more than the channel holds
+func TestServerProcessDeliversEveryLine(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stand-in server is a shell script")
+	}
+
+	packDir := t.TempDir()
+	if err := os.MkdirAll(ServerRunDir(packDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"pack.toml":       "name = \"Test\"\n[versions]\nminecraft = \"1.21.1\"\n",
+		"packwrap.toml":   "[server]\njava = \"./fake-java.sh\"\n",
+		"fake-java.sh":    "#!/bin/sh\nseq 5000\n",
+		".run/server.jar": "",
+		".run/eula.txt":   "eula=true\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(packDir, filepath.FromSlash(name)), []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	process, err := NewManager(nil).StartTestServer(packDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := process.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	count := 0
+	for line := range process.Output() {
+		count++
+		if line != strconv.Itoa(count) {
+			t.Fatalf("Expected line %d, got %q", count, line)
+		}
+	}
+	if count != 5000 {
+		t.Errorf("Expected 5000 lines, got %d", count)
+	}
+}
@@ -19,6 +19,9 @@ var (
 func InitializeApp(app fyne.App, packManager *core.Manager) {
 	App = app
 	PackManager = packManager
+	if PackManager != nil {
+		PackManager.Subscribe(logEvents(NewGUILogger(nil)))
+	}
 
 	// Set app icon (optional)
 	App.SetIcon(nil)
@@ -0,0 +1,44 @@
+//go:build gui
+
+package gui
+
+import (
+	"github.com/Merith-TK/packwiz-wrapper/internal/core"
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
+)
+
+// newPackManager creates a manager that logs to the GUI and writes its events to the log
+func newPackManager(logger *GUILogger) *core.Manager {
+	manager := core.NewManager(logger)
+	manager.Subscribe(logEvents(logger))
+	return manager
+}
+
+// logEvents renders manager events as log lines
+func logEvents(logger *GUILogger) packwrap.EventHandler {
+	return func(event packwrap.Event) {
+		switch event.Type {
+		case packwrap.EventStepStarted, packwrap.EventExportStarted:
+			if event.Total > 0 {
+				logger.Info("[%d/%d] %s", event.Current, event.Total, event.Message)
+			} else {
+				logger.Info("%s", event.Message)
+			}
+		case packwrap.EventStepFailed:
+			logger.Error("%s: %s", event.Message, event.Error)
+		case packwrap.EventDownloadProgress:
+			// Every percent would flood the log, only report finished downloads
+			if event.Total > 0 && event.Current == event.Total {
+				logger.Info("%s: done", event.Message)
+			}
+		case packwrap.EventExportFinished:
+			if event.Path != "" {
+				logger.Info("%s: %s", event.Message, event.Path)
+			} else {
+				logger.Info("%s", event.Message)
+			}
+		default:
+			logger.Info("%s", event.Message)
+		}
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/widget"
-	"github.com/Merith-TK/packwiz-wrapper/internal/core"
 	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
 )
 
@@ -70,7 +69,7 @@ func CreateImportExportTab() fyne.CanvasObject {
 
 	// Export section
 	exportFormatSelect := widget.NewSelect(
-		[]string{"CurseForge", "Modrinth", "MultiMC", "Technic", "Server", "All"},
+		[]string{"CurseForge", "Modrinth", "MultiMC", "Prism", "Technic", "Server", "All"},
 		func(selected string) {
 			// Selection callback (optional)
 		},
@@ -113,22 +112,27 @@ func importFromFile(packDir string, filename string) {
 	}
 
 	logger := NewGUILogger(GlobalLogWidget)
-	manager := core.NewManager(logger)
+	manager := newPackManager(logger)
 
 	logger.Info("Importing mods from file: %s", filename)
 
-	err := manager.ImportFromFile(packDir, filename)
+	result, err := manager.ImportFromFile(packDir, filename)
+	if result != nil {
+		for _, line := range result.Failed() {
+			logger.Error("Line %d (%s): %s", line.Number, line.URL, line.Error)
+		}
+	}
 	if err != nil {
 		logger.Error("Failed to import from file: %s", err.Error())
 		return
 	}
 
-	logger.Info("Successfully imported mods from: %s", filename)
+	logger.Info("Successfully imported %d mods from: %s", len(result.Lines), filename)
 }
 
 func exportPack(packDir string, format string) {
 	logger := NewGUILogger(GlobalLogWidget)
-	manager := core.NewManager(logger)
+	manager := newPackManager(logger)
 
 	logger.Info("Exporting pack in %s format", format)
 
@@ -140,6 +144,8 @@ func exportPack(packDir string, format string) {
 		exportFormat = packwrap.ExportModrinth
 	case "MultiMC":
 		exportFormat = packwrap.ExportMultiMC
+	case "Prism":
+		exportFormat = packwrap.ExportPrism
 	case "Technic":
 		exportFormat = packwrap.ExportTechnic
 	case "Server":
@@ -151,24 +157,11 @@ func exportPack(packDir string, format string) {
 		return
 	}
 
-	if exportFormat == packwrap.ExportAll {
-		logger.Info("Exporting all formats...")
-		formats := []packwrap.ExportFormat{packwrap.ExportCurseForge, packwrap.ExportModrinth}
-		for _, fmt := range formats {
-			logger.Info("Exporting %s format...", fmt)
-			_, err := manager.ExportPack(packDir, fmt)
-			if err != nil {
-				logger.Error("Failed to export %s: %s", fmt, err.Error())
-			} else {
-				logger.Info("Successfully exported %s format", fmt)
-			}
-		}
-	} else {
-		_, err := manager.ExportPack(packDir, exportFormat)
-		if err != nil {
-			logger.Error("Failed to export pack: %s", err.Error())
-			return
-		}
-		logger.Info("Successfully exported pack in %s format", format)
+	artifacts, err := manager.ExportPack(packDir, exportFormat, packwrap.ExportOptions{})
+	if err != nil {
+		logger.Error("Failed to export pack: %s", err.Error())
+	}
+	if len(artifacts) > 0 {
+		logger.Info("Successfully exported %d artifact(s) in %s format", len(artifacts), format)
 	}
 }
@@ -9,7 +9,6 @@ import (
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
-	"github.com/Merith-TK/packwiz-wrapper/internal/core"
 )
 
 // CreateModsTab creates the mod management tab with dual-pane layout
@@ -159,7 +158,7 @@ func loadMods(packDir string, modData *[]*ModDisplayInfo, modList *widget.List)
 	}
 
 	logger := NewGUILogger(GlobalLogWidget)
-	manager := core.NewManager(logger)
+	manager := newPackManager(logger)
 
 	logger.Info("Loading mods from: %s", packDir)
 
@@ -3,12 +3,21 @@
 package gui
 
 import (
+	"fmt"
+	"sync"
 	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
+	"github.com/Merith-TK/packwiz-wrapper/pkg/packwrap"
+)
+
+// runningServer is the test server started from the server tab, if any
+var (
+	runningServer   packwrap.ServerProcess
+	runningServerMu sync.Mutex
 )
 
 // CreateServerTab creates the server management tab
@@ -26,33 +35,34 @@ func CreateServerTab() fyne.CanvasObject {
 		SetGlobalPackDir(text)
 	}, 500*time.Millisecond)
 
+	// Minimal status
+	statusLabel := widget.NewLabel("Status: Ready • Port: 25565 • Directory: .run/")
+	setStatus := func(status string) {
+		fyne.Do(func() {
+			statusLabel.SetText(fmt.Sprintf("Status: %s • Port: 25565 • Directory: .run/", status))
+		})
+	}
+
 	// Server buttons - simple layout
 	setupButton := widget.NewButton("⚙️ Setup", func() {
-		setupServer(GetGlobalPackDir())
+		setupServer(GetGlobalPackDir(), setStatus)
 	})
 
 	startButton := widget.NewButton("▶️ Start", func() {
-		startServer(GetGlobalPackDir())
+		startServer(GetGlobalPackDir(), setStatus)
 	})
 
 	stopButton := widget.NewButton("⏹️ Stop", func() {
-		dialog.ShowInformation("Stop Server", "Stop functionality coming soon", Window)
+		stopServer(GetGlobalPackDir(), setStatus)
 	})
 
 	cleanButton := widget.NewButton("🗑️ Clean", func() {
-		dialog.ShowConfirm("Clean Server", "Remove server files?", func(confirmed bool) {
-			if confirmed {
-				cleanServer(GetGlobalPackDir())
-			}
-		}, Window)
+		cleanServer(GetGlobalPackDir(), setStatus)
 	})
 
 	// Simple grid layout
 	buttonGrid := container.NewGridWithColumns(4, setupButton, startButton, stopButton, cleanButton)
 
-	// Minimal status
-	statusLabel := widget.NewLabel("Status: Ready • Port: 25565 • Directory: .run/")
-
 	// Compact layout
 	content := container.NewVBox(
 		packDirEntry,
@@ -64,18 +74,116 @@ func CreateServerTab() fyne.CanvasObject {
 }
 
 // Server functions
-func setupServer(packDir string) {
-	RunPwCommand("Server Setup", []string{"server", "--setup"}, packDir)
+func setupServer(packDir string, setStatus func(string)) {
+	logger := NewGUILogger(GlobalLogWidget)
+	manager := newPackManager(logger)
+
+	go func() {
+		unsubscribe := manager.Subscribe(func(event packwrap.Event) {
+			if event.Type == packwrap.EventStepStarted && event.Operation == packwrap.OperationServerSetup {
+				setStatus(fmt.Sprintf("Setting up (%d/%d)", event.Current, event.Total))
+			}
+		})
+		defer unsubscribe()
+
+		err := manager.SetupServer(packDir, nil)
+		if err != nil {
+			logger.Error("Server setup failed: %s", err.Error())
+			setStatus("Setup failed")
+			return
+		}
+		setStatus("Ready")
+	}()
+}
+
+func startServer(packDir string, setStatus func(string)) {
+	runningServerMu.Lock()
+	defer runningServerMu.Unlock()
+
+	logger := NewGUILogger(GlobalLogWidget)
+	if runningServer != nil {
+		logger.Warn("Server is already running (PID %d)", runningServer.PID())
+		return
+	}
+
+	server, err := newPackManager(logger).StartTestServer(packDir)
+	if err != nil {
+		logger.Error("Failed to start server: %s", err.Error())
+		return
+	}
+	runningServer = server
+	setStatus(fmt.Sprintf("Running (PID %d)", server.PID()))
+
+	// Stream the server console into the log tab
+	go func() {
+		for line := range server.Output() {
+			logger.Info("[server] %s", line)
+		}
+		if err := server.Wait(); err != nil {
+			logger.Warn("Server exited: %s", err.Error())
+		} else {
+			logger.Info("Server stopped")
+		}
+
+		runningServerMu.Lock()
+		runningServer = nil
+		runningServerMu.Unlock()
+		setStatus("Stopped")
+	}()
 }
 
-func startServer(packDir string) {
-	RunPwCommand("Start Server", []string{"server", "--start"}, packDir)
+func stopServer(packDir string, setStatus func(string)) {
+	runningServerMu.Lock()
+	server := runningServer
+	runningServerMu.Unlock()
+
+	if server == nil {
+		// A server started with 'pw server start --detach' is stopped through the manager
+		logger := NewGUILogger(GlobalLogWidget)
+		manager := newPackManager(logger)
+		if status, err := manager.ServerStatus(packDir); err == nil && status.Running {
+			setStatus("Stopping")
+			go func() {
+				if err := manager.StopServer(packDir, 30*time.Second); err != nil {
+					logger.Error("Failed to stop server: %s", err.Error())
+					return
+				}
+				setStatus("Stopped")
+			}()
+			return
+		}
+
+		dialog.ShowInformation("Stop Server", "No server is running", Window)
+		return
+	}
+
+	setStatus("Stopping")
+	go func() {
+		if err := server.Stop(30 * time.Second); err != nil {
+			NewGUILogger(GlobalLogWidget).Error("Failed to stop server: %s", err.Error())
+		}
+	}()
 }
 
-func cleanServer(packDir string) {
+func cleanServer(packDir string, setStatus func(string)) {
 	dialog.ShowConfirm("Clean Server", "This will remove all server files in .run/ directory. Continue?", func(confirmed bool) {
-		if confirmed {
-			RunPwCommand("Clean Server", []string{"server", "--clean"}, packDir)
+		if !confirmed {
+			return
+		}
+
+		runningServerMu.Lock()
+		running := runningServer != nil
+		runningServerMu.Unlock()
+		if running {
+			dialog.ShowInformation("Clean Server", "Stop the server before removing its files", Window)
+			return
+		}
+
+		logger := NewGUILogger(GlobalLogWidget)
+		if err := newPackManager(logger).CleanServer(packDir); err != nil {
+			logger.Error("Failed to clean server: %s", err.Error())
+			return
 		}
+		setStatus("Not set up")
 	}, Window)
 }
@@ -10,7 +10,6 @@ import (
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
-	"github.com/Merith-TK/packwiz-wrapper/internal/core"
 )
 
 // CreateWelcomeTab creates a compact welcome/getting started tab
@@ -143,7 +142,7 @@ func updateWelcomeStatusUI(packDir string, statusCard *widget.Card, packInfoWidg
 	logger := NewGUILogger(GlobalLogWidget)
 	manager := PackManager
 	if manager == nil {
-		manager = core.NewManager(logger)
+		manager = newPackManager(logger)
 	}
 
 	packInfo, err := manager.GetPackInfo(packDir)
@@ -295,7 +294,7 @@ func refreshWelcomePack(packDir string, statusCard *widget.Card, packInfoWidget
 	}
 
 	logger := NewGUILogger(GlobalLogWidget)
-	manager := core.NewManager(logger)
+	manager := newPackManager(logger)
 
 	err := manager.RefreshPack(packDir)
 	if err != nil {
@@ -0,0 +1,471 @@
+package packwiz
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Document is a TOML file that can be edited without losing formatting.
+//
+// Every line of the source is kept verbatim; only the values that are changed
+// through Set are rewritten. Comments, blank lines, key order and keys the
+// wrapper does not know about survive a load/save round trip unchanged.
+type Document struct {
+	root    *Table
+	tables  []*Table
+	newline string
+	final   bool // source ended with a newline
+}
+
+// Table is a [table] or [[array]] section of a Document. The root table holds
+// the keys that appear before the first header.
+type Table struct {
+	Name    string
+	Array   bool
+	header  string
+	entries []*docEntry
+}
+
+// docEntry is a key/value pair, or a run of comment and blank lines when key is empty
+type docEntry struct {
+	key    string
+	prefix string // everything before the value, e.g. `name = `
+	value  string // raw TOML value, may span several lines
+	suffix string // whitespace and comment after the value
+}
+
+// ParseDocument parses TOML source into an editable document
+func ParseDocument(data []byte) (*Document, error) {
+	// Validate the whole file up front so the line scanner only sees valid TOML
+	var check map[string]interface{}
+	if _, err := toml.Decode(string(data), &check); err != nil {
+		return nil, fmt.Errorf("failed to parse TOML: %w", err)
+	}
+
+	text := string(data)
+	doc := &Document{newline: "\n", root: &Table{}}
+	if strings.Contains(text, "\r\n") {
+		doc.newline = "\r\n"
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+	doc.final = strings.HasSuffix(text, "\n")
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		doc.final = true
+		return doc, nil
+	}
+
+	lines := strings.Split(text, "\n")
+	current := doc.root
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			current.entries = append(current.entries, &docEntry{prefix: line})
+
+		case strings.HasPrefix(trimmed, "["):
+			name, array := parseHeader(trimmed)
+			current = &Table{Name: name, Array: array, header: line}
+			doc.tables = append(doc.tables, current)
+
+		default:
+			eq := keyEnd(line)
+			if eq < 0 {
+				return nil, fmt.Errorf("line %d: expected key = value", i+1)
+			}
+			key := normalizeKey(line[:eq])
+
+			// Skip the spaces after '=' so the prefix keeps the original alignment
+			valueStart := eq + 1
+			for valueStart < len(line) && (line[valueStart] == ' ' || line[valueStart] == '\t') {
+				valueStart++
+			}
+
+			rest := strings.Join(lines[i:], "\n")[valueStart:]
+			valueLen, suffixLen := scanValue(rest)
+			entry := &docEntry{
+				key:    key,
+				prefix: line[:valueStart],
+				value:  rest[:valueLen],
+				suffix: rest[valueLen : valueLen+suffixLen],
+			}
+			current.entries = append(current.entries, entry)
+			i += strings.Count(rest[:valueLen+suffixLen], "\n")
+		}
+	}
+
+	return doc, nil
+}
+
+// Bytes serializes the document, keeping the original line endings
+func (d *Document) Bytes() []byte {
+	var lines []string
+	for _, table := range append([]*Table{d.root}, d.tables...) {
+		if table != d.root {
+			lines = append(lines, table.header)
+		}
+		for _, entry := range table.entries {
+			lines = append(lines, entry.prefix+entry.value+entry.suffix)
+		}
+	}
+
+	text := strings.Join(lines, "\n")
+	if d.final && len(lines) > 0 {
+		text += "\n"
+	}
+	return []byte(strings.ReplaceAll(text, "\n", d.newline))
+}
+
+// Decode decodes the document into v using the toml struct tags
+func (d *Document) Decode(v interface{}) error {
+	_, err := toml.Decode(string(d.Bytes()), v)
+	return err
+}
+
+// Table returns the first table with the given name, or the root table for "".
+// It returns nil when the table does not exist.
+func (d *Document) Table(name string) *Table {
+	if name == "" {
+		return d.root
+	}
+	for _, table := range d.tables {
+		if table.Name == name {
+			return table
+		}
+	}
+	return nil
+}
+
+// Tables returns every table with the given name, in file order.
+// This is how [[array]] sections such as index.toml's files are reached.
+func (d *Document) Tables(name string) []*Table {
+	var tables []*Table
+	for _, table := range d.tables {
+		if table.Name == name {
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+// AddTable appends a new [name] or [[name]] section to the end of the document
+func (d *Document) AddTable(name string, array bool) *Table {
+	header := "[" + name + "]"
+	if array {
+		header = "[" + header + "]"
+	}
+
+	// Separate the new section from whatever came before it
+	last := d.root
+	if len(d.tables) > 0 {
+		last = d.tables[len(d.tables)-1]
+	}
+	if len(d.tables) > 0 || len(d.root.entries) > 0 {
+		if n := len(last.entries); n == 0 || !last.entries[n-1].blank() {
+			last.entries = append(last.entries, &docEntry{})
+		}
+	}
+
+	table := &Table{Name: name, Array: array, header: header}
+	d.tables = append(d.tables, table)
+	d.final = true
+	return table
+}
+
+// RemoveTable removes a table and all of its keys from the document
+func (d *Document) RemoveTable(table *Table) bool {
+	for i, t := range d.tables {
+		if t == table {
+			d.tables = append(d.tables[:i], d.tables[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the decoded value of key in the named table
+func (d *Document) Get(table, key string) (interface{}, bool) {
+	t := d.Table(table)
+	if t == nil {
+		return nil, false
+	}
+	return t.Get(key)
+}
+
+// GetString returns a string value, or "" when the key is missing or not a string
+func (d *Document) GetString(table, key string) string {
+	value, _ := d.Get(table, key)
+	s, _ := value.(string)
+	return s
+}
+
+// Set sets key in the named table, creating the table if needed
+func (d *Document) Set(table, key string, value interface{}) error {
+	t := d.Table(table)
+	if t == nil {
+		t = d.AddTable(table, false)
+	}
+	return t.Set(key, value)
+}
+
+// Delete removes key from the named table
+func (d *Document) Delete(table, key string) bool {
+	t := d.Table(table)
+	if t == nil {
+		return false
+	}
+	return t.Delete(key)
+}
+
+// Keys returns the keys of the table in file order
+func (t *Table) Keys() []string {
+	var keys []string
+	for _, entry := range t.entries {
+		if entry.key != "" {
+			keys = append(keys, entry.key)
+		}
+	}
+	return keys
+}
+
+// Get returns the decoded value of key
+func (t *Table) Get(key string) (interface{}, bool) {
+	entry := t.find(key)
+	if entry == nil {
+		return nil, false
+	}
+
+	value, err := decodeValue(entry.value)
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// Set replaces the value of key, keeping any trailing comment, or adds the key
+// after the last key of the table when it does not exist yet
+func (t *Table) Set(key string, value interface{}) error {
+	encoded, err := encodeValue(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+
+	if entry := t.find(key); entry != nil {
+		entry.value = encoded
+		return nil
+	}
+
+	entry := &docEntry{key: key, prefix: formatKey(key) + " = ", value: encoded}
+
+	// Insert after the last key so comments that introduce the next table stay with it
+	insertAt := 0
+	for i, e := range t.entries {
+		if e.key != "" {
+			insertAt = i + 1
+		}
+	}
+	if insertAt == 0 && t.Name == "" {
+		// Root table without keys: go below leading comments but above trailing blank lines
+		insertAt = len(t.entries)
+		for insertAt > 0 && t.entries[insertAt-1].blank() {
+			insertAt--
+		}
+	}
+
+	t.entries = append(t.entries, nil)
+	copy(t.entries[insertAt+1:], t.entries[insertAt:])
+	t.entries[insertAt] = entry
+	return nil
+}
+
+// Delete removes key from the table
+func (t *Table) Delete(key string) bool {
+	for i, entry := range t.entries {
+		if entry.key == key {
+			t.entries = append(t.entries[:i], t.entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// blank reports whether the entry is an empty line
+func (e *docEntry) blank() bool {
+	return e.key == "" && strings.TrimSpace(e.prefix) == ""
+}
+
+func (t *Table) find(key string) *docEntry {
+	for _, entry := range t.entries {
+		if entry.key == key {
+			return entry
+		}
+	}
+	return nil
+}
+
+// parseHeader extracts the table name from a [table] or [[array]] header line
+func parseHeader(line string) (name string, array bool) {
+	array = strings.HasPrefix(line, "[[")
+	open, close := "[", "]"
+	if array {
+		open, close = "[[", "]]"
+	}
+
+	inner := strings.TrimPrefix(line, open)
+	if end := strings.Index(inner, close); end >= 0 {
+		inner = inner[:end]
+	}
+	return normalizeKey(inner), array
+}
+
+// keyEnd returns the index of the '=' separating key and value, ignoring '=' inside quoted keys
+func keyEnd(line string) int {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '=':
+			return i
+		}
+	}
+	return -1
+}
+
+// normalizeKey trims whitespace around a (possibly dotted) key and unquotes simple quoted parts
+func normalizeKey(raw string) string {
+	parts := strings.Split(strings.TrimSpace(raw), ".")
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if len(part) >= 2 && (part[0] == '"' || part[0] == '\'') && part[len(part)-1] == part[0] {
+			part = part[1 : len(part)-1]
+		}
+		parts[i] = part
+	}
+	return strings.Join(parts, ".")
+}
+
+// formatKey quotes a key for writing when it is not a valid bare key
+func formatKey(key string) string {
+	for _, c := range key {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return fmt.Sprintf("%q", key)
+		}
+	}
+	return key
+}
+
+// scanValue finds the end of the TOML value at the start of s. It returns the
+// length of the value itself and of the whitespace and comment that follow it
+// on the same line.
+func scanValue(s string) (valueLen, suffixLen int) {
+	depth := 0
+	i := 0
+	end := 0
+	for i < len(s) {
+		switch {
+		case strings.HasPrefix(s[i:], `"""`) || strings.HasPrefix(s[i:], `'''`):
+			delim := s[i : i+3]
+			j := i + 3
+			for j < len(s) {
+				if delim == `"""` && s[j] == '\\' {
+					j += 2
+					continue
+				}
+				if strings.HasPrefix(s[j:], delim) {
+					j += 3
+					// Up to two extra quotes may belong to the string content
+					for k := 0; k < 2 && j < len(s) && s[j] == delim[0]; k++ {
+						j++
+					}
+					break
+				}
+				j++
+			}
+			i = j
+			end = i
+
+		case s[i] == '"' || s[i] == '\'':
+			quote := s[i]
+			j := i + 1
+			for j < len(s) && s[j] != quote && s[j] != '\n' {
+				if quote == '"' && s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			i = j + 1
+			end = i
+
+		case s[i] == '[' || s[i] == '{':
+			depth++
+			i++
+			end = i
+
+		case s[i] == ']' || s[i] == '}':
+			depth--
+			i++
+			end = i
+
+		case s[i] == '#':
+			// Comment runs to the end of the line
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+
+		case s[i] == '\n':
+			if depth <= 0 {
+				return end, i - end
+			}
+			i++
+
+		case s[i] == ' ' || s[i] == '\t' || s[i] == '\r':
+			i++
+
+		default:
+			i++
+			end = i
+		}
+	}
+
+	if i > len(s) {
+		i = len(s)
+	}
+	if end > i {
+		end = i
+	}
+	return end, i - end
+}
+
+// decodeValue decodes a single raw TOML value
+func decodeValue(raw string) (interface{}, error) {
+	var holder map[string]interface{}
+	if _, err := toml.Decode("v = "+raw, &holder); err != nil {
+		return nil, err
+	}
+	return holder["v"], nil
+}
+
+// encodeValue formats a Go value as a TOML value
+func encodeValue(value interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(map[string]interface{}{"v": value}); err != nil {
+		return "", err
+	}
+
+	encoded := strings.TrimSpace(buf.String())
+	if !strings.HasPrefix(encoded, "v = ") {
+		return "", fmt.Errorf("unsupported value type %T", value)
+	}
+	return strings.TrimPrefix(encoded, "v = "), nil
+}
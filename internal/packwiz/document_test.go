@@ -0,0 +1,141 @@
+package packwiz
+
+import (
+	"strings"
+	"testing"
+)
+
+const samplePackToml = `# My pack
+name = "Test Pack" # shown in launchers
+author = "Someone"
+custom-key = 42
+
+[index]
+file = "index.toml"
+hash-format = "sha256"
+hash = "abc"
+
+# Loader versions
+[versions]
+fabric = "0.15.0"
+minecraft = "1.20.1"
+
+[options]
+acceptable-game-versions = [
+  "1.20",   # older
+  "1.20.1",
+]
+description = """
+multi
+line"""
+`
+
+func TestDocumentRoundTrip(t *testing.T) {
+	for _, source := range []string{samplePackToml, strings.ReplaceAll(samplePackToml, "\n", "\r\n"), "name = \"x\""} {
+		doc, err := ParseDocument([]byte(source))
+		if err != nil {
+			t.Fatalf("ParseDocument failed: %v", err)
+		}
+		if out := string(doc.Bytes()); out != source {
+			t.Errorf("Round trip changed the document:\n%q\n%q", source, out)
+		}
+	}
+}
+
+func TestDocumentGet(t *testing.T) {
+	doc, err := ParseDocument([]byte(samplePackToml))
+	if err != nil {
+		t.Fatalf("ParseDocument failed: %v", err)
+	}
+
+	if name := doc.GetString("", "name"); name != "Test Pack" {
+		t.Errorf("Expected name 'Test Pack', got '%s'", name)
+	}
+	if mc := doc.GetString("versions", "minecraft"); mc != "1.20.1" {
+		t.Errorf("Expected minecraft '1.20.1', got '%s'", mc)
+	}
+	versions, ok := doc.Get("options", "acceptable-game-versions")
+	if !ok || len(versions.([]interface{})) != 2 {
+		t.Errorf("Expected two acceptable versions, got %v", versions)
+	}
+	if desc := doc.GetString("options", "description"); desc != "multi\nline" {
+		t.Errorf("Expected multi-line description, got %q", desc)
+	}
+}
+
+func TestDocumentSetPreservesFormatting(t *testing.T) {
+	doc, err := ParseDocument([]byte(samplePackToml))
+	if err != nil {
+		t.Fatalf("ParseDocument failed: %v", err)
+	}
+
+	if err := doc.Set("", "name", "Renamed"); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Set("", "version", "1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Set("versions", "quilt", "0.20.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Set("options", "acceptable-game-versions", []string{"1.20.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Set("extra", "enabled", true); err != nil {
+		t.Fatal(err)
+	}
+
+	out := string(doc.Bytes())
+	for _, want := range []string{
+		`name = "Renamed" # shown in launchers`,
+		"custom-key = 42\nversion = \"1.0.0\"\n\n[index]",
+		"minecraft = \"1.20.1\"\nquilt = \"0.20.0\"\n\n[options]",
+		`acceptable-game-versions = ["1.20.1"]` + "\ndescription",
+		"# Loader versions\n[versions]",
+		"line\"\"\"\n\n[extra]\nenabled = true\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	// The result must still be valid TOML with the new values
+	var meta PackToml
+	if err := doc.Decode(&meta); err != nil {
+		t.Fatalf("Edited document does not decode: %v", err)
+	}
+	if meta.Name != "Renamed" || meta.Versions.Quilt != "0.20.0" || len(meta.Options.AcceptableGameVersions) != 1 {
+		t.Errorf("Unexpected decoded values: %+v", meta)
+	}
+}
+
+func TestDocumentArrayTables(t *testing.T) {
+	source := "hash-format = \"sha256\"\n\n[[files]]\nfile = \"a.pw.toml\"\nhash = \"1\"\nmetafile = true\n\n[[files]]\nfile = \"b.jar\"\nhash = \"2\"\n"
+	doc, err := ParseDocument([]byte(source))
+	if err != nil {
+		t.Fatalf("ParseDocument failed: %v", err)
+	}
+
+	files := doc.Tables("files")
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 file tables, got %d", len(files))
+	}
+	if err := files[1].Set("hash", "3"); err != nil {
+		t.Fatal(err)
+	}
+	doc.RemoveTable(files[0])
+
+	var index IndexToml
+	if err := doc.Decode(&index); err != nil {
+		t.Fatalf("Edited index does not decode: %v", err)
+	}
+	if len(index.Files) != 1 || index.Files[0].File != "b.jar" || index.Files[0].Hash != "3" {
+		t.Errorf("Unexpected index after edit: %+v", index.Files)
+	}
+}
+
+func TestParseDocumentRejectsInvalidToml(t *testing.T) {
+	if _, err := ParseDocument([]byte("name = ")); err == nil {
+		t.Error("Expected an error for invalid TOML")
+	}
+}
@@ -0,0 +1,184 @@
+package packwiz
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DefaultIgnores are the patterns packwiz always excludes from the index
+var DefaultIgnores = []string{
+	".git/**",
+	".gitattributes",
+	".gitignore",
+	".DS_Store",
+	"/*.zip",
+	"*.mrpack",
+	"packwiz.exe",
+	"packwiz",
+}
+
+// IgnoreFile is the name of the per-pack ignore list, using gitignore syntax
+const IgnoreFile = ".packwizignore"
+
+// IgnoreMatcher matches slash separated paths against gitignore style patterns.
+// Later patterns win, and a leading "!" re-includes a path.
+type IgnoreMatcher struct {
+	rules []ignoreRule
+}
+
+type ignoreRule struct {
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// NewIgnoreMatcher compiles gitignore style patterns. Blank lines and comments are skipped.
+func NewIgnoreMatcher(patterns ...string) *IgnoreMatcher {
+	matcher := &IgnoreMatcher{}
+	matcher.Add(patterns...)
+	return matcher
+}
+
+// LoadIgnoreMatcher returns packwiz's default ignores followed by the patterns in
+// the pack's .packwizignore, if it has one
+func LoadIgnoreMatcher(packRoot string) (*IgnoreMatcher, error) {
+	matcher := NewIgnoreMatcher(DefaultIgnores...)
+
+	file, err := os.Open(filepath.Join(packRoot, IgnoreFile))
+	if os.IsNotExist(err) {
+		return matcher, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		matcher.Add(scanner.Text())
+	}
+	return matcher, scanner.Err()
+}
+
+// Add appends patterns to the matcher
+func (m *IgnoreMatcher) Add(patterns ...string) {
+	for _, pattern := range patterns {
+		if rule, ok := compileIgnoreRule(pattern); ok {
+			m.rules = append(m.rules, rule)
+		}
+	}
+}
+
+// Match reports whether the slash separated relative path is ignored.
+// A path is also ignored when one of its parent directories is.
+func (m *IgnoreMatcher) Match(relPath string, isDir bool) bool {
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.matches(relPath, isDir) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+func (r ignoreRule) matches(relPath string, isDir bool) bool {
+	// Directory-only patterns match the directory itself or anything inside it
+	if r.dirOnly && !isDir {
+		return r.pattern.MatchString(relPath) && r.matchesParent(relPath)
+	}
+	return r.pattern.MatchString(relPath)
+}
+
+// matchesParent reports whether the match comes from a parent directory of relPath
+func (r ignoreRule) matchesParent(relPath string) bool {
+	for dir := relPath; strings.Contains(dir, "/"); {
+		dir = dir[:strings.LastIndex(dir, "/")]
+		if r.pattern.MatchString(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+func compileIgnoreRule(pattern string) (ignoreRule, bool) {
+	pattern = strings.TrimRight(pattern, " \r")
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return ignoreRule{}, false
+	}
+
+	rule := ignoreRule{}
+	if strings.HasPrefix(pattern, "!") {
+		rule.negate = true
+		pattern = pattern[1:]
+	} else if strings.HasPrefix(pattern, `\`) {
+		pattern = pattern[1:]
+	}
+
+	if strings.HasSuffix(pattern, "/") {
+		rule.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+
+	// Patterns containing a slash are relative to the pack root, others match at any depth
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	if pattern == "" {
+		return ignoreRule{}, false
+	}
+
+	prefix := "^"
+	if !anchored {
+		prefix = "^(?:.*/)?"
+	}
+
+	expr := prefix + globToRegexp(pattern) + "(?:/.*)?$"
+	compiled, err := regexp.Compile(expr)
+	if err != nil {
+		return ignoreRule{}, false
+	}
+	rule.pattern = compiled
+	return rule, true
+}
+
+// globToRegexp translates gitignore glob syntax into a regular expression
+func globToRegexp(glob string) string {
+	var expr strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			expr.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				expr.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			expr.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return expr.String()
+}
@@ -0,0 +1,170 @@
+package packwiz
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+)
+
+// PackData is a read-only snapshot of a pack's decoded metadata. Snapshots are
+// shared between callers and must not be modified; use LoadPack for edits.
+type PackData struct {
+	Location  string // directory containing pack.toml
+	Meta      PackToml
+	Index     IndexToml
+	IndexHash string // sha256 of the index file, used as the cache key
+
+	// Mods holds every metafile in the index sorted by path, with Parse.ModID set
+	// to the file name without .pw.toml and Parse.Path to its folder (e.g. "mods",
+	// empty at the pack root)
+	Mods []ModToml
+
+	// Errors holds the metafiles that could not be read or decoded
+	Errors []error
+}
+
+var (
+	packDataMu    sync.Mutex
+	packDataCache = map[string]*PackData{}
+)
+
+// ReadPack returns the decoded metadata of the pack in packLocation. Metafiles
+// are decoded concurrently and the result is cached per pack until the index
+// file changes, so repeated calls only re-read pack.toml and the index.
+func ReadPack(packLocation string) (*PackData, error) {
+	if abs, err := filepath.Abs(packLocation); err == nil {
+		packLocation = abs
+	}
+
+	meta, err := ReadPackToml(packLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	indexName := meta.Index.File
+	if indexName == "" {
+		indexName = "index.toml"
+	}
+	indexData, err := os.ReadFile(filepath.Join(packLocation, filepath.FromSlash(indexName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", indexName, err)
+	}
+	sum := sha256.Sum256(indexData)
+	indexHash := hex.EncodeToString(sum[:])
+
+	packDataMu.Lock()
+	cached := packDataCache[packLocation]
+	packDataMu.Unlock()
+	if cached != nil && cached.IndexHash == indexHash {
+		// pack.toml may change without touching the index, the metafiles are still valid
+		data := *cached
+		data.Meta = meta
+		return &data, nil
+	}
+
+	data := &PackData{Location: packLocation, Meta: meta, IndexHash: indexHash}
+	if _, err := toml.Decode(string(indexData), &data.Index); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", indexName, err)
+	}
+
+	// Metafile paths in the index are relative to the index file
+	indexDir := path.Dir(indexName)
+	var metafiles []string
+	for _, file := range data.Index.Files {
+		if file.Metafile {
+			metafiles = append(metafiles, path.Join(indexDir, file.File))
+		}
+	}
+	sort.Strings(metafiles)
+
+	data.Mods, data.Errors = decodeMetafiles(packLocation, metafiles)
+
+	packDataMu.Lock()
+	packDataCache[packLocation] = data
+	packDataMu.Unlock()
+	return data, nil
+}
+
+// ReadPackToml decodes only the pack.toml in packLocation
+func ReadPackToml(packLocation string) (PackToml, error) {
+	var meta PackToml
+	if _, err := toml.DecodeFile(filepath.Join(packLocation, "pack.toml"), &meta); err != nil {
+		return meta, fmt.Errorf("failed to decode pack.toml: %w", err)
+	}
+	return meta, nil
+}
+
+// ForgetPack drops the cached snapshot of a pack, forcing the next ReadPack to
+// decode every metafile again
+func ForgetPack(packLocation string) {
+	if abs, err := filepath.Abs(packLocation); err == nil {
+		packLocation = abs
+	}
+
+	packDataMu.Lock()
+	delete(packDataCache, packLocation)
+	packDataMu.Unlock()
+}
+
+// decodeMetafiles decodes the metafiles in parallel, keeping their order.
+// Files that fail are left out of the result and reported as errors.
+func decodeMetafiles(packLocation string, relPaths []string) ([]ModToml, []error) {
+	mods := make([]ModToml, len(relPaths))
+	errs := make([]error, len(relPaths))
+
+	jobs := runtime.NumCPU()
+	if jobs > len(relPaths) {
+		jobs = len(relPaths)
+	}
+
+	var wg sync.WaitGroup
+	queue := make(chan int)
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				mods[i], errs[i] = decodeMetafile(packLocation, relPaths[i])
+			}
+		}()
+	}
+
+	for i := range relPaths {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	var decoded []ModToml
+	var failed []error
+	for i := range relPaths {
+		if errs[i] != nil {
+			failed = append(failed, errs[i])
+			continue
+		}
+		decoded = append(decoded, mods[i])
+	}
+	return decoded, failed
+}
+
+func decodeMetafile(packLocation, relPath string) (ModToml, error) {
+	var mod ModToml
+	if _, err := toml.DecodeFile(filepath.Join(packLocation, filepath.FromSlash(relPath)), &mod); err != nil {
+		return mod, fmt.Errorf("failed to decode %s: %w", relPath, err)
+	}
+
+	mod.Parse.ModID = strings.TrimSuffix(path.Base(relPath), ".pw.toml")
+	if dir := path.Dir(relPath); dir != "." {
+		mod.Parse.Path = dir
+	}
+	return mod, nil
+}
@@ -0,0 +1,76 @@
+package packwiz
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadPackCachesByIndexHash(t *testing.T) {
+	dir := writePackFiles(t, map[string]string{
+		"pack.toml": samplePackToml,
+		"index.toml": `hash-format = "sha256"
+
+[[files]]
+file = "mods/sodium.pw.toml"
+hash = "1"
+metafile = true
+
+[[files]]
+file = "resourcepacks/faithful.pw.toml"
+hash = "2"
+metafile = true
+
+[[files]]
+file = "mods/broken.pw.toml"
+hash = "3"
+metafile = true
+`,
+		"mods/sodium.pw.toml":            "name = \"Sodium\"\nside = \"client\"\n",
+		"resourcepacks/faithful.pw.toml": "name = \"Faithful\"\nside = \"client\"\n",
+		"mods/broken.pw.toml":            "name = \n",
+	})
+	t.Cleanup(func() { ForgetPack(dir) })
+
+	pack, err := ReadPack(dir)
+	if err != nil {
+		t.Fatalf("ReadPack failed: %v", err)
+	}
+	if len(pack.Mods) != 2 || len(pack.Errors) != 1 {
+		t.Fatalf("expected 2 mods and 1 error, got %d and %d", len(pack.Mods), len(pack.Errors))
+	}
+	if mod := pack.Mods[0]; mod.Name != "Sodium" || mod.Parse.ModID != "sodium" || mod.Parse.Path != "mods" {
+		t.Errorf("unexpected first mod: %+v", mod.Parse)
+	}
+	if mod := pack.Mods[1]; mod.Parse.ModID != "faithful" || mod.Parse.Path != "resourcepacks" {
+		t.Errorf("unexpected second mod: %+v", mod.Parse)
+	}
+
+	// Metafile changes are not picked up until the index changes
+	sodium := filepath.Join(dir, "mods", "sodium.pw.toml")
+	if err := os.WriteFile(sodium, []byte("name = \"Sodium Extra\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cached, err := ReadPack(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached.Mods[0].Name != "Sodium" {
+		t.Errorf("expected cached metafile, got %q", cached.Mods[0].Name)
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index.toml"), append(index, '\n'), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fresh, err := ReadPack(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fresh.Mods[0].Name != "Sodium Extra" {
+		t.Errorf("expected metafile to be decoded again, got %q", fresh.Mods[0].Name)
+	}
+}
@@ -0,0 +1,253 @@
+package packwiz
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
+)
+
+// Pack is an editable packwiz modpack: pack.toml, index.toml and every metafile
+// listed in the index. Edits go through the underlying Documents so formatting,
+// comments and unknown keys are preserved when the pack is saved.
+type Pack struct {
+	Location string // directory containing pack.toml
+
+	Meta  PackToml  // decoded pack.toml, updated after every edit
+	Index IndexToml // decoded index.toml
+	Mods  []*Metafile
+
+	packFile  *tomlFile
+	indexFile *tomlFile
+}
+
+// Metafile is a .pw.toml file referenced by the index
+type Metafile struct {
+	Path string // slash separated, relative to the pack location
+	Mod  ModToml
+
+	file *tomlFile
+}
+
+// tomlFile ties a Document to the file it was read from
+type tomlFile struct {
+	path  string
+	doc   *Document
+	dirty bool
+}
+
+// LoadPackConfig loads pack configuration from the current directory or parent directories
+func LoadPackConfig(packDir string) (*PackToml, string, error) {
+	// Use the centralized pack.toml finding logic
+	packLocation := utils.FindPackToml(packDir)
+	if packLocation == "" {
+		return nil, "", fmt.Errorf("pack.toml not found in current directory, .minecraft subdirectory, or parent directories")
+	}
+
+	packTomlPath := filepath.Join(packLocation, "pack.toml")
+	data, err := os.ReadFile(packTomlPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read pack.toml: %w", err)
+	}
+
+	var packToml PackToml
+	if err := toml.Unmarshal(data, &packToml); err != nil {
+		return nil, "", fmt.Errorf("failed to parse pack.toml: %w", err)
+	}
+
+	return &packToml, packLocation, nil
+}
+
+// LoadPack loads the pack whose pack.toml lives in packLocation
+func LoadPack(packLocation string) (*Pack, error) {
+	pack, err := LoadPackMeta(packLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	indexName := pack.Meta.Index.File
+	if indexName == "" {
+		indexName = "index.toml"
+	}
+	indexFile, err := readTomlFile(filepath.Join(packLocation, filepath.FromSlash(indexName)))
+	if err != nil {
+		return nil, err
+	}
+	pack.indexFile = indexFile
+	if err := indexFile.doc.Decode(&pack.Index); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", indexName, err)
+	}
+
+	// Metafile paths in the index are relative to the index file
+	indexDir := path.Dir(indexName)
+	for _, file := range pack.Index.Files {
+		if !file.Metafile {
+			continue
+		}
+
+		relPath := path.Join(indexDir, file.File)
+		metaFile, err := readTomlFile(filepath.Join(packLocation, filepath.FromSlash(relPath)))
+		if err != nil {
+			return nil, err
+		}
+
+		metafile := &Metafile{Path: relPath, file: metaFile}
+		if err := metaFile.doc.Decode(&metafile.Mod); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", relPath, err)
+		}
+		pack.Mods = append(pack.Mods, metafile)
+	}
+
+	sort.Slice(pack.Mods, func(i, j int) bool {
+		return pack.Mods[i].Path < pack.Mods[j].Path
+	})
+
+	return pack, nil
+}
+
+// LoadPackMeta loads only the pack.toml in packLocation, for edits that must not
+// depend on the index or the metafiles being readable. Index and Mods stay
+// empty, and Save only writes pack.toml.
+func LoadPackMeta(packLocation string) (*Pack, error) {
+	pack := &Pack{Location: packLocation}
+
+	packFile, err := readTomlFile(filepath.Join(packLocation, "pack.toml"))
+	if err != nil {
+		return nil, err
+	}
+	pack.packFile = packFile
+	if err := packFile.doc.Decode(&pack.Meta); err != nil {
+		return nil, fmt.Errorf("failed to decode pack.toml: %w", err)
+	}
+	return pack, nil
+}
+
+// Document returns the editable pack.toml document
+func (p *Pack) Document() *Document {
+	return p.packFile.doc
+}
+
+// IndexDocument returns the editable index document, or nil when only
+// pack.toml was loaded
+func (p *Pack) IndexDocument() *Document {
+	if p.indexFile == nil {
+		return nil
+	}
+	return p.indexFile.doc
+}
+
+// Set changes a pack.toml value, creating the table and key if they are missing.
+// Use table "" for top level keys such as name and version.
+func (p *Pack) Set(table, key string, value interface{}) error {
+	if err := p.packFile.doc.Set(table, key, value); err != nil {
+		return err
+	}
+	p.packFile.dirty = true
+	p.Meta = PackToml{}
+	return p.packFile.doc.Decode(&p.Meta)
+}
+
+// Delete removes a pack.toml value
+func (p *Pack) Delete(table, key string) error {
+	if !p.packFile.doc.Delete(table, key) {
+		return nil
+	}
+	p.packFile.dirty = true
+	p.Meta = PackToml{}
+	return p.packFile.doc.Decode(&p.Meta)
+}
+
+// MarkIndexChanged records that the index document was edited directly
+func (p *Pack) MarkIndexChanged() error {
+	if p.indexFile == nil {
+		return fmt.Errorf("the index of %s was not loaded", p.Location)
+	}
+	p.indexFile.dirty = true
+	p.Index = IndexToml{}
+	return p.indexFile.doc.Decode(&p.Index)
+}
+
+// Mod returns the metafile with the given name (file name without .pw.toml) or path
+func (p *Pack) Mod(name string) *Metafile {
+	for _, mod := range p.Mods {
+		if mod.Path == name || mod.ID() == name {
+			return mod
+		}
+	}
+	return nil
+}
+
+// Save writes every changed file back to disk
+func (p *Pack) Save() error {
+	files := []*tomlFile{p.packFile, p.indexFile}
+	for _, mod := range p.Mods {
+		files = append(files, mod.file)
+	}
+
+	for _, file := range files {
+		if err := file.save(); err != nil {
+			return err
+		}
+	}
+
+	// Metafile edits do not change the index until a refresh
+	ForgetPack(p.Location)
+	return nil
+}
+
+// ID returns the metafile name without its directory and .pw.toml suffix
+func (m *Metafile) ID() string {
+	return strings.TrimSuffix(path.Base(m.Path), ".pw.toml")
+}
+
+// Document returns the editable metafile document
+func (m *Metafile) Document() *Document {
+	return m.file.doc
+}
+
+// Set changes a metafile value, creating the table and key if they are missing.
+// The metafile hash in the index is not updated; refresh the pack afterwards.
+func (m *Metafile) Set(table, key string, value interface{}) error {
+	if err := m.file.doc.Set(table, key, value); err != nil {
+		return err
+	}
+	m.file.dirty = true
+	m.Mod = ModToml{}
+	return m.file.doc.Decode(&m.Mod)
+}
+
+func readTomlFile(filePath string) (*tomlFile, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(filePath), err)
+	}
+
+	doc, err := ParseDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(filePath), err)
+	}
+
+	return &tomlFile{path: filePath, doc: doc}, nil
+}
+
+func (f *tomlFile) save() error {
+	if f == nil || !f.dirty {
+		return nil
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(f.path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	if err := os.WriteFile(f.path, f.doc.Bytes(), mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(f.path), err)
+	}
+	f.dirty = false
+	return nil
+}
@@ -0,0 +1,206 @@
+package packwiz
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePackFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadPackAndSave(t *testing.T) {
+	dir := writePackFiles(t, map[string]string{
+		"pack.toml": samplePackToml,
+		"index.toml": `hash-format = "sha256"
+
+[[files]]
+file = "mods/sodium.pw.toml"
+hash = "1"
+metafile = true
+
+[[files]]
+file = "config/options.txt"
+hash = "2"
+`,
+		"mods/sodium.pw.toml": `name = "Sodium"
+filename = "sodium.jar"
+side = "client"
+
+[download]
+url = "https://example.com/sodium.jar"
+hash-format = "sha1"
+hash = "ff"
+
+[update.modrinth]
+mod-id = "AANobbMI"
+version = "abc"
+`,
+	})
+
+	pack, err := LoadPack(dir)
+	if err != nil {
+		t.Fatalf("LoadPack failed: %v", err)
+	}
+
+	if pack.Meta.Name != "Test Pack" || len(pack.Index.Files) != 2 || len(pack.Mods) != 1 {
+		t.Fatalf("Unexpected pack contents: %+v", pack.Meta)
+	}
+
+	sodium := pack.Mod("sodium")
+	if sodium == nil || sodium.Mod.Update.Modrinth.ModID != "AANobbMI" {
+		t.Fatalf("Expected to find sodium metafile, got %+v", sodium)
+	}
+
+	if err := pack.Set("versions", "minecraft", "1.20.4"); err != nil {
+		t.Fatal(err)
+	}
+	if pack.Meta.Versions.Minecraft != "1.20.4" {
+		t.Errorf("Expected typed view to follow edits, got %s", pack.Meta.Versions.Minecraft)
+	}
+	if err := sodium.Set("", "side", "both"); err != nil {
+		t.Fatal(err)
+	}
+	if err := pack.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, "pack.toml"))
+	if !strings.Contains(string(data), "custom-key = 42") || !strings.Contains(string(data), `minecraft = "1.20.4"`) {
+		t.Errorf("pack.toml lost content or edit:\n%s", data)
+	}
+	data, _ = os.ReadFile(filepath.Join(dir, "mods", "sodium.pw.toml"))
+	if !strings.Contains(string(data), `side = "both"`) || !strings.Contains(string(data), "[update.modrinth]") {
+		t.Errorf("metafile lost content or edit:\n%s", data)
+	}
+}
+
+func TestLoadPackMetaOnlyTouchesPackToml(t *testing.T) {
+	dir := writePackFiles(t, map[string]string{
+		"pack.toml":  samplePackToml,
+		"index.toml": "this is not toml [[[",
+	})
+
+	if _, err := LoadPack(dir); err == nil {
+		t.Fatal("Expected LoadPack to fail on a broken index")
+	}
+
+	pack, err := LoadPackMeta(dir)
+	if err != nil {
+		t.Fatalf("LoadPackMeta failed: %v", err)
+	}
+	if pack.Meta.Name != "Test Pack" || pack.IndexDocument() != nil || len(pack.Mods) != 0 {
+		t.Fatalf("Unexpected pack contents: %+v", pack)
+	}
+	if err := pack.MarkIndexChanged(); err == nil {
+		t.Error("Expected MarkIndexChanged to fail without an index")
+	}
+
+	if err := pack.Set("versions", "minecraft", "1.20.4"); err != nil {
+		t.Fatal(err)
+	}
+	if err := pack.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, "pack.toml"))
+	if !strings.Contains(string(data), `minecraft = "1.20.4"`) {
+		t.Errorf("pack.toml missing edit:\n%s", data)
+	}
+	index, _ := os.ReadFile(filepath.Join(dir, "index.toml"))
+	if string(index) != "this is not toml [[[" {
+		t.Errorf("index.toml was rewritten:\n%s", index)
+	}
+}
+
+func TestLoadPackConfig(t *testing.T) {
+	// Create a temporary directory with a valid pack.toml
+	tmpDir := t.TempDir()
+	packTomlContent := `name = "Test Pack"
+author = "Test Author"
+version = "1.0.0"
+pack-format = "packwiz:1.1.0"
+
+[index]
+file = "index.toml"
+hash-format = "sha256"
+
+[versions]
+minecraft = "1.20.1"
+`
+
+	packTomlPath := filepath.Join(tmpDir, "pack.toml")
+	if err := os.WriteFile(packTomlPath, []byte(packTomlContent), 0644); err != nil {
+		t.Fatalf("Failed to create test pack.toml: %v", err)
+	}
+
+	packToml, location, err := LoadPackConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to load pack config: %v", err)
+	}
+
+	if location != tmpDir {
+		t.Errorf("Expected location %s, got %s", tmpDir, location)
+	}
+
+	if packToml.Name != "Test Pack" {
+		t.Errorf("Expected pack name 'Test Pack', got '%s'", packToml.Name)
+	}
+
+	if packToml.Author != "Test Author" {
+		t.Errorf("Expected author 'Test Author', got '%s'", packToml.Author)
+	}
+
+	if packToml.Version != "1.0.0" {
+		t.Errorf("Expected version '1.0.0', got '%s'", packToml.Version)
+	}
+}
+
+func TestLoadPackConfigNotFound(t *testing.T) {
+	// Test with directory that has no pack.toml
+	tmpDir := t.TempDir()
+
+	_, _, err := LoadPackConfig(tmpDir)
+	if err == nil {
+		t.Error("Expected error when pack.toml not found, got nil")
+	}
+
+	if !strings.Contains(err.Error(), "pack.toml not found") {
+		t.Errorf("Expected 'pack.toml not found' error, got: %v", err)
+	}
+}
+
+func TestLoadPackConfigInvalidToml(t *testing.T) {
+	// Test with invalid TOML content
+	tmpDir := t.TempDir()
+	packTomlContent := `name = "Invalid TOML"
+[invalid toml syntax
+`
+
+	packTomlPath := filepath.Join(tmpDir, "pack.toml")
+	if err := os.WriteFile(packTomlPath, []byte(packTomlContent), 0644); err != nil {
+		t.Fatalf("Failed to create invalid pack.toml: %v", err)
+	}
+
+	_, _, err := LoadPackConfig(tmpDir)
+	if err == nil {
+		t.Error("Expected error when pack.toml is invalid, got nil")
+	}
+
+	if !strings.Contains(err.Error(), "failed to parse pack.toml") {
+		t.Errorf("Expected 'failed to parse pack.toml' error, got: %v", err)
+	}
+}
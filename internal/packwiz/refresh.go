@@ -0,0 +1,474 @@
+package packwiz
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Merith-TK/packwiz-wrapper/internal/config"
+	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
+)
+
+// RefreshOptions controls a native index refresh
+type RefreshOptions struct {
+	Jobs     int    // files hashed in parallel, defaults to the CPU count
+	NoCache  bool   // rehash every file instead of trusting the cache
+	CacheDir string // where the size/mtime cache is kept, defaults to utils.RefreshCacheDir
+}
+
+// RefreshResult summarizes what a refresh changed
+type RefreshResult struct {
+	Files   int // files in the index after the refresh
+	Hashed  int // files that had to be read and hashed
+	Added   int
+	Updated int
+	Removed int
+	Changed bool // index.toml or pack.toml was rewritten
+
+	IgnoreUpdated bool // packwrap.toml was added to .packwizignore
+}
+
+// indexEntry is a [[files]] entry in the order packwiz writes its fields
+type indexEntry struct {
+	File       string `toml:"file"`
+	Hash       string `toml:"hash"`
+	HashFormat string `toml:"hash-format,omitempty"`
+	Alias      string `toml:"alias,omitempty"`
+	Metafile   bool   `toml:"metafile,omitempty"`
+	Preserve   bool   `toml:"preserve,omitempty"`
+}
+
+// indexFile is index.toml as packwiz writes it
+type indexFile struct {
+	HashFormat string       `toml:"hash-format"`
+	Files      []indexEntry `toml:"files"`
+}
+
+// refreshCache remembers the hash of every file by size and modification time
+type refreshCache struct {
+	Version int                          `json:"version"`
+	Files   map[string]refreshCacheEntry `json:"files"`
+}
+
+type refreshCacheEntry struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mtime"`
+	SHA256  string `json:"sha256"`
+}
+
+// Refresh updates index.toml and the index hash in pack.toml the same way
+// 'packwiz refresh' does, but only rehashes files whose size or modification
+// time changed since the last refresh. Hashing runs in parallel.
+//
+// index.toml is written in packwiz's format; pack.toml is edited in place so
+// its formatting is kept. The cache lives outside the pack, and packwrap.toml
+// is added to .packwizignore so packwiz's own refresh skips it too.
+func Refresh(packLocation string, options RefreshOptions) (*RefreshResult, error) {
+	packDoc, err := readTomlFile(filepath.Join(packLocation, "pack.toml"))
+	if err != nil {
+		return nil, err
+	}
+	var meta PackToml
+	if err := packDoc.doc.Decode(&meta); err != nil {
+		return nil, fmt.Errorf("failed to decode pack.toml: %w", err)
+	}
+
+	indexName := meta.Index.File
+	if indexName == "" {
+		indexName = "index.toml"
+	}
+	indexPath := filepath.Join(packLocation, filepath.FromSlash(indexName))
+	packRoot := filepath.Dir(indexPath)
+
+	var index indexFile
+	if data, err := os.ReadFile(indexPath); err == nil {
+		if _, err := toml.Decode(string(data), &index); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", indexName, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", indexName, err)
+	}
+	if index.HashFormat == "" {
+		index.HashFormat = "sha256"
+	}
+
+	// Update .packwizignore before listing so this refresh already honors it
+	ignoreUpdated, err := ignoreWrapperConfig(packLocation, packRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := listPackFiles(packRoot, []string{
+		filepath.Join(packLocation, "pack.toml"),
+		indexPath,
+		// The wrapper config only matters to pw and must not be shipped with the pack
+		filepath.Join(packLocation, config.FileName),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cachePath := refreshCachePath(options.CacheDir, packRoot)
+	cache := loadRefreshCache(cachePath)
+	if options.NoCache {
+		cache.Files = map[string]refreshCacheEntry{}
+	}
+
+	hashes, hashed, newCache, err := hashPackFiles(packRoot, files, cache, options.Jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RefreshResult{Hashed: hashed, IgnoreUpdated: ignoreUpdated}
+	index.Files, result.Added, result.Updated, result.Removed = mergeIndexEntries(index, files, hashes)
+	result.Files = len(index.Files)
+
+	// Write index.toml only when it actually changed
+	var encoded bytes.Buffer
+	encoder := toml.NewEncoder(&encoded)
+	encoder.Indent = ""
+	if err := encoder.Encode(index); err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", indexName, err)
+	}
+	if existing, err := os.ReadFile(indexPath); err != nil || !bytes.Equal(existing, encoded.Bytes()) {
+		if err := os.WriteFile(indexPath, encoded.Bytes(), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", indexName, err)
+		}
+		result.Changed = true
+	}
+
+	// Update the index hash in pack.toml
+	hashFormat := meta.Index.HashFormat
+	if hashFormat == "" {
+		hashFormat = "sha256"
+	}
+	hasher, err := utils.NewFileHasher(hashFormat)
+	if err != nil {
+		return nil, err
+	}
+	hasher.Write(encoded.Bytes())
+	indexHash := hasher.String()
+	if meta.Index.Hash != indexHash || meta.Index.HashFormat != hashFormat {
+		if err := packDoc.doc.Set("index", "hash-format", hashFormat); err != nil {
+			return nil, err
+		}
+		if err := packDoc.doc.Set("index", "hash", indexHash); err != nil {
+			return nil, err
+		}
+		packDoc.dirty = true
+		if err := packDoc.save(); err != nil {
+			return nil, err
+		}
+		result.Changed = true
+	}
+
+	if err := saveRefreshCache(cachePath, newCache); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// listPackFiles walks the pack root and returns the slash separated paths of
+// every file packwiz would index
+func listPackFiles(packRoot string, exclude []string) ([]string, error) {
+	ignore, err := LoadIgnoreMatcher(packRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", IgnoreFile, err)
+	}
+
+	excluded := map[string]bool{}
+	for _, file := range exclude {
+		if abs, err := filepath.Abs(file); err == nil {
+			excluded[abs] = true
+		}
+	}
+
+	var files []string
+	err = filepath.WalkDir(packRoot, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if filePath == packRoot {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(packRoot, filePath)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if entry.IsDir() {
+			if ignore.Match(relPath, true) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if abs, err := filepath.Abs(filePath); err == nil && excluded[abs] {
+			return nil
+		}
+		if ignore.Match(relPath, false) {
+			return nil
+		}
+
+		files = append(files, relPath)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pack files: %w", err)
+	}
+
+	return files, nil
+}
+
+// hashPackFiles returns the sha256 of every file, reusing cached hashes for
+// files whose size and modification time are unchanged
+func hashPackFiles(packRoot string, files []string, cache *refreshCache, jobs int) (map[string]string, int, *refreshCache, error) {
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+
+	hashes := make(map[string]string, len(files))
+	newCache := &refreshCache{Version: 1, Files: make(map[string]refreshCacheEntry, len(files))}
+
+	type job struct {
+		relPath string
+		info    fs.FileInfo
+	}
+	var pending []job
+
+	for _, relPath := range files {
+		info, err := os.Stat(filepath.Join(packRoot, filepath.FromSlash(relPath)))
+		if err != nil {
+			return nil, 0, nil, fmt.Errorf("failed to stat %s: %w", relPath, err)
+		}
+
+		cached, ok := cache.Files[relPath]
+		if ok && cached.Size == info.Size() && cached.ModTime == info.ModTime().UnixNano() && cached.SHA256 != "" {
+			hashes[relPath] = cached.SHA256
+			newCache.Files[relPath] = cached
+			continue
+		}
+		pending = append(pending, job{relPath: relPath, info: info})
+	}
+
+	var mu sync.Mutex
+	var firstErr error
+	var wg sync.WaitGroup
+	queue := make(chan job)
+
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range queue {
+				sum, err := utils.HashFile(filepath.Join(packRoot, filepath.FromSlash(j.relPath)), "sha256")
+
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = fmt.Errorf("failed to hash %s: %w", j.relPath, err)
+					}
+				} else {
+					hashes[j.relPath] = sum
+					newCache.Files[j.relPath] = refreshCacheEntry{
+						Size:    j.info.Size(),
+						ModTime: j.info.ModTime().UnixNano(),
+						SHA256:  sum,
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, j := range pending {
+		queue <- j
+	}
+	close(queue)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, 0, nil, firstErr
+	}
+	return hashes, len(pending), newCache, nil
+}
+
+// mergeIndexEntries applies the fresh hashes to the existing index entries the
+// way packwiz does: entries keep their alias and preserve flags, new files are
+// added, and entries for files that no longer exist are dropped
+func mergeIndexEntries(index indexFile, files []string, hashes map[string]string) (entries []indexEntry, added, updated, removed int) {
+	found := make(map[string]bool, len(files))
+	for _, file := range files {
+		found[file] = true
+	}
+
+	seen := map[string]bool{}
+	for _, entry := range index.Files {
+		entry.File = path.Clean(entry.File)
+		if !found[entry.File] {
+			removed++
+			continue
+		}
+		seen[entry.File] = true
+
+		hashFormat := ""
+		if index.HashFormat != "sha256" {
+			hashFormat = "sha256"
+		}
+		metafile := strings.HasSuffix(entry.File, ".pw.toml")
+		if entry.Hash != hashes[entry.File] || entry.HashFormat != hashFormat || entry.Metafile != metafile {
+			updated++
+		}
+		entry.Hash = hashes[entry.File]
+		entry.HashFormat = hashFormat
+		entry.Metafile = metafile
+		entries = append(entries, entry)
+	}
+
+	for _, file := range files {
+		if seen[file] {
+			continue
+		}
+		entry := indexEntry{
+			File:     file,
+			Hash:     hashes[file],
+			Metafile: strings.HasSuffix(file, ".pw.toml"),
+		}
+		if index.HashFormat != "sha256" {
+			entry.HashFormat = "sha256"
+		}
+		entries = append(entries, entry)
+		added++
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].File == entries[j].File {
+			return entries[i].Alias < entries[j].Alias
+		}
+		return entries[i].File < entries[j].File
+	})
+
+	return entries, added, updated, removed
+}
+
+// refreshCachePath returns the cache file for the pack rooted at packRoot,
+// keyed by its absolute path so every pack gets its own file
+func refreshCachePath(cacheDir, packRoot string) string {
+	if cacheDir == "" {
+		cacheDir = utils.RefreshCacheDir()
+	}
+	if abs, err := filepath.Abs(packRoot); err == nil {
+		packRoot = abs
+	}
+	sum := sha256.Sum256([]byte(packRoot))
+	return filepath.Join(cacheDir, hex.EncodeToString(sum[:8])+".json")
+}
+
+func loadRefreshCache(cachePath string) *refreshCache {
+	cache := &refreshCache{Version: 1, Files: map[string]refreshCacheEntry{}}
+
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		return cache
+	}
+
+	var loaded refreshCache
+	if err := json.Unmarshal(data, &loaded); err != nil || loaded.Version != 1 || loaded.Files == nil {
+		// A corrupt or outdated cache just means everything is rehashed
+		return cache
+	}
+	return &loaded
+}
+
+func saveRefreshCache(cachePath string, cache *refreshCache) error {
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return fmt.Errorf("failed to encode refresh cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return fmt.Errorf("failed to create refresh cache directory: %w", err)
+	}
+	if err := os.WriteFile(cachePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write refresh cache: %w", err)
+	}
+	return nil
+}
+
+// IgnoreWrapperConfig adds packwrap.toml to the .packwizignore of the pack in
+// packLocation, so packwiz commands that refresh the index never pick it up.
+// It reports whether .packwizignore was changed.
+func IgnoreWrapperConfig(packLocation string) (bool, error) {
+	packDoc, err := readTomlFile(filepath.Join(packLocation, "pack.toml"))
+	if err != nil {
+		return false, err
+	}
+	var meta PackToml
+	if err := packDoc.doc.Decode(&meta); err != nil {
+		return false, fmt.Errorf("failed to decode pack.toml: %w", err)
+	}
+
+	indexName := meta.Index.File
+	if indexName == "" {
+		indexName = "index.toml"
+	}
+	return ignoreWrapperConfig(packLocation, filepath.Dir(filepath.Join(packLocation, filepath.FromSlash(indexName))))
+}
+
+// ignoreWrapperConfig adds packwrap.toml to the .packwizignore in packRoot
+// when the pack has one inside the indexed tree
+func ignoreWrapperConfig(packLocation, packRoot string) (bool, error) {
+	configPath := filepath.Join(packLocation, config.FileName)
+	if _, err := os.Stat(configPath); err != nil {
+		return false, nil
+	}
+	rel, err := filepath.Rel(packRoot, configPath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false, nil
+	}
+	return ensureIgnored(packRoot, filepath.ToSlash(rel), "pw wrapper config")
+}
+
+// ensureIgnored adds name to the pack's .packwizignore, under comment, so
+// packwiz itself never indexes files that only pw uses
+func ensureIgnored(packRoot, name, comment string) (bool, error) {
+	ignorePath := filepath.Join(packRoot, IgnoreFile)
+	pattern := "/" + name
+
+	data, err := os.ReadFile(ignorePath)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read %s: %w", IgnoreFile, err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return false, nil
+		}
+	}
+
+	newline := "\n"
+	if bytes.Contains(data, []byte("\r\n")) {
+		newline = "\r\n"
+	}
+	content := string(data)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += newline
+	}
+	content += "# " + comment + newline + pattern + newline
+
+	if err := os.WriteFile(ignorePath, []byte(content), 0644); err != nil {
+		return false, fmt.Errorf("failed to update %s: %w", IgnoreFile, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,189 @@
+package packwiz
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Merith-TK/packwiz-wrapper/internal/utils"
+)
+
+func TestRefreshWritesPackwizIndex(t *testing.T) {
+	dir := writePackFiles(t, map[string]string{
+		"pack.toml": `# keep me
+name = "Refresh Pack"
+pack-format = "packwiz:1.1.0"
+
+[index]
+file = "index.toml"
+hash-format = "sha256"
+hash = ""
+
+[versions]
+minecraft = "1.20.1"
+`,
+		"index.toml":          "hash-format = \"sha256\"\n\n[[files]]\nfile = \"config/gone.txt\"\nhash = \"00\"\n",
+		"config/b.txt":        "b",
+		"config/a.txt":        "a",
+		"mods/sodium.pw.toml": "name = \"Sodium\"\n",
+		"export.zip":          "ignored by default",
+		"nested/keep.zip":     "only root zips are ignored",
+		".git/HEAD":           "ignored",
+		"resourcepacks/x.zip": "kept",
+		"private/secret.txt":  "ignored by .packwizignore",
+		".packwizignore":      "private/\n",
+		"packwrap.toml":       "[build]\n",
+	})
+
+	options := RefreshOptions{CacheDir: t.TempDir()}
+	result, err := Refresh(dir, options)
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if !result.Changed || result.Removed != 1 || !result.IgnoreUpdated {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if entries, _ := os.ReadDir(options.CacheDir); len(entries) != 1 {
+		t.Errorf("Expected one cache file outside the pack, got %d", len(entries))
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, "index.toml"))
+	index := string(data)
+	expectedOrder := []string{".packwizignore", "config/a.txt", "config/b.txt", "mods/sodium.pw.toml", "nested/keep.zip", "resourcepacks/x.zip"}
+	last := -1
+	for _, file := range expectedOrder {
+		pos := strings.Index(index, `file = "`+file+`"`)
+		if pos < 0 || pos < last {
+			t.Errorf("Expected %s in sorted position in index:\n%s", file, index)
+		}
+		last = pos
+	}
+	for _, ignored := range []string{"export.zip\"", ".git/HEAD", "gone.txt", "secret.txt", ".json", "packwrap.toml", "pack.toml", "\"index.toml"} {
+		if strings.Contains(index, ignored) {
+			t.Errorf("Index should not contain %s:\n%s", ignored, index)
+		}
+	}
+	if ignoreData, _ := os.ReadFile(filepath.Join(dir, IgnoreFile)); string(ignoreData) != "private/\n# pw wrapper config\n/packwrap.toml\n" {
+		t.Errorf("Expected packwrap.toml to be added to %s, got %q", IgnoreFile, ignoreData)
+	}
+	if !strings.HasPrefix(index, "hash-format = \"sha256\"\n\n[[files]]\nfile = ") {
+		t.Errorf("Index is not in packwiz format:\n%s", index)
+	}
+	if !strings.Contains(index, "file = \"mods/sodium.pw.toml\"\nhash = \"") || !strings.Contains(index, "\nmetafile = true\n") {
+		t.Errorf("Expected metafile entry:\n%s", index)
+	}
+
+	// pack.toml keeps its formatting and gets the new index hash
+	indexHash, _ := utils.HashFile(filepath.Join(dir, "index.toml"), "sha256")
+	packData, _ := os.ReadFile(filepath.Join(dir, "pack.toml"))
+	if !strings.Contains(string(packData), "# keep me") || !strings.Contains(string(packData), `hash = "`+indexHash+`"`) {
+		t.Errorf("pack.toml not updated in place:\n%s", packData)
+	}
+
+	// A second refresh uses the cache and changes nothing
+	again, err := Refresh(dir, options)
+	if err != nil {
+		t.Fatalf("Second refresh failed: %v", err)
+	}
+	if again.Changed || again.Hashed != 0 || again.IgnoreUpdated {
+		t.Errorf("Expected a cached no-op refresh, got %+v", again)
+	}
+
+	// Changing one file only rehashes that file
+	later := time.Now().Add(time.Minute)
+	if err := os.WriteFile(filepath.Join(dir, "config", "a.txt"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(filepath.Join(dir, "config", "a.txt"), later, later)
+
+	changed, err := Refresh(dir, options)
+	if err != nil {
+		t.Fatalf("Third refresh failed: %v", err)
+	}
+	if !changed.Changed || changed.Hashed != 1 || changed.Updated != 1 {
+		t.Errorf("Expected exactly one rehashed and updated file, got %+v", changed)
+	}
+}
+
+func TestPackwizRefreshSkipsWrapperFiles(t *testing.T) {
+	dir := writePackFiles(t, map[string]string{
+		"pack.toml": `name = "Refresh Pack"
+pack-format = "packwiz:1.1.0"
+
+[index]
+file = "index.toml"
+hash-format = "sha256"
+hash = ""
+
+[versions]
+minecraft = "1.20.1"
+`,
+		"index.toml":    "hash-format = \"sha256\"\n",
+		"config/a.txt":  "a",
+		"packwrap.toml": "[build]\n",
+	})
+
+	// A native refresh followed by packwiz's own, as after 'pw refresh' and 'pw add'
+	if _, err := Refresh(dir, RefreshOptions{CacheDir: t.TempDir()}); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	runner := &Runner{Dir: dir}
+	if _, err := runner.Run(context.Background(), "refresh", "--pack-file", filepath.Join(dir, "pack.toml")); err != nil {
+		t.Fatalf("packwiz refresh failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, "index.toml"))
+	if !strings.Contains(string(data), "config/a.txt") {
+		t.Fatalf("packwiz did not refresh the index:\n%s", data)
+	}
+	for _, ignored := range []string{"packwrap.toml", ".json"} {
+		if strings.Contains(string(data), ignored) {
+			t.Errorf("Index should not contain %s:\n%s", ignored, data)
+		}
+	}
+
+	// Passthrough commands ignore the config without a native refresh first
+	if err := os.WriteFile(filepath.Join(dir, IgnoreFile), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if updated, err := IgnoreWrapperConfig(dir); err != nil || !updated {
+		t.Fatalf("Expected packwrap.toml to be ignored, got %v, %v", updated, err)
+	}
+	if updated, err := IgnoreWrapperConfig(dir); err != nil || updated {
+		t.Errorf("Expected a second call to change nothing, got %v, %v", updated, err)
+	}
+}
+
+func TestIgnoreMatcher(t *testing.T) {
+	matcher := NewIgnoreMatcher(DefaultIgnores...)
+	matcher.Add("*.log", "!keep.log", "build/", "/docs/*.md", "**/tmp")
+
+	cases := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{".git", true, false},
+		{".git/config", false, true},
+		{"pack.zip", false, true},
+		{"mods/pack.zip", false, false},
+		{"packwiz", false, true},
+		{"a/latest.log", false, true},
+		{"a/keep.log", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"build/out.txt", false, true},
+		{"docs/readme.md", false, true},
+		{"docs/sub/readme.md", false, false},
+		{"x/y/tmp", true, true},
+	}
+
+	for _, c := range cases {
+		if got := matcher.Match(c.path, c.isDir); got != c.ignored {
+			t.Errorf("Match(%q, dir=%v) = %v, expected %v", c.path, c.isDir, got, c.ignored)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package packwiz
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/packwiz/packwiz/cmd"
+	"github.com/spf13/cobra"
+)
+
+// ChildEnv marks a process started by Run as a packwiz child.
+// main checks it before doing anything else and hands control to RunChild.
+const ChildEnv = "PACKWRAP_PACKWIZ_CHILD"
+
+// rootProbe is a hidden command registered with packwiz so the unexported
+// root command can be reached through Root()
+var rootProbe = &cobra.Command{
+	Use:    "__packwrap-probe",
+	Hidden: true,
+	Run:    func(*cobra.Command, []string) {},
+}
+
+func init() {
+	cmd.Add(rootProbe)
+}
+
+// Result holds the captured output of a packwiz invocation
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// Runner invokes packwiz and reports failures as errors.
+//
+// packwiz command handlers call os.Exit directly on failure, so each call runs
+// in a child copy of this executable (see RunChild) where an exit can only end
+// the child. The child drives packwiz through cobra and exits non-zero when the
+// command returns an error.
+type Runner struct {
+	Dir    string    // working directory for packwiz, usually the pack location
+	Stdout io.Writer // optional live copy of stdout
+	Stderr io.Writer // optional live copy of stderr
+}
+
+// Run executes packwiz with args, returning the captured output. A non-zero
+// exit is returned as an error carrying the last line packwiz printed.
+func (r *Runner) Run(ctx context.Context, args ...string) (*Result, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get executable path: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	command := exec.CommandContext(ctx, executable, args...)
+	command.Dir = r.Dir
+	command.Env = append(os.Environ(), ChildEnv+"=1")
+	command.Stdin = os.Stdin
+	command.Stdout = teeWriter(&stdout, r.Stdout)
+	command.Stderr = teeWriter(&stderr, r.Stderr)
+
+	runErr := command.Run()
+	result := &Result{
+		Stdout: stdout.String(),
+		Stderr: stderr.String(),
+	}
+
+	if runErr != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(runErr, &exitErr) {
+			return result, fmt.Errorf("failed to run packwiz: %w", runErr)
+		}
+		result.ExitCode = exitErr.ExitCode()
+		return result, &CommandError{Args: args, ExitCode: result.ExitCode, Message: result.lastLine()}
+	}
+
+	return result, nil
+}
+
+// lastLine returns the last line packwiz printed, preferring stderr.
+// packwiz reports most failures with fmt.Println, so stdout is the fallback.
+func (r *Result) lastLine() string {
+	for _, output := range []string{r.Stderr, r.Stdout} {
+		lines := strings.Split(strings.TrimSpace(output), "\n")
+		if line := strings.TrimSpace(lines[len(lines)-1]); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// CommandError is returned when a packwiz command exits unsuccessfully
+type CommandError struct {
+	Args     []string
+	ExitCode int
+	Message  string
+}
+
+func (e *CommandError) Error() string {
+	name := "packwiz"
+	if len(e.Args) > 0 {
+		name = "packwiz " + e.Args[0]
+		if len(e.Args) > 1 && !strings.HasPrefix(e.Args[1], "-") {
+			name += " " + e.Args[1]
+		}
+	}
+	if e.Message != "" {
+		return fmt.Sprintf("%s failed: %s", name, e.Message)
+	}
+	return fmt.Sprintf("%s failed with exit code %d", name, e.ExitCode)
+}
+
+// Execute runs packwiz in the current process with args, returning the
+// cobra error instead of exiting. Handlers that call os.Exit still end the
+// process, so callers that must survive failures should use a Runner.
+func Execute(args []string) error {
+	root := rootProbe.Root()
+	root.SetArgs(args)
+	root.SilenceErrors = true
+	_, err := root.ExecuteC()
+	return err
+}
+
+// RunChild is the entry point of a packwiz child process. It returns the exit code to use.
+func RunChild(args []string) int {
+	if err := Execute(args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func teeWriter(buffer *bytes.Buffer, live io.Writer) io.Writer {
+	if live == nil {
+		return buffer
+	}
+	return io.MultiWriter(buffer, live)
+}
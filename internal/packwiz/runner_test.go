@@ -0,0 +1,59 @@
+package packwiz
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestMain lets the test binary act as a packwiz child when started by a Runner
+func TestMain(m *testing.M) {
+	if os.Getenv(ChildEnv) != "" {
+		os.Exit(RunChild(os.Args[1:]))
+	}
+	os.Exit(m.Run())
+}
+
+func TestRunnerSucceeds(t *testing.T) {
+	runner := &Runner{Dir: t.TempDir()}
+	result, err := runner.Run(context.Background(), rootProbe.Use)
+	if err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+	if result.ExitCode != 0 {
+		t.Errorf("Expected exit code 0, got %d", result.ExitCode)
+	}
+}
+
+func TestRunnerReturnsCommandError(t *testing.T) {
+	runner := &Runner{Dir: t.TempDir()}
+	result, err := runner.Run(context.Background(), "definitely-not-a-command")
+	if err == nil {
+		t.Fatal("Expected an error for an unknown command")
+	}
+
+	var commandErr *CommandError
+	if !errors.As(err, &commandErr) {
+		t.Fatalf("Expected a CommandError, got %T: %v", err, err)
+	}
+	if commandErr.ExitCode != 1 || result.ExitCode != 1 {
+		t.Errorf("Expected exit code 1, got %d", commandErr.ExitCode)
+	}
+	if !strings.Contains(err.Error(), "definitely-not-a-command") {
+		t.Errorf("Expected the cobra error in the message, got %q", err.Error())
+	}
+}
+
+func TestResultLastLinePrefersStderr(t *testing.T) {
+	result := &Result{Stdout: "Downloading...\nfailed to find mod\n", Stderr: ""}
+	if line := result.lastLine(); line != "failed to find mod" {
+		t.Errorf("Expected stdout fallback, got %q", line)
+	}
+
+	result.Stderr = "Error: network unreachable\n"
+	if line := result.lastLine(); line != "Error: network unreachable" {
+		t.Errorf("Expected stderr line, got %q", line)
+	}
+}
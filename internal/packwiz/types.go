@@ -4,9 +4,10 @@ package packwiz
 type IndexToml struct {
 	HashFormat string `toml:"hash-format"`
 	Files      []struct {
-		File     string `toml:"file"`
-		Hash     string `toml:"hash"`
-		Metafile bool   `toml:"metafile,omitempty"`
+		File       string `toml:"file"`
+		Hash       string `toml:"hash"`
+		HashFormat string `toml:"hash-format,omitempty"` // overrides the index hash format
+		Metafile   bool   `toml:"metafile,omitempty"`
 	} `toml:"files"`
 }
 
@@ -30,6 +31,12 @@ type ModToml struct {
 			ProjectID int `toml:"project-id"`
 		} `toml:"curseforge"`
 	} `toml:"update,omitempty"`
+	// Option is only present for mods the user can toggle in the installer
+	Option *struct {
+		Optional    bool   `toml:"optional"`
+		Default     bool   `toml:"default,omitempty"`
+		Description string `toml:"description,omitempty"`
+	} `toml:"option,omitempty"`
 	// Parse is specific to this program
 	Parse struct {
 		ModID string `toml:"mod-id"`
@@ -54,9 +61,63 @@ type PackToml struct {
 		Fabric    string `toml:"fabric,omitempty"`
 		Minecraft string `toml:"minecraft,omitempty"`
 		Forge     string `toml:"forge,omitempty"`
+		NeoForge  string `toml:"neoforge,omitempty"`
 		Quilt     string `toml:"quilt,omitempty"`
 	} `toml:"versions,omitempty"`
 	Options struct {
 		AcceptableGameVersions []string `toml:"acceptable-game-versions,omitempty"`
 	} `toml:"options,omitempty"`
 }
+
+// MinecraftVersion returns the Minecraft version a pack targets,
+// preferring versions.minecraft over the legacy mc-version field
+func MinecraftVersion(packToml *PackToml) string {
+	if packToml.Versions.Minecraft != "" {
+		return packToml.Versions.Minecraft
+	}
+	return packToml.McVersion
+}
+
+// Mod loader names used in pack.toml's versions table
+const (
+	LoaderNeoForge = "neoforge"
+	LoaderForge    = "forge"
+	LoaderFabric   = "fabric"
+	LoaderQuilt    = "quilt"
+)
+
+// ModLoader returns the pack's mod loader and its version, or two empty strings
+// for vanilla packs. NeoForge wins over Forge for packs that list both.
+func ModLoader(packToml *PackToml) (string, string) {
+	versions := packToml.Versions
+	switch {
+	case versions.NeoForge != "":
+		return LoaderNeoForge, versions.NeoForge
+	case versions.Forge != "":
+		return LoaderForge, versions.Forge
+	case versions.Fabric != "":
+		return LoaderFabric, versions.Fabric
+	case versions.Quilt != "":
+		return LoaderQuilt, versions.Quilt
+	default:
+		return "", ""
+	}
+}
+
+// LoaderName returns the display name of a mod loader
+func LoaderName(loader string) string {
+	switch loader {
+	case LoaderNeoForge:
+		return "NeoForge"
+	case LoaderForge:
+		return "Forge"
+	case LoaderFabric:
+		return "Fabric"
+	case LoaderQuilt:
+		return "Quilt"
+	case "":
+		return "Vanilla"
+	default:
+		return loader
+	}
+}
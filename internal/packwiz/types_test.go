@@ -192,9 +192,10 @@ func TestIndexTomlStructure(t *testing.T) {
 	index := IndexToml{
 		HashFormat: "sha256",
 		Files: []struct {
-			File     string `toml:"file"`
-			Hash     string `toml:"hash"`
-			Metafile bool   `toml:"metafile,omitempty"`
+			File       string `toml:"file"`
+			Hash       string `toml:"hash"`
+			HashFormat string `toml:"hash-format,omitempty"`
+			Metafile   bool   `toml:"metafile,omitempty"`
 		}{
 			{File: "mods/test-mod.pw.toml", Hash: "abc123", Metafile: true},
 			{File: "mods/another-mod.pw.toml", Hash: "def456", Metafile: true},
@@ -228,9 +229,10 @@ func TestIndexTomlSerialization(t *testing.T) {
 	index := IndexToml{
 		HashFormat: "sha512",
 		Files: []struct {
-			File     string `toml:"file"`
-			Hash     string `toml:"hash"`
-			Metafile bool   `toml:"metafile,omitempty"`
+			File       string `toml:"file"`
+			Hash       string `toml:"hash"`
+			HashFormat string `toml:"hash-format,omitempty"`
+			Metafile   bool   `toml:"metafile,omitempty"`
 		}{
 			{File: "mods/fabric-api.pw.toml", Hash: "hash1", Metafile: true},
 			{File: "mods/sodium.pw.toml", Hash: "hash2", Metafile: true},
@@ -307,3 +309,23 @@ func TestJSONCompatibility(t *testing.T) {
 		t.Errorf("JSON Name mismatch: expected %s, got %s", pack.Name, deserializedPack.Name)
 	}
 }
+
+func TestModLoader(t *testing.T) {
+	pack := PackToml{}
+	if loader, version := ModLoader(&pack); loader != "" || version != "" {
+		t.Errorf("Expected no loader for a vanilla pack, got %s %s", loader, version)
+	}
+	if name := LoaderName(""); name != "Vanilla" {
+		t.Errorf("Expected Vanilla, got %s", name)
+	}
+
+	pack.Versions.Forge = "47.2.0"
+	pack.Versions.NeoForge = "21.1.77"
+	loader, version := ModLoader(&pack)
+	if loader != LoaderNeoForge || version != "21.1.77" {
+		t.Errorf("Expected neoforge 21.1.77, got %s %s", loader, version)
+	}
+	if name := LoaderName(loader); name != "NeoForge" {
+		t.Errorf("Expected NeoForge, got %s", name)
+	}
+}
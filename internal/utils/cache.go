@@ -0,0 +1,237 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Cache is a content-addressed file store shared by every pack. Files are
+// keyed by their packwiz hash-format and hash, so a mod jar downloaded for one
+// pack or export is reused by all the others.
+type Cache struct {
+	Dir string
+}
+
+// CacheEntry is a single cached file
+type CacheEntry struct {
+	HashFormat string
+	Hash       string
+	Path       string
+	Size       int64
+	LastUsed   time.Time // refreshed whenever the entry is read
+}
+
+// DefaultCache returns the download cache in the application data directory
+func DefaultCache() *Cache {
+	return &Cache{Dir: filepath.Join(getDataDirectory(), "cache")}
+}
+
+// RefreshCacheDir is where pw refresh keeps its per-pack hash caches, outside
+// the packs so packwiz never indexes them
+func RefreshCacheDir() string {
+	return filepath.Join(getDataDirectory(), "refresh")
+}
+
+// Path returns where the file with the given hash is stored
+func (c *Cache) Path(hashFormat, hash string) string {
+	hashFormat = strings.ToLower(hashFormat)
+	hash = strings.ToLower(hash)
+
+	prefix := hash
+	if len(prefix) > 2 {
+		prefix = prefix[:2]
+	}
+	return filepath.Join(c.Dir, hashFormat, prefix, hash)
+}
+
+// Open opens a cached file, or returns an error wrapping fs.ErrNotExist on a miss.
+// The entry's last use time is refreshed so pruning by age keeps it.
+func (c *Cache) Open(hashFormat, hash string) (*os.File, error) {
+	if !validCacheKey(hashFormat, hash) {
+		return nil, fmt.Errorf("invalid cache key %s:%s: %w", hashFormat, hash, fs.ErrNotExist)
+	}
+
+	path := c.Path(hashFormat, hash)
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return file, nil
+}
+
+// Store copies srcPath into the cache. The caller must have verified that the
+// file has the given hash.
+func (c *Cache) Store(hashFormat, hash, srcPath string) error {
+	if !validCacheKey(hashFormat, hash) {
+		return fmt.Errorf("invalid cache key %s:%s", hashFormat, hash)
+	}
+
+	dest := c.Path(hashFormat, hash)
+	if _, err := os.Stat(dest); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return NewFailedToError("create cache directory", err)
+	}
+
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return NewFailedToError("open source file", err)
+	}
+	defer src.Close()
+
+	// Write to a temporary file first so concurrent readers never see partial files
+	temp, err := os.CreateTemp(filepath.Dir(dest), ".store-*")
+	if err != nil {
+		return NewFailedToError("create cache file", err)
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := io.Copy(temp, src); err != nil {
+		temp.Close()
+		return NewFailedToError("write cache file", err)
+	}
+	if err := temp.Close(); err != nil {
+		return NewFailedToError("write cache file", err)
+	}
+	if err := os.Chmod(temp.Name(), 0644); err != nil {
+		return NewFailedToError("write cache file", err)
+	}
+	return os.Rename(temp.Name(), dest)
+}
+
+// Entries lists every cached file, least recently used first
+func (c *Cache) Entries() ([]CacheEntry, error) {
+	var entries []CacheEntry
+	err := filepath.WalkDir(c.Dir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && path == c.Dir {
+			return filepath.SkipDir
+		}
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+
+		// Layout is <format>/<prefix>/<hash>
+		rel, err := filepath.Rel(c.Dir, path)
+		if err != nil {
+			return err
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		if len(parts) != 3 {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entries = append(entries, CacheEntry{
+			HashFormat: parts[0],
+			Hash:       parts[2],
+			Path:       path,
+			Size:       info.Size(),
+			LastUsed:   info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, NewFailedToError("read cache", err)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsed.Before(entries[j].LastUsed)
+	})
+	return entries, nil
+}
+
+// Size returns the number of cached files and their total size in bytes
+func (c *Cache) Size() (int, int64, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+	return len(entries), total, nil
+}
+
+// Prune removes entries unused for longer than maxAge, then the least recently
+// used entries until the cache fits in maxSize bytes. Zero disables a limit.
+// It returns the removed entries.
+func (c *Cache) Prune(maxAge time.Duration, maxSize int64) ([]CacheEntry, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+
+	var removed []CacheEntry
+	cutoff := time.Now().Add(-maxAge)
+	for _, entry := range entries {
+		expired := maxAge > 0 && entry.LastUsed.Before(cutoff)
+		oversized := maxSize > 0 && total > maxSize
+		if !expired && !oversized {
+			continue
+		}
+
+		if err := os.Remove(entry.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return removed, NewFailedToError("remove cache entry", err)
+		}
+		total -= entry.Size
+		removed = append(removed, entry)
+	}
+	return removed, nil
+}
+
+// Verify re-hashes every entry and returns the ones whose content no longer
+// matches their hash. When remove is set the corrupt entries are deleted.
+func (c *Cache) Verify(remove bool) ([]CacheEntry, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	var corrupt []CacheEntry
+	for _, entry := range entries {
+		actual, err := HashFile(entry.Path, entry.HashFormat)
+		if err == nil && strings.EqualFold(actual, entry.Hash) {
+			continue
+		}
+
+		corrupt = append(corrupt, entry)
+		if remove {
+			if err := os.Remove(entry.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return corrupt, NewFailedToError("remove cache entry", err)
+			}
+		}
+	}
+	return corrupt, nil
+}
+
+// validCacheKey rejects keys that would escape the cache directory
+func validCacheKey(hashFormat, hash string) bool {
+	if hashFormat == "" || hash == "" {
+		return false
+	}
+	return !strings.ContainsAny(hashFormat+hash, `/\.:`)
+}
@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// storeTestEntry caches content under its sha256 and returns the hash
+func storeTestEntry(t *testing.T, cache *Cache, content string) string {
+	t.Helper()
+	sum := sha256.Sum256([]byte(content))
+	hash := hex.EncodeToString(sum[:])
+
+	src := filepath.Join(t.TempDir(), "src")
+	if err := os.WriteFile(src, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Store("sha256", hash, src); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	return hash
+}
+
+func TestCacheStoreAndOpen(t *testing.T) {
+	cache := &Cache{Dir: t.TempDir()}
+	hash := storeTestEntry(t, cache, "mod jar")
+
+	file, err := cache.Open("SHA256", hash)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	data, _ := io.ReadAll(file)
+	file.Close()
+	if string(data) != "mod jar" {
+		t.Errorf("Unexpected cached content: %q", data)
+	}
+
+	if _, err := cache.Open("sha256", "missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected a miss, got %v", err)
+	}
+	if _, err := cache.Open("sha256", "../escape"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected invalid keys to miss, got %v", err)
+	}
+
+	count, size, err := cache.Size()
+	if err != nil {
+		t.Fatalf("Size failed: %v", err)
+	}
+	if count != 1 || size != int64(len("mod jar")) {
+		t.Errorf("Expected 1 file of %d bytes, got %d files of %d bytes", len("mod jar"), count, size)
+	}
+}
+
+func TestCachePrune(t *testing.T) {
+	cache := &Cache{Dir: t.TempDir()}
+	old := storeTestEntry(t, cache, "old entry")
+	recent := storeTestEntry(t, cache, "recent entry")
+	newest := storeTestEntry(t, cache, "newest entry")
+
+	now := time.Now()
+	times := map[string]time.Time{
+		old:    now.Add(-48 * time.Hour),
+		recent: now.Add(-2 * time.Hour),
+		newest: now,
+	}
+	for hash, at := range times {
+		if err := os.Chtimes(cache.Path("sha256", hash), at, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := cache.Prune(24*time.Hour, 0)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if len(removed) != 1 || removed[0].Hash != old {
+		t.Fatalf("Expected only the old entry to be pruned, got %+v", removed)
+	}
+
+	// The least recently used entry goes first when the cache is too big
+	removed, err = cache.Prune(0, int64(len("newest entry")))
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if len(removed) != 1 || removed[0].Hash != recent {
+		t.Fatalf("Expected the recent entry to be pruned, got %+v", removed)
+	}
+	if _, err := os.Stat(cache.Path("sha256", newest)); err != nil {
+		t.Errorf("Expected the newest entry to be kept: %v", err)
+	}
+}
+
+func TestCacheVerify(t *testing.T) {
+	cache := &Cache{Dir: t.TempDir()}
+	good := storeTestEntry(t, cache, "good")
+	bad := storeTestEntry(t, cache, "bad")
+	if err := os.WriteFile(cache.Path("sha256", bad), []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	corrupt, err := cache.Verify(false)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(corrupt) != 1 || corrupt[0].Hash != bad {
+		t.Fatalf("Expected the tampered entry to be reported, got %+v", corrupt)
+	}
+
+	if _, err := cache.Verify(true); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if _, err := os.Stat(cache.Path("sha256", bad)); !os.IsNotExist(err) {
+		t.Error("Expected the corrupt entry to be removed")
+	}
+	if _, err := os.Stat(cache.Path("sha256", good)); err != nil {
+		t.Errorf("Expected the good entry to be kept: %v", err)
+	}
+}
+
+func TestCacheMissingDirectory(t *testing.T) {
+	cache := &Cache{Dir: filepath.Join(t.TempDir(), "missing")}
+	count, size, err := cache.Size()
+	if err != nil || count != 0 || size != 0 {
+		t.Errorf("Expected an empty cache, got %d files, %d bytes, %v", count, size, err)
+	}
+}
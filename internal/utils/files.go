@@ -8,7 +8,10 @@ import (
 )
 
 // HTTPDownloader provides utilities for downloading files
-type HTTPDownloader struct{}
+type HTTPDownloader struct {
+	// Progress is called as the download is written; total is -1 when the size is unknown
+	Progress func(written, total int64)
+}
 
 // DownloadFile downloads a file from URL to local path
 func (d *HTTPDownloader) DownloadFile(url, filePath string) error {
@@ -28,7 +31,12 @@ func (d *HTTPDownloader) DownloadFile(url, filePath string) error {
 	}
 	defer file.Close()
 
-	_, err = io.Copy(file, resp.Body)
+	var dst io.Writer = file
+	if d.Progress != nil {
+		dst = &progressWriter{w: file, total: resp.ContentLength, progress: d.Progress}
+	}
+
+	_, err = io.Copy(dst, resp.Body)
 	if err != nil {
 		return NewFailedToError("write file", err)
 	}
@@ -36,6 +44,21 @@ func (d *HTTPDownloader) DownloadFile(url, filePath string) error {
 	return nil
 }
 
+// progressWriter reports how many bytes have been written so far
+type progressWriter struct {
+	w        io.Writer
+	written  int64
+	total    int64
+	progress func(written, total int64)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+	p.progress(p.written, p.total)
+	return n, err
+}
+
 // CopyFile copies a file from src to dst
 func CopyFile(src, dst string) error {
 	sourceFile, err := os.Open(src)
@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// FileHasher hashes written data and formats the result the way packwiz stores it
+type FileHasher interface {
+	io.Writer
+	String() string
+}
+
+// NewFileHasher returns a hasher for a packwiz hash-format: sha1, sha256,
+// sha512, md5 or murmur2
+func NewFileHasher(format string) (FileHasher, error) {
+	switch strings.ToLower(format) {
+	case "sha1":
+		return hexHasher{sha1.New()}, nil
+	case "sha256":
+		return hexHasher{sha256.New()}, nil
+	case "sha512":
+		return hexHasher{sha512.New()}, nil
+	case "md5":
+		return hexHasher{md5.New()}, nil
+	case "murmur2":
+		return &murmur2Hasher{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported hash format: %q", format)
+	}
+}
+
+// hexHasher formats a standard hash as lowercase hex
+type hexHasher struct {
+	hash.Hash
+}
+
+func (h hexHasher) String() string {
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// murmur2Hasher computes CurseForge's file fingerprint: MurmurHash2 with seed 1
+// over the file with whitespace bytes removed, formatted as a decimal number
+type murmur2Hasher struct {
+	data []byte
+}
+
+func (h *murmur2Hasher) Write(b []byte) (int, error) {
+	for _, c := range b {
+		if c != 9 && c != 10 && c != 13 && c != 32 {
+			h.data = append(h.data, c)
+		}
+	}
+	return len(b), nil
+}
+
+func (h *murmur2Hasher) String() string {
+	return strconv.FormatUint(uint64(murmur2(h.data, 1)), 10)
+}
+
+// murmur2 is the 32-bit MurmurHash2 algorithm
+func murmur2(data []byte, seed uint32) uint32 {
+	const m = 0x5bd1e995
+	const r = 24
+
+	length := len(data)
+	h := seed ^ uint32(length)
+	for ; length >= 4; length -= 4 {
+		k := uint32(data[0]) | uint32(data[1])<<8 | uint32(data[2])<<16 | uint32(data[3])<<24
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+		data = data[4:]
+	}
+
+	switch length {
+	case 3:
+		h ^= uint32(data[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(data[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(data[0])
+		h *= m
+	}
+
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return h
+}
+
+// HashFile returns the hash of a file in the given packwiz hash-format
+func HashFile(path, format string) (string, error) {
+	hasher, err := NewFileHasher(format)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", err
+	}
+	return hasher.String(), nil
+}
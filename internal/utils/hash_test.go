@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMurmur2(t *testing.T) {
+	if got := murmur2(nil, 0); got != 0 {
+		t.Errorf("Expected 0 for empty input, got %d", got)
+	}
+
+	// CurseForge fingerprints use seed 1 and ignore whitespace
+	hasher, err := NewFileHasher("murmur2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hasher.Write([]byte("The quick brown fox\r\njumps over\tthe lazy dog"))
+	if got := hasher.String(); got != "3751777527" {
+		t.Errorf("Unexpected fingerprint: %s", got)
+	}
+}
+
+func TestHashFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"sha1":   "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d",
+		"sha256": "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		"md5":    "5d41402abc4b2a76b9719d911017c592",
+	}
+	for format, expected := range tests {
+		got, err := HashFile(path, format)
+		if err != nil {
+			t.Fatalf("HashFile(%s) failed: %v", format, err)
+		}
+		if got != expected {
+			t.Errorf("HashFile(%s) = %s, want %s", format, got, expected)
+		}
+	}
+
+	if _, err := HashFile(path, "crc32"); err == nil {
+		t.Error("Expected an error for an unsupported hash format")
+	}
+}
@@ -628,6 +628,12 @@ func parseMinecraftVersion(version string) MinecraftVersion {
 	return mv
 }
 
+// CompareMinecraftVersions compares two Minecraft version strings
+// Returns: -1 if a < b, 0 if equal, 1 if a > b
+func CompareMinecraftVersions(a, b string) int {
+	return parseMinecraftVersion(a).Compare(b)
+}
+
 // Compare compares this version with another version string
 // Returns: -1 if this < other, 0 if equal, 1 if this > other
 func (mv MinecraftVersion) Compare(other string) int {
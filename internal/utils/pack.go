@@ -5,17 +5,20 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
-
-	"github.com/BurntSushi/toml"
-	"github.com/Merith-TK/packwiz-wrapper/internal/packwiz"
+	"time"
 )
 
 // FindPackToml finds the pack.toml file in the given directory or its parents.
 // It checks both the current directory and .minecraft subdirectory (common modpack pattern).
 // Returns the directory containing pack.toml, or empty string if not found.
+// Relative start directories are resolved so the parent search always works.
 func FindPackToml(startDir string) string {
 	dir := startDir
+	if abs, err := filepath.Abs(startDir); err == nil {
+		dir = abs
+	}
 	for {
 		// Check current directory
 		packTomlPath := filepath.Join(dir, "pack.toml")
@@ -39,44 +42,49 @@ func FindPackToml(startDir string) string {
 	return ""
 }
 
-// LoadPackConfig loads pack configuration from the current directory or parent directories
-func LoadPackConfig(packDir string) (*packwiz.PackToml, string, error) {
-	// Use the centralized pack.toml finding logic
-	packLocation := FindPackToml(packDir)
+// PackFileArgs appends --pack-file pointing at packLocation to packwiz arguments,
+// unless the arguments already name a pack file. This lets packwiz operate on a
+// pack without depending on the process working directory.
+func PackFileArgs(args []string, packLocation string) []string {
 	if packLocation == "" {
-		return nil, "", fmt.Errorf("pack.toml not found in current directory, .minecraft subdirectory, or parent directories")
+		return args
 	}
-
-	packTomlPath := filepath.Join(packLocation, "pack.toml")
-	data, err := os.ReadFile(packTomlPath)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to read pack.toml: %w", err)
+	for _, arg := range args {
+		if arg == "--pack-file" || strings.HasPrefix(arg, "--pack-file=") {
+			return args
+		}
 	}
 
-	var packToml packwiz.PackToml
-	if err := toml.Unmarshal(data, &packToml); err != nil {
-		return nil, "", fmt.Errorf("failed to parse pack.toml: %w", err)
-	}
+	result := make([]string, 0, len(args)+2)
+	result = append(result, args...)
+	return append(result, "--pack-file", filepath.Join(packLocation, "pack.toml"))
+}
 
-	return &packToml, packLocation, nil
+// gitOutput runs a git command in dir and returns its output
+func gitOutput(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	return cmd.Output()
 }
 
-// DetectRemotePackURL tries to detect the remote pack URL from git
-// Returns the raw URL to pack.toml in the remote repository
-func DetectRemotePackURL(packLocation string) (string, error) {
-	// Change to pack directory for git commands
-	originalDir, err := os.Getwd()
+// GitCommitTime returns the committer time of HEAD in the repository containing dir
+func GitCommitTime(dir string) (time.Time, error) {
+	output, err := gitOutput(dir, "log", "-1", "--format=%ct")
 	if err != nil {
-		return "", err
+		return time.Time{}, fmt.Errorf("failed to get commit time: %w", err)
 	}
-	defer os.Chdir(originalDir)
-
-	if err := os.Chdir(packLocation); err != nil {
-		return "", err
+	seconds, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse commit time: %w", err)
 	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
 
+// DetectRemotePackURL tries to detect the remote pack URL from git
+// Returns the raw URL to pack.toml in the remote repository
+func DetectRemotePackURL(packLocation string) (string, error) {
 	// Get git remote URL
-	remote, err := exec.Command("git", "remote", "get-url", "origin").Output()
+	remote, err := gitOutput(packLocation, "remote", "get-url", "origin")
 	if err != nil {
 		return "", fmt.Errorf("failed to get git remote: %w", err)
 	}
@@ -84,7 +92,7 @@ func DetectRemotePackURL(packLocation string) (string, error) {
 	remoteString = strings.TrimSuffix(remoteString, ".git")
 
 	// Get current branch
-	branch, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output()
+	branch, err := gitOutput(packLocation, "rev-parse", "--abbrev-ref", "HEAD")
 	if err != nil {
 		// Fallback: try GITHUB_HEAD_REF environment variable (GitHub Actions)
 		if envBranch := os.Getenv("GITHUB_HEAD_REF"); envBranch != "" {
@@ -96,7 +104,7 @@ func DetectRemotePackURL(packLocation string) (string, error) {
 	branchString := strings.TrimSpace(string(branch))
 
 	// Get relative path to pack.toml from git root
-	gitRoot, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	gitRoot, err := gitOutput(packLocation, "rev-parse", "--show-toplevel")
 	if err != nil {
 		return "", fmt.Errorf("failed to get git root: %w", err)
 	}
@@ -48,85 +48,6 @@ func TestFindPackToml(t *testing.T) {
 	}
 }
 
-func TestLoadPackConfig(t *testing.T) {
-	// Create a temporary directory with a valid pack.toml
-	tmpDir := t.TempDir()
-	packTomlContent := `name = "Test Pack"
-author = "Test Author"
-version = "1.0.0"
-pack-format = "packwiz:1.1.0"
-
-[index]
-file = "index.toml"
-hash-format = "sha256"
-
-[versions]
-minecraft = "1.20.1"
-`
-
-	packTomlPath := filepath.Join(tmpDir, "pack.toml")
-	if err := os.WriteFile(packTomlPath, []byte(packTomlContent), 0644); err != nil {
-		t.Fatalf("Failed to create test pack.toml: %v", err)
-	}
-
-	packToml, location, err := LoadPackConfig(tmpDir)
-	if err != nil {
-		t.Fatalf("Failed to load pack config: %v", err)
-	}
-
-	if location != tmpDir {
-		t.Errorf("Expected location %s, got %s", tmpDir, location)
-	}
-
-	if packToml.Name != "Test Pack" {
-		t.Errorf("Expected pack name 'Test Pack', got '%s'", packToml.Name)
-	}
-
-	if packToml.Author != "Test Author" {
-		t.Errorf("Expected author 'Test Author', got '%s'", packToml.Author)
-	}
-
-	if packToml.Version != "1.0.0" {
-		t.Errorf("Expected version '1.0.0', got '%s'", packToml.Version)
-	}
-}
-
-func TestLoadPackConfigNotFound(t *testing.T) {
-	// Test with directory that has no pack.toml
-	tmpDir := t.TempDir()
-
-	_, _, err := LoadPackConfig(tmpDir)
-	if err == nil {
-		t.Error("Expected error when pack.toml not found, got nil")
-	}
-
-	if !strings.Contains(err.Error(), "pack.toml not found") {
-		t.Errorf("Expected 'pack.toml not found' error, got: %v", err)
-	}
-}
-
-func TestLoadPackConfigInvalidToml(t *testing.T) {
-	// Test with invalid TOML content
-	tmpDir := t.TempDir()
-	packTomlContent := `name = "Invalid TOML"
-[invalid toml syntax
-`
-
-	packTomlPath := filepath.Join(tmpDir, "pack.toml")
-	if err := os.WriteFile(packTomlPath, []byte(packTomlContent), 0644); err != nil {
-		t.Fatalf("Failed to create invalid pack.toml: %v", err)
-	}
-
-	_, _, err := LoadPackConfig(tmpDir)
-	if err == nil {
-		t.Error("Expected error when pack.toml is invalid, got nil")
-	}
-
-	if !strings.Contains(err.Error(), "failed to parse pack.toml") {
-		t.Errorf("Expected 'failed to parse pack.toml' error, got: %v", err)
-	}
-}
-
 func TestDetectRemotePackURLNoGit(t *testing.T) {
 	// Test with directory that's not a git repo
 	tmpDir := t.TempDir()
@@ -143,3 +64,24 @@ func runCmd(dir, name string, args ...string) error {
 	cmd.Dir = dir
 	return cmd.Run()
 }
+
+func TestPackFileArgs(t *testing.T) {
+	packLocation := filepath.Join("packs", "example")
+
+	args := PackFileArgs([]string{"refresh"}, packLocation)
+	expected := []string{"refresh", "--pack-file", filepath.Join(packLocation, "pack.toml")}
+	if strings.Join(args, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected %v, got %v", expected, args)
+	}
+
+	// An explicit pack file is left alone
+	explicit := []string{"refresh", "--pack-file=other/pack.toml"}
+	if args := PackFileArgs(explicit, packLocation); len(args) != len(explicit) {
+		t.Errorf("Expected explicit --pack-file to be kept, got %v", args)
+	}
+
+	// Without a pack location nothing is added
+	if args := PackFileArgs([]string{"init"}, ""); len(args) != 1 {
+		t.Errorf("Expected no --pack-file without a location, got %v", args)
+	}
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+// UpdateProperties sets keys in a Java properties file such as server.properties,
+// creating it when missing. Existing lines keep their order and comments; keys
+// that are not in the file yet are appended in sorted order.
+func UpdateProperties(path string, values map[string]string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return NewFailedToError("read "+path, err)
+	}
+
+	var lines []string
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimRight(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n"), "\n")
+	}
+
+	written := map[string]bool{}
+	for i, line := range lines {
+		key, ok := propertyKey(line)
+		if !ok {
+			continue
+		}
+		if value, set := values[key]; set {
+			lines[i] = key + "=" + escapePropertyValue(value)
+			written[key] = true
+		}
+	}
+
+	var missing []string
+	for key := range values {
+		if !written[key] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	for _, key := range missing {
+		lines = append(lines, key+"="+escapePropertyValue(values[key]))
+	}
+
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		return NewFailedToError("write "+path, err)
+	}
+	return nil
+}
+
+// propertyKey returns the key of a properties line, or false for comments and blank lines
+func propertyKey(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+		return "", false
+	}
+	if end := strings.IndexAny(line, "=:"); end >= 0 {
+		line = line[:end]
+	}
+	return strings.TrimSpace(line), true
+}
+
+// escapePropertyValue escapes a value so the server reads it back unchanged,
+// whatever encoding its version reads server.properties with
+func escapePropertyValue(value string) string {
+	var escaped strings.Builder
+	for i, r := range value {
+		switch {
+		case r == '\\':
+			escaped.WriteString(`\\`)
+		case r == '\n':
+			escaped.WriteString(`\n`)
+		case r == '\r':
+			escaped.WriteString(`\r`)
+		case r == ' ' && i == 0:
+			escaped.WriteString(`\ `)
+		case r > 0xffff:
+			high, low := utf16.EncodeRune(r)
+			fmt.Fprintf(&escaped, `\u%04x\u%04x`, high, low)
+		case r > 0x7e:
+			fmt.Fprintf(&escaped, `\u%04x`, r)
+		default:
+			escaped.WriteRune(r)
+		}
+	}
+	return escaped.String()
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpdateProperties(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.properties")
+	original := "#Minecraft server properties\nmotd=A Minecraft Server\nlevel-type=minecraft\\:normal\nserver-port=25565\n"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := UpdateProperties(path, map[string]string{
+		"server-port": "25570",
+		"motd":        "§aWelcome\\",
+		"white-list":  "true",
+		"difficulty":  "hard",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "#Minecraft server properties\n" +
+		"motd=\\u00a7aWelcome\\\\\n" +
+		"level-type=minecraft\\:normal\n" +
+		"server-port=25570\n" +
+		"difficulty=hard\n" +
+		"white-list=true\n"
+	if string(data) != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, data)
+	}
+}
+
+func TestUpdatePropertiesCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.properties")
+	if err := UpdateProperties(path, map[string]string{"server-port": "25566"}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "server-port=25566\n" {
+		t.Errorf("Unexpected content %q", data)
+	}
+}
@@ -0,0 +1,44 @@
+package packwrap
+
+import "time"
+
+// EventType identifies what an Event reports
+type EventType string
+
+const (
+	EventStepStarted      EventType = "step_started"
+	EventStepFinished     EventType = "step_finished"
+	EventStepFailed       EventType = "step_failed"
+	EventModAdded         EventType = "mod_added"
+	EventModRemoved       EventType = "mod_removed"
+	EventDownloadProgress EventType = "download_progress"
+	EventExportStarted    EventType = "export_started"
+	EventExportFinished   EventType = "export_finished"
+)
+
+// Operations that emit events
+const (
+	OperationImport      = "import"
+	OperationExport      = "export"
+	OperationReinstall   = "reinstall"
+	OperationBatch       = "batch"
+	OperationServerSetup = "server-setup"
+)
+
+// Event describes progress of a long running PackManager operation
+type Event struct {
+	Type      EventType `json:"type"`
+	Operation string    `json:"operation"`
+	PackDir   string    `json:"pack_dir,omitempty"`
+	Subject   string    `json:"subject,omitempty"` // mod reference, directory, export format or file
+	Message   string    `json:"message,omitempty"` // human readable summary, ready to display
+	Current   int64     `json:"current,omitempty"` // step number, or bytes for downloads
+	Total     int64     `json:"total,omitempty"`   // 0 when unknown
+	Path      string    `json:"path,omitempty"`    // artifact written by an export
+	Error     string    `json:"error,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// EventHandler receives events. Calls are serialized, so handlers need no locking,
+// but they run on the emitting goroutine and should return quickly.
+type EventHandler func(event Event)
@@ -2,6 +2,7 @@ package packwrap
 
 import (
 	"context"
+	"time"
 )
 
 // PackManager provides high-level operations for modpack management
@@ -17,28 +18,44 @@ type PackManager interface {
 	UpdateMod(packDir string, modID string) error
 
 	// Import/Export operations
-	ImportFromFile(packDir string, filename string) error
+	ImportFromFile(packDir string, filename string) (*ImportResult, error)
 	ImportFromURLs(packDir string, urls []string) error
-	ExportPack(packDir string, format ExportFormat) (string, error)
+	ExportPack(packDir string, format ExportFormat, options ExportOptions) ([]string, error)
 
 	// Batch operations
-	BatchOperation(ctx context.Context, dirs []string, operation BatchOp) error
+	BatchOperation(ctx context.Context, dirs []string, operation BatchOp) ([]BatchResult, error)
 
 	// Server operations
-	StartTestServer(packDir string) error
-	SetupServer(packDir string) error
+	SetupServer(packDir string, progress ProgressCallback) error
+	StartTestServer(packDir string) (ServerProcess, error)
+	StartDetachedServer(packDir string) (pid int, err error)
+	StopServer(packDir string, timeout time.Duration) error
+	SuperviseServer(ctx context.Context, packDir string, options SuperviseOptions) error
+	ServerStatus(packDir string) (*ServerStatus, error)
+	TestServer(packDir string, timeout time.Duration) (*ServerTestResult, error)
+	AnalyzeCrash(packDir, file string) (*CrashAnalysis, error)
+	Bisect(packDir string, options BisectOptions) (*BisectResult, error)
 	CleanServer(packDir string) error
+
+	// Maintenance operations
+	ReinstallMods(packDir string, keepVersions bool) error
+
+	// Subscribe registers handler for events from every operation and returns
+	// a function that removes it
+	Subscribe(handler EventHandler) (unsubscribe func())
 }
 
 // PackInfo represents basic pack information
 type PackInfo struct {
-	Name        string `json:"name"`
-	Author      string `json:"author"`
-	McVersion   string `json:"mc_version"`
-	PackFormat  string `json:"pack_format"`
-	Description string `json:"description"`
-	ModCount    int    `json:"mod_count"`
-	PackDir     string `json:"pack_dir"`
+	Name        string            `json:"name"`
+	Author      string            `json:"author"`
+	Version     string            `json:"version"`
+	McVersion   string            `json:"mc_version"`
+	Loaders     map[string]string `json:"loaders"` // loader name (fabric, forge, neoforge, quilt) to version
+	PackFormat  string            `json:"pack_format"`
+	Description string            `json:"description"`
+	ModCount    int               `json:"mod_count"`
+	PackDir     string            `json:"pack_dir"`
 }
 
 // ModInfo represents information about a mod
@@ -50,6 +67,60 @@ type ModInfo struct {
 	DownloadURL string `json:"download_url"`
 	Version     string `json:"version"`
 	Platform    string `json:"platform"` // modrinth, curseforge, url
+	Folder      string `json:"folder"`   // metafile folder relative to the pack, e.g. mods or shaderpacks
+	HashFormat  string `json:"hash_format"`
+	Hash        string `json:"hash"`
+
+	ModrinthID          string `json:"modrinth_id,omitempty"`
+	CurseForgeProjectID int    `json:"curseforge_project_id,omitempty"`
+	CurseForgeFileID    int    `json:"curseforge_file_id,omitempty"`
+
+	Option *ModOption `json:"option,omitempty"` // nil unless the mod is optional
+}
+
+// ModOption is the packwiz [option] table of an optional mod
+type ModOption struct {
+	Optional    bool   `json:"optional"`
+	Default     bool   `json:"default"`
+	Description string `json:"description,omitempty"`
+}
+
+// ImportResult reports the outcome of an import, one entry per mod reference
+type ImportResult struct {
+	Source string       `json:"source,omitempty"`
+	Lines  []ImportLine `json:"lines"`
+}
+
+// ImportLine represents a single mod reference read from an import file
+type ImportLine struct {
+	Number int    `json:"number"` // 1-based line number in the source
+	Raw    string `json:"raw"`
+	URL    string `json:"url"`
+	Path   string `json:"path,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Error  string `json:"error,omitempty"` // empty when the import succeeded
+}
+
+// Succeeded returns the lines that were imported successfully
+func (r *ImportResult) Succeeded() []ImportLine {
+	var lines []ImportLine
+	for _, line := range r.Lines {
+		if line.Error == "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Failed returns the lines that could not be imported
+func (r *ImportResult) Failed() []ImportLine {
+	var lines []ImportLine
+	for _, line := range r.Lines {
+		if line.Error != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
 }
 
 // ExportFormat represents different export formats
@@ -59,15 +130,149 @@ const (
 	ExportCurseForge ExportFormat = "curseforge"
 	ExportModrinth   ExportFormat = "modrinth"
 	ExportMultiMC    ExportFormat = "multimc"
+	ExportPrism      ExportFormat = "prism"
 	ExportTechnic    ExportFormat = "technic"
 	ExportServer     ExportFormat = "server"
 	ExportAll        ExportFormat = "all"
 )
 
+// ExportOptions controls how ExportPack names and builds artifacts
+type ExportOptions struct {
+	// Output is the artifact path, relative to the pack directory unless absolute.
+	// For ExportAll it is the directory receiving every artifact. Defaults to .build.
+	Output string `json:"output,omitempty"`
+
+	// PackName is used in artifact names, defaults to the pack.toml name
+	PackName string `json:"pack_name,omitempty"`
+
+	// NameTemplate names artifacts, e.g. "{{.Name}}-{{.Version}}-{{.Format}}".
+	// Defaults to the name-template in the pack's packwrap.toml.
+	NameTemplate string `json:"name_template,omitempty"`
+
+	// MultiMCLocal makes MultiMC and Prism instances install from the local
+	// pack.toml instead of the pack's remote URL
+	MultiMCLocal bool `json:"multimc_local,omitempty"`
+
+	// BundleMods ships the mod jars in Prism instances for offline play
+	BundleMods bool `json:"bundle_mods,omitempty"`
+}
+
 // BatchOp represents a batch operation
 type BatchOp struct {
-	Name string
-	Args []string
+	Name    string
+	Args    []string
+	Refresh bool // refresh each pack after the command
+	Jobs    int  // packs processed in parallel, defaults to the CPU count
+
+	// OnComplete is called as each directory finishes; calls are serialized
+	OnComplete func(result BatchResult)
+}
+
+// BatchResult represents the outcome of a batch operation in one directory
+type BatchResult struct {
+	Dir      string        `json:"dir"`
+	Output   string        `json:"output"`          // combined stdout and stderr
+	Error    string        `json:"error,omitempty"` // empty on success
+	Skipped  bool          `json:"skipped,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// ServerProcess is a handle to a running test server
+type ServerProcess interface {
+	PID() int
+	// Output streams every console line; the channel is closed after the last one
+	// once the server exits. Lines wait until they are read, so reading is optional.
+	Output() <-chan string
+	// SendCommand writes a command to the server console
+	SendCommand(command string) error
+	// Stop asks the server to shut down and kills it after timeout
+	Stop(timeout time.Duration) error
+	Wait() error
+}
+
+// ServerStatus describes the detached test server of a pack
+type ServerStatus struct {
+	Running   bool      `json:"running"`
+	PID       int       `json:"pid,omitempty"`
+	StartedAt time.Time `json:"started_at,omitempty"`
+	Port      int       `json:"port"`
+
+	// Supervisor is the restart watchdog of the server, nil when it was never supervised
+	Supervisor *SupervisorStatus `json:"supervisor,omitempty"`
+}
+
+// SuperviseOptions configures when a supervised server is restarted
+type SuperviseOptions struct {
+	MaxRestarts int           // crashes in a row before giving up, 5 when zero
+	Backoff     time.Duration // wait before the first restart, doubled after every crash; 10s when zero
+	MaxBackoff  time.Duration // longest wait between restarts, 5 minutes when zero
+}
+
+// SupervisorStatus describes the watchdog that restarts a crashed server
+type SupervisorStatus struct {
+	Running     bool            `json:"running"`
+	PID         int             `json:"pid"`
+	StartedAt   time.Time       `json:"started_at"`
+	MaxRestarts int             `json:"max_restarts"`
+	GaveUp      bool            `json:"gave_up,omitempty"`  // the server crashed more than MaxRestarts times in a row
+	Restarts    []ServerRestart `json:"restarts,omitempty"` // the most recent crashes, oldest first
+}
+
+// ServerRestart records a crash of a supervised server
+type ServerRestart struct {
+	CrashedAt time.Time     `json:"crashed_at"`
+	ExitCode  int           `json:"exit_code"` // -1 when the server was killed by a signal
+	Uptime    time.Duration `json:"uptime"`
+	Backoff   time.Duration `json:"backoff,omitempty"` // wait before the restart, zero when the supervisor gave up
+	Archive   string        `json:"archive,omitempty"` // directory the crash reports and console output were copied to
+}
+
+// ServerTestResult is the outcome of booting the test server until it finishes starting
+type ServerTestResult struct {
+	Passed      bool          `json:"passed"`
+	Duration    time.Duration `json:"duration"`               // until the server started or failed
+	Reason      string        `json:"reason,omitempty"`       // why the test failed
+	CrashReport string        `json:"crash_report,omitempty"` // path of the crash report written during the test
+	Errors      []string      `json:"errors,omitempty"`       // ERROR and FATAL console lines
+	Mods        []string      `json:"mods,omitempty"`         // mods named by the errors or the crash report
+}
+
+// CrashAnalysis maps a crash report or server log back to the metafiles of the mods it blames
+type CrashAnalysis struct {
+	File        string         `json:"file"`
+	CrashReport bool           `json:"crash_report"` // false for a log such as latest.log
+	Description string         `json:"description,omitempty"`
+	Suspects    []CrashSuspect `json:"suspects"`
+	Unmatched   []string       `json:"unmatched,omitempty"` // blamed mod IDs that no metafile matches
+}
+
+// CrashSuspect is a metafile whose mod a crash blames
+type CrashSuspect struct {
+	Metafile string   `json:"metafile"` // relative to the pack, e.g. mods/sodium.pw.toml
+	Name     string   `json:"name"`
+	Filename string   `json:"filename"`
+	Source   string   `json:"source"`            // modrinth, curseforge or url
+	Project  string   `json:"project,omitempty"` // Modrinth project ID or CurseForge project ID
+	Version  string   `json:"version,omitempty"` // Modrinth version ID or CurseForge file ID
+	Evidence []string `json:"evidence"`          // the mod IDs and jar names that matched it
+}
+
+// BisectOptions configures how Bisect decides whether a boot succeeded
+type BisectOptions struct {
+	Timeout time.Duration // per boot, 5 minutes when zero
+	Success string        // regexp of the console line that passes a boot; the "Done" line when empty
+	Failure string        // regexp of a console line that fails a boot, besides crashes and timeouts
+	Keep    bool          // keep the scratch run directory of the last boot
+}
+
+// BisectResult names the smallest set of mods that still stops the server from booting
+type BisectResult struct {
+	Culprits     []CrashSuspect `json:"culprits"`               // Evidence holds the mod IDs each jar declares
+	Dependencies []CrashSuspect `json:"dependencies,omitempty"` // installed with the culprits because they require them
+	Reason       string         `json:"reason"`                 // why the server failed with the culprits
+	Mods         int            `json:"mods"`                   // server mods that were bisected
+	Boots        int            `json:"boots"`
+	Duration     time.Duration  `json:"duration"`
 }
 
 // ProgressCallback represents a progress callback function